@@ -1,11 +1,13 @@
 package app
 
 import (
+	"errors"
 	"os"
 
 	"github.com/apex/log"
 	"github.com/stenh0use/hind/pkg/cmd"
 	"github.com/stenh0use/hind/pkg/cmd/hind"
+	"github.com/stenh0use/hind/pkg/provider"
 )
 
 // Main is the entrypoint for the hind CLI.
@@ -14,6 +16,11 @@ func Main() {
 	logLevel := cmd.GetLogLevelFromEnv()
 	logger := cmd.NewLogger(logLevel, "text")
 	if err := Run(logger, os.Args[1:]); err != nil {
+		// Exit with the code of a command run in a node, eg. by hind exec
+		var exitErr *provider.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		os.Exit(1)
 	}
 }
@@ -23,7 +30,11 @@ func Run(logger *log.Logger, args []string) error {
 	cmd := hind.NewCommand(logger)
 	cmd.SetArgs(args)
 	if err := cmd.Execute(); err != nil {
-		logger.WithError(err).Error("command failed")
+		// The command run in a node reported its own failure
+		var exitErr *provider.ExitError
+		if !errors.As(err, &exitErr) {
+			logger.WithError(err).Error("command failed")
+		}
 		return err
 	}
 	return nil
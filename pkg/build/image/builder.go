@@ -16,8 +16,8 @@ type Builder struct {
 	image  Image
 }
 
-func NewBuilder(logger *log.Logger, kind release.ImageKind) (*Builder, error) {
-	image, err := NewImage(kind)
+func NewBuilder(logger *log.Logger, kind release.ImageKind, version string) (*Builder, error) {
+	image, err := NewImage(kind, version)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create image definition: %w", err)
 	}
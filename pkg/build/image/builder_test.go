@@ -50,7 +50,7 @@ func TestNewBuilder(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			logger := &log.Logger{Handler: discard.New()}
-			got, err := NewBuilder(logger, tt.kind)
+			got, err := NewBuilder(logger, tt.kind, "")
 
 			if tt.wantErr {
 				if err == nil {
@@ -169,7 +169,7 @@ func TestBuilder_ImageConfiguration(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			builder, err := NewBuilder(logger, tt.kind)
+			builder, err := NewBuilder(logger, tt.kind, "")
 			if err != nil {
 				t.Fatalf("NewBuilder(%v) unexpected error: %v", tt.kind, err)
 			}
@@ -189,3 +189,58 @@ func TestBuilder_ImageConfiguration(t *testing.T) {
 		})
 	}
 }
+
+func TestNewBuilder_Version(t *testing.T) {
+	logger := &log.Logger{Handler: discard.New()}
+
+	tests := []struct {
+		name        string
+		version     string
+		wantRelease string
+		wantErr     bool
+	}{
+		{
+			name:        "empty version uses latest release",
+			version:     "",
+			wantRelease: release.Latest().Hind,
+		},
+		{
+			name:        "latest alias uses latest release",
+			version:     release.LatestAlias,
+			wantRelease: release.Latest().Hind,
+		},
+		{
+			name:        "older release",
+			version:     "0.3.0",
+			wantRelease: "0.3.0",
+		},
+		{
+			name:    "unknown release",
+			version: "999.0.0",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewBuilder(logger, release.Nomad, tt.version)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("NewBuilder(%q) = %v, want error", tt.version, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewBuilder(%q) unexpected error: %v", tt.version, err)
+			}
+
+			if got.image.Release != tt.wantRelease {
+				t.Errorf("NewBuilder(%q).image.Release = %q, want %q", tt.version, got.image.Release, tt.wantRelease)
+			}
+			// Dependent images are built from the base image of the same release
+			if got.image.BaseImage.Tag != tt.wantRelease {
+				t.Errorf("NewBuilder(%q).image.BaseImage.Tag = %q, want %q", tt.version, got.image.BaseImage.Tag, tt.wantRelease)
+			}
+		})
+	}
+}
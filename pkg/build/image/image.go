@@ -34,8 +34,13 @@ func BuildTargets() []string {
 	return append(targets, "all")
 }
 
-func NewImage(i release.ImageKind) (Image, error) {
-	rel := release.Latest()
+// NewImage returns the image definition of a kind for a hind release.
+// An empty version or "latest" selects the latest release.
+func NewImage(i release.ImageKind, version string) (Image, error) {
+	rel, err := release.Resolve(version)
+	if err != nil {
+		return Image{}, err
+	}
 	switch i {
 	case release.Consul:
 		return newConsul(rel), nil
@@ -8,8 +8,12 @@ import (
 	"fmt"
 	"maps"
 	"slices"
+	"strings"
 )
 
+// LatestAlias is the version name that always refers to the latest release.
+const LatestAlias = "latest"
+
 var (
 	ErrUnknownPackage = errors.New("unknown package")
 	ErrUnknownRelease = errors.New("unknown release")
@@ -84,6 +88,23 @@ func (d *Data) Get(release string) (Info, error) {
 	return info, nil
 }
 
+// Resolve returns release information for a version, where an empty version
+// or LatestAlias selects the latest release.
+// Returns an error listing the known releases if the version does not exist.
+func (d *Data) Resolve(release string) (Info, error) {
+	if release == "" || release == LatestAlias {
+		return d.Latest(), nil
+	}
+	info, ok := d.releases[release]
+	if !ok {
+		known := d.List()
+		slices.Sort(known)
+		return Info{}, fmt.Errorf("%w: %s (available releases: %s)",
+			ErrUnknownRelease, release, strings.Join(known, ", "))
+	}
+	return info, nil
+}
+
 // List returns all available releases by name.
 func (d *Data) List() []string {
 	return slices.Collect(maps.Keys(d.releases))
@@ -123,6 +123,54 @@ func TestPackageLevelGet(t *testing.T) {
 	}
 }
 
+func TestData_Resolve(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     string
+		expectError bool
+		wantHind    string
+	}{
+		{
+			name:     "empty version resolves to latest",
+			version:  "",
+			wantHind: "0.3.1",
+		},
+		{
+			name:     "latest alias resolves to latest",
+			version:  LatestAlias,
+			wantHind: "0.3.1",
+		},
+		{
+			name:     "existing version",
+			version:  "0.3.0",
+			wantHind: "0.3.0",
+		},
+		{
+			name:        "non-existent version",
+			version:     "999.0.0",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testData.Resolve(tt.version)
+			if tt.expectError {
+				if !errors.Is(err, ErrUnknownRelease) {
+					t.Errorf("Resolve(%q) error = %v, want ErrUnknownRelease", tt.version, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve(%q) unexpected error: %v", tt.version, err)
+			}
+			if got.Hind != tt.wantHind {
+				t.Errorf("Resolve(%q).Hind = %q, want %q", tt.version, got.Hind, tt.wantHind)
+			}
+		})
+	}
+}
+
 func TestData_List(t *testing.T) {
 	list := testData.List()
 
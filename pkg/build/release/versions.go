@@ -45,6 +45,12 @@ func Get(version string) (Info, error) {
 	return versions.Get(version)
 }
 
+// Resolve returns a release version, or the latest release for LatestAlias,
+// from the default store.
+func Resolve(version string) (Info, error) {
+	return versions.Resolve(version)
+}
+
 // List returns all releases from the default store.
 func List() []string {
 	return versions.List()
@@ -0,0 +1,262 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+// ACLTokensFile is the file in the cluster directory holding the ACL tokens,
+// readable by the owner only
+const ACLTokensFile = "acl.json"
+
+// ErrNoACLTokens is returned when the ACLs of a cluster weren't
+// bootstrapped yet
+var ErrNoACLTokens = errors.New("no acl tokens")
+
+// ACLTokens are the secret IDs of the tokens hind creates when it
+// bootstraps the ACLs of a cluster
+type ACLTokens struct {
+	// ConsulManagement is the Consul bootstrap token, with the
+	// global-management policy
+	ConsulManagement string `json:"consulManagementToken"`
+	// ConsulAgent is the token of every Consul agent. Nodes running Nomad or
+	// Vault also use it for the requests without a token.
+	ConsulAgent string `json:"consulAgentToken"`
+	// NomadManagement is the Nomad bootstrap token, of type management
+	NomadManagement string `json:"nomadManagementToken"`
+}
+
+// consulAgentPolicy is the policy of the Consul agent token. It covers the
+// node registration of the agents and the services Nomad and Vault
+// register through them.
+const consulAgentPolicy = "hind-agent"
+
+const consulAgentRules = `node_prefix "" { policy = "write" }
+service_prefix "" { policy = "write" }
+agent_prefix "" { policy = "read" }
+key_prefix "" { policy = "read" }`
+
+// The bootstrap commands fail with these errors once the ACLs were
+// bootstrapped, eg. on restarts
+const (
+	consulBootstrapDone = "ACL bootstrap no longer allowed"
+	nomadBootstrapDone  = "ACL bootstrap already done"
+)
+
+var consulBootstrapCmd = []string{"consul", "acl", "bootstrap", "-format=json"}
+
+var nomadBootstrapCmd = []string{"nomad", "acl", "bootstrap", "-json"}
+
+// consulAgentTokenCmd creates the agent policy when missing and a token
+// with it
+var consulAgentTokenCmd = []string{"sh", "-c", `set -e
+consul acl policy read -name "$POLICY" >/dev/null 2>&1 ||
+	consul acl policy create -name "$POLICY" -description "hind agents" -rules "$RULES" >/dev/null
+consul acl token create -description "hind agent token" -policy-name "$POLICY" -format=json`}
+
+// consulSetAgentTokenCmd sets the agent token of the local Consul agent,
+// and its default token when DEFAULT is set
+var consulSetAgentTokenCmd = []string{"sh", "-c", `set -e
+consul acl set-agent-token agent "$CONSUL_AGENT_TOKEN" >/dev/null
+if [ -n "$DEFAULT" ]; then
+	consul acl set-agent-token default "$CONSUL_AGENT_TOKEN" >/dev/null
+fi`}
+
+// ACLTokens returns the stored ACL tokens of the cluster, or ErrNoACLTokens
+// if the ACLs weren't bootstrapped
+func (m *Manager) ACLTokens() (*ACLTokens, error) {
+	var tokens ACLTokens
+	found, err := m.readSecrets(ACLTokensFile, &tokens)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read acl tokens: %w", err)
+	}
+	if !found {
+		return nil, ErrNoACLTokens
+	}
+	return &tokens, nil
+}
+
+// saveACLTokens stores the ACL tokens of the cluster
+func (m *Manager) saveACLTokens(tokens *ACLTokens) error {
+	if err := m.writeSecrets(ACLTokensFile, tokens); err != nil {
+		return err
+	}
+	m.logger.Debug("Updated acl tokens")
+	return nil
+}
+
+// aclTokens returns the stored ACL tokens, empty when the cluster doesn't
+// enable ACLs or they weren't bootstrapped yet
+func (m *Manager) aclTokens() ACLTokens {
+	if !m.config.ACL {
+		return ACLTokens{}
+	}
+	tokens, err := m.ACLTokens()
+	if err != nil {
+		if !errors.Is(err, ErrNoACLTokens) {
+			m.logger.Warnf("Failed to read acl tokens: %v", err)
+		}
+		return ACLTokens{}
+	}
+	return *tokens
+}
+
+// storedACLTokens returns the stored ACL tokens, empty ones if there are
+// none yet
+func (m *Manager) storedACLTokens() (*ACLTokens, error) {
+	tokens, err := m.ACLTokens()
+	if errors.Is(err, ErrNoACLTokens) {
+		return &ACLTokens{}, nil
+	}
+	return tokens, err
+}
+
+// bootstrapConsulACL bootstraps the Consul ACLs on the first server, then
+// creates the agent token and sets it on every node. A new bootstrap, eg.
+// when the servers were recreated, replaces the stored tokens.
+func (m *Manager) bootstrapConsulACL(ctx context.Context) error {
+	servers := m.nodesOf(config.ConsulNode, config.Server)
+	if len(servers) == 0 {
+		return nil
+	}
+
+	tokens, err := m.storedACLTokens()
+	if err != nil {
+		return err
+	}
+
+	secret, err := m.aclBootstrap(ctx, servers[0], consulBootstrapCmd, consulBootstrapDone)
+	if err != nil {
+		return err
+	}
+	switch {
+	case secret != "":
+		tokens.ConsulManagement, tokens.ConsulAgent = secret, ""
+		if err := m.saveACLTokens(tokens); err != nil {
+			return err
+		}
+	case tokens.ConsulManagement == "":
+		return fmt.Errorf("consul ACLs were bootstrapped already, but no management token is stored in %s", m.clusterFile(ACLTokensFile))
+	}
+
+	consulEnv := m.nodeCertEnvironment()
+	consulEnv[agentAddrEnv[config.ConsulNode]] = m.agentAddress("127.0.0.1", config.ConsulNode)
+	consulEnv["CONSUL_HTTP_TOKEN"] = tokens.ConsulManagement
+
+	if tokens.ConsulAgent == "" {
+		env := map[string]string{"POLICY": consulAgentPolicy, "RULES": consulAgentRules}
+		maps.Copy(env, consulEnv)
+		var stdout bytes.Buffer
+		if err := m.nodeExec(ctx, servers[0], consulAgentTokenCmd, env, &stdout); err != nil {
+			return fmt.Errorf("failed to create the consul agent token: %w", err)
+		}
+		if tokens.ConsulAgent, err = secretID(stdout.Bytes()); err != nil {
+			return fmt.Errorf("failed to create the consul agent token: %w", err)
+		}
+		if err := m.saveACLTokens(tokens); err != nil {
+			return err
+		}
+	}
+
+	for _, n := range m.config.Nodes {
+		env := map[string]string{"CONSUL_AGENT_TOKEN": tokens.ConsulAgent}
+		maps.Copy(env, consulEnv)
+		// Nomad and Vault register their services without a token
+		if n.Kind != config.ConsulNode {
+			env["DEFAULT"] = "true"
+		}
+		if err := m.nodeExec(ctx, n, consulSetAgentTokenCmd, env, io.Discard); err != nil {
+			return fmt.Errorf("failed to set the consul agent token of '%s': %w", n.Name, err)
+		}
+	}
+	return nil
+}
+
+// bootstrapNomadACL bootstraps the Nomad ACLs on the first server. Nomad
+// clients authenticate with their node secret, they don't need a token.
+func (m *Manager) bootstrapNomadACL(ctx context.Context) error {
+	servers := m.nodesOf(config.NomadNode, config.Server)
+	if len(servers) == 0 {
+		return nil
+	}
+
+	tokens, err := m.storedACLTokens()
+	if err != nil {
+		return err
+	}
+
+	secret, err := m.aclBootstrap(ctx, servers[0], nomadBootstrapCmd, nomadBootstrapDone)
+	if err != nil {
+		return err
+	}
+	switch {
+	case secret != "":
+		tokens.NomadManagement = secret
+		return m.saveACLTokens(tokens)
+	case tokens.NomadManagement == "":
+		return fmt.Errorf("nomad ACLs were bootstrapped already, but no management token is stored in %s", m.clusterFile(ACLTokensFile))
+	}
+	return nil
+}
+
+// aclBootstrap runs a bootstrap command on a server and returns the secret
+// ID of the management token, empty if the ACLs were bootstrapped already
+func (m *Manager) aclBootstrap(ctx context.Context, node config.Node, cmd []string, done string) (string, error) {
+	env := m.nodeCertEnvironment()
+	for kind, name := range agentAddrEnv {
+		env[name] = m.agentAddress("127.0.0.1", kind)
+	}
+
+	var stdout bytes.Buffer
+	err := m.nodeExec(ctx, node, cmd, env, &stdout)
+	if err != nil {
+		if strings.Contains(err.Error(), done) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to bootstrap the %s ACLs: %w", node.Kind, err)
+	}
+	return secretID(stdout.Bytes())
+}
+
+// nodeExec runs a command in a node, the error includes what the command
+// wrote to stderr
+func (m *Manager) nodeExec(ctx context.Context, node config.Node, cmd []string, env map[string]string, stdout io.Writer) error {
+	var stderr bytes.Buffer
+	err := m.provider.Exec(ctx, node.Name, provider.ExecOptions{
+		Cmd:    cmd,
+		Env:    env,
+		Stdout: stdout,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// secretID reads the secret of a token from the JSON the Consul and Nomad
+// CLIs print
+func secretID(data []byte) (string, error) {
+	var token struct {
+		SecretID string
+	}
+	if err := json.Unmarshal(data, &token); err != nil {
+		return "", fmt.Errorf("failed to decode token: %w", err)
+	}
+	if token.SecretID == "" {
+		return "", fmt.Errorf("token has no secret ID")
+	}
+	return token.SecretID, nil
+}
@@ -0,0 +1,257 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+	"github.com/stenh0use/hind/pkg/provider/fake"
+)
+
+// fakeACLs plays the ACL bootstrap of Consul and Nomad in a fake provider,
+// with Vault initialised so start can unseal it
+type fakeACLs struct {
+	mu           sync.Mutex
+	bootstrapped map[string]bool
+	issued       int
+	// agentTokens is the agent token set on each node, defaults whether it
+	// is also its default token
+	agentTokens map[string]string
+	defaults    map[string]bool
+}
+
+func newFakeACLs() *fakeACLs {
+	return &fakeACLs{bootstrapped: map[string]bool{}, agentTokens: map[string]string{}, defaults: map[string]bool{}}
+}
+
+func (f *fakeACLs) exec(_ context.Context, name string, opts provider.ExecOptions) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	secret := func() {
+		f.issued++
+		fmt.Fprintf(opts.Stdout, `{"SecretID": "secret-%d"}`, f.issued)
+	}
+
+	switch {
+	case slices.Equal(opts.Cmd, consulBootstrapCmd), slices.Equal(opts.Cmd, nomadBootstrapCmd):
+		done := consulBootstrapDone
+		if opts.Cmd[0] == "nomad" {
+			done = nomadBootstrapDone
+		}
+		if f.bootstrapped[opts.Cmd[0]] {
+			io.WriteString(opts.Stderr, "Error: "+done+" (reset index: 7)")
+			return &provider.ExitError{Code: 1}
+		}
+		f.bootstrapped[opts.Cmd[0]] = true
+		secret()
+	case slices.Equal(opts.Cmd, consulAgentTokenCmd):
+		if opts.Env["POLICY"] != consulAgentPolicy || opts.Env["CONSUL_HTTP_TOKEN"] == "" {
+			return fmt.Errorf("unexpected env %v", opts.Env)
+		}
+		secret()
+	case slices.Equal(opts.Cmd, consulSetAgentTokenCmd):
+		f.agentTokens[name] = opts.Env["CONSUL_AGENT_TOKEN"]
+		f.defaults[name] = opts.Env["DEFAULT"] != ""
+	case slices.Equal(opts.Cmd, vaultEnvCmd):
+		io.WriteString(opts.Stdout, "VAULT_ROOT_TOKEN=hvs.root\nVAULT_UNSEAL_KEY=key\n")
+	}
+	return nil
+}
+
+func TestManager_BootstrapConsulACL(t *testing.T) {
+	acls := newFakeACLs()
+	m := newFakeManager(t, "dev", fake.New(fake.WithExec(acls.exec)), WithACL(true))
+	ctx := context.Background()
+	if _, err := m.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	if err := m.bootstrapConsulACL(ctx); err != nil {
+		t.Fatalf("bootstrapConsulACL() error = %v", err)
+	}
+	tokens, err := m.ACLTokens()
+	if err != nil {
+		t.Fatalf("ACLTokens() error = %v", err)
+	}
+	want := ACLTokens{ConsulManagement: "secret-1", ConsulAgent: "secret-2"}
+	if *tokens != want {
+		t.Errorf("ACLTokens() = %+v, want %+v", *tokens, want)
+	}
+
+	// Every node gets the agent token, the ones running Nomad or Vault also
+	// as their default token
+	for _, n := range m.config.Nodes {
+		if acls.agentTokens[n.Name] != "secret-2" {
+			t.Errorf("agent token of %s = %q, want secret-2", n.Name, acls.agentTokens[n.Name])
+		}
+		if wantDefault := n.Kind != config.ConsulNode; acls.defaults[n.Name] != wantDefault {
+			t.Errorf("default token set on %s = %v, want %v", n.Name, acls.defaults[n.Name], wantDefault)
+		}
+	}
+
+	// On restarts the stored tokens are distributed again
+	clear(acls.agentTokens)
+	if err := m.bootstrapConsulACL(ctx); err != nil {
+		t.Fatalf("bootstrapConsulACL() after restart error = %v", err)
+	}
+	if tokens, _ := m.ACLTokens(); *tokens != want {
+		t.Errorf("ACLTokens() after restart = %+v, want %+v", *tokens, want)
+	}
+	if acls.agentTokens["hind.dev.client.01"] != "secret-2" {
+		t.Errorf("agent token of hind.dev.client.01 = %q, want secret-2", acls.agentTokens["hind.dev.client.01"])
+	}
+}
+
+func TestManager_BootstrapConsulACL_LostTokens(t *testing.T) {
+	acls := newFakeACLs()
+	acls.bootstrapped["consul"] = true
+	m := newFakeManager(t, "dev", fake.New(fake.WithExec(acls.exec)), WithACL(true))
+	ctx := context.Background()
+	if _, err := m.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	err := m.bootstrapConsulACL(ctx)
+	if err == nil || !strings.Contains(err.Error(), "no management token is stored") {
+		t.Errorf("bootstrapConsulACL() error = %v, want no management token is stored", err)
+	}
+}
+
+func TestManager_BootstrapNomadACL(t *testing.T) {
+	acls := newFakeACLs()
+	m := newFakeManager(t, "dev", fake.New(fake.WithExec(acls.exec)), WithACL(true))
+	ctx := context.Background()
+	if _, err := m.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := m.saveACLTokens(&ACLTokens{ConsulManagement: "consul", ConsulAgent: "agent"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		if err := m.bootstrapNomadACL(ctx); err != nil {
+			t.Fatalf("bootstrapNomadACL() error = %v", err)
+		}
+	}
+	want := ACLTokens{ConsulManagement: "consul", ConsulAgent: "agent", NomadManagement: "secret-1"}
+	if tokens, err := m.ACLTokens(); err != nil || *tokens != want {
+		t.Errorf("ACLTokens() = %+v, %v, want %+v", tokens, err, want)
+	}
+}
+
+func TestManager_Start_ACLSetup(t *testing.T) {
+	// newFakeManager skips the readiness checks, like start --wait=false
+	acls := newFakeACLs()
+	m := newFakeManager(t, "dev", fake.New(fake.WithExec(acls.exec)), WithACL(true), withSetup(true))
+	if _, err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	tokens, err := m.ACLTokens()
+	if err != nil {
+		t.Fatalf("ACLTokens() error = %v", err)
+	}
+	if tokens.ConsulManagement == "" || tokens.ConsulAgent == "" || tokens.NomadManagement == "" {
+		t.Errorf("ACLTokens() = %+v, want every token", *tokens)
+	}
+	if acls.agentTokens["hind.dev.client.01"] != tokens.ConsulAgent {
+		t.Errorf("agent token of hind.dev.client.01 = %q, want %q", acls.agentTokens["hind.dev.client.01"], tokens.ConsulAgent)
+	}
+}
+
+func TestManager_Start_ACLSetupRetried(t *testing.T) {
+	orig := readinessPollInterval
+	readinessPollInterval = time.Millisecond
+	t.Cleanup(func() { readinessPollInterval = orig })
+
+	// The first start gives up before Consul elects a leader
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var leader atomic.Bool
+	acls := newFakeACLs()
+	p := fake.New(fake.WithExec(func(ctx context.Context, name string, opts provider.ExecOptions) error {
+		if !leader.Load() {
+			cancel()
+			io.WriteString(opts.Stderr, "No cluster leader")
+			return &provider.ExitError{Code: 1}
+		}
+		return acls.exec(ctx, name, opts)
+	}))
+	m := newFakeManager(t, "dev", p, WithACL(true), withSetup(true))
+	if _, err := m.Start(ctx); err == nil {
+		t.Fatal("Start() error = nil, want the setup to fail")
+	}
+	if _, err := m.ACLTokens(); !errors.Is(err, ErrNoACLTokens) {
+		t.Fatalf("ACLTokens() error = %v, want %v", err, ErrNoACLTokens)
+	}
+
+	// The containers run, the next start has nothing to reconcile but
+	// completes the setup
+	plan, err := m.Plan(context.Background())
+	if err != nil || !plan.IsEmpty() {
+		t.Fatalf("Plan() = %+v, %v, want no changes", plan, err)
+	}
+	leader.Store(true)
+	if _, err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start() again error = %v", err)
+	}
+	if tokens, err := m.ACLTokens(); err != nil || tokens.ConsulManagement == "" || tokens.NomadManagement == "" {
+		t.Errorf("ACLTokens() = %v, %v, want the management tokens", tokens, err)
+	}
+}
+
+func TestManager_ACLEnvironment(t *testing.T) {
+	m := newFakeManager(t, "dev", fake.New(), WithACL(true))
+	if _, err := m.ACLTokens(); !errors.Is(err, ErrNoACLTokens) {
+		t.Errorf("ACLTokens() error = %v, want %v", err, ErrNoACLTokens)
+	}
+
+	for _, n := range m.config.Nodes {
+		want := map[string]string{"CONSUL_ACL_ENABLED": "true"}
+		if n.Kind == config.NomadNode {
+			want["NOMAD_ACL_ENABLED"] = "true"
+		}
+		got := maps.Clone(n.Environment)
+		maps.DeleteFunc(got, func(k, _ string) bool { return !strings.HasSuffix(k, "_ACL_ENABLED") })
+		if !maps.Equal(got, want) {
+			t.Errorf("%s ACL environment = %v, want %v", n.Name, got, want)
+		}
+	}
+
+	// Clients added later enable ACLs too
+	if err := m.ResizeClients(2); err != nil {
+		t.Fatalf("ResizeClients() error = %v", err)
+	}
+	if env := m.findNodeConfigByName("hind.dev.client.02").Environment; env["NOMAD_ACL_ENABLED"] != "true" {
+		t.Errorf("added client environment = %v, want NOMAD_ACL_ENABLED", env)
+	}
+
+	if err := m.saveACLTokens(&ACLTokens{ConsulManagement: "consul", ConsulAgent: "agent", NomadManagement: "nomad"}); err != nil {
+		t.Fatal(err)
+	}
+	env := m.AgentEnvironment(*m.findNodeConfigByName("hind.dev.client.01"))
+	if env["CONSUL_HTTP_TOKEN"] != "consul" || env["NOMAD_TOKEN"] != "nomad" {
+		t.Errorf("AgentEnvironment() = %v, want the management tokens", env)
+	}
+	env = clientEnvironment(t, m)
+	if env["CONSUL_HTTP_TOKEN"] != "consul" || env["NOMAD_TOKEN"] != "nomad" {
+		t.Errorf("ClientEnvironment() = %v, want the management tokens", env)
+	}
+
+	// Without ACLs stale tokens aren't used
+	m.config.ACL = false
+	if env := clientEnvironment(t, m); env["CONSUL_HTTP_TOKEN"] != "" || env["NOMAD_TOKEN"] != "" {
+		t.Errorf("ClientEnvironment() = %v, want no ACL tokens", env)
+	}
+}
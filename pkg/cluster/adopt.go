@@ -0,0 +1,204 @@
+package cluster
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/file"
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+// imageEnvironment are the variables the hind images set, they are not
+// part of a node's config
+var imageEnvironment = []string{
+	"PATH", "container", "DEBIAN_FRONTEND",
+	"HASHICORP_RELEASES", "DOCKER_RELEASES", "CNI_RELEASES",
+}
+
+// Adopt rebuilds the cluster config from the containers carrying the
+// cluster label and saves it, eg. after the saved state was lost or a run
+// crashed before saving it. The cluster must not have a saved config.
+func (m *Manager) Adopt(ctx context.Context) error {
+	if m.ConfigFileExists() {
+		return fmt.Errorf("cluster '%s' already has a config", m.config.Name)
+	}
+
+	filter := "label=" + ClusterLabel + "=" + m.config.Name
+	listed, err := m.provider.ListContainers(ctx, []string{filter})
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+	if len(listed) == 0 {
+		return fmt.Errorf("no containers found with label %s=%s", ClusterLabel, m.config.Name)
+	}
+
+	// Not every provider reports the container config when listing
+	var containers []provider.ContainerInfo
+	for _, c := range listed {
+		info, err := m.provider.InspectContainer(ctx, c.Name)
+		if err != nil {
+			return fmt.Errorf("failed to inspect container '%s': %w", c.Name, err)
+		}
+		if info != nil {
+			containers = append(containers, *info)
+		}
+	}
+
+	cfg, err := adoptedConfig(m.config.Name, containers)
+	if err != nil {
+		return err
+	}
+	cfg.Provider = m.config.Provider
+	m.config = cfg
+
+	clusterDir := file.JoinPath(m.fm.GetRootDir(), ClusterConfigDir, m.config.Name)
+	if err := m.fm.EnsureDir(clusterDir); err != nil {
+		return fmt.Errorf("failed to create cluster dir: %w", err)
+	}
+	if err := m.saveConfig(); err != nil {
+		return err
+	}
+
+	m.logger.Infof("Adopted cluster '%s' with %d nodes", cfg.Name, len(cfg.Nodes))
+	return nil
+}
+
+// adoptedConfig builds the config of a cluster from the specs of its
+// containers. The version is the one the containers are labeled with, the
+// ACL and TLS modes, datacenter and region the ones their agents run with.
+func adoptedConfig(name string, containers []provider.ContainerInfo) (*config.Cluster, error) {
+	cfg := &config.Cluster{
+		Name:    name,
+		Network: config.Network{Name: networkName(name)},
+	}
+
+	for _, c := range containers {
+		if c.Spec == nil {
+			return nil, fmt.Errorf("provider doesn't report the config of container '%s'", c.Name)
+		}
+
+		version := c.Labels[VersionLabel]
+		switch {
+		case version == "":
+			return nil, fmt.Errorf("container '%s' has no %s label", c.Name, VersionLabel)
+		case cfg.Version == "":
+			cfg.Version = version
+		case cfg.Version != version:
+			return nil, fmt.Errorf("containers of cluster '%s' run different versions: %s and %s", name, cfg.Version, version)
+		}
+
+		node, err := adoptedNode(*c.Spec)
+		if err != nil {
+			return nil, err
+		}
+		if node.Network != "" {
+			cfg.Network.Name = node.Network
+		}
+		cfg.Nodes = append(cfg.Nodes, node)
+	}
+
+	slices.SortFunc(cfg.Nodes, func(a, b config.Node) int {
+		return cmp.Or(cmp.Compare(nodeTier(a), nodeTier(b)), cmp.Compare(a.Name, b.Name))
+	})
+	if err := adoptClusterEnvironment(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// adoptClusterEnvironment sets the cluster settings from the agent
+// environment of its nodes, the inverse of clusterEnvironment. Every node
+// runs a Consul agent, the region is only set in the Nomad agents.
+func adoptClusterEnvironment(cfg *config.Cluster) error {
+	for _, n := range cfg.Nodes {
+		env := n.Environment
+		cfg.ACL = cfg.ACL || env["CONSUL_ACL_ENABLED"] == "true"
+		cfg.TLS = cfg.TLS || env["CONSUL_TLS_ENABLED"] == "true"
+
+		if dc := env["CONSUL_DATACENTER"]; dc != "" {
+			if cfg.Datacenter != "" && cfg.Datacenter != dc {
+				return fmt.Errorf("nodes of cluster '%s' run in different datacenters: %s and %s", cfg.Name, cfg.Datacenter, dc)
+			}
+			cfg.Datacenter = dc
+		}
+		if region := env["NOMAD_REGION"]; region != "" {
+			if cfg.Region != "" && cfg.Region != region {
+				return fmt.Errorf("nodes of cluster '%s' run in different regions: %s and %s", cfg.Name, cfg.Region, region)
+			}
+			cfg.Region = region
+		}
+	}
+	return validateLocation(cfg)
+}
+
+// adoptedNode returns the node config of a container spec, without the
+// cluster labels, the environment set by the image and the volumes hind
+// mounts in every node of its kind
+func adoptedNode(spec config.Node) (config.Node, error) {
+	node := spec
+
+	kind, role, err := nodeKindRole(spec)
+	if err != nil {
+		return node, err
+	}
+	node.Kind, node.Role = kind, role
+
+	node.Labels = maps.Clone(spec.Labels)
+	delete(node.Labels, ClusterLabel)
+	delete(node.Labels, VersionLabel)
+	if len(node.Labels) == 0 {
+		node.Labels = nil
+	}
+
+	node.Environment = maps.Clone(spec.Environment)
+	for _, k := range slices.Concat(imageEnvironment, gossipKeyEnvironment) {
+		delete(node.Environment, k)
+	}
+
+	data, _ := vaultDataVolume(config.Node{Name: node.Name, Kind: kind})
+	node.Volumes = slices.DeleteFunc(slices.Clone(spec.Volumes), func(v config.Volume) bool {
+		return (v.MountType() == config.BindMount && v.Destination == nodeTLSDir) ||
+			(v.Name == data.Name && v.Destination == data.Destination)
+	})
+	if len(node.Volumes) == 0 {
+		node.Volumes = nil
+	}
+	return node, nil
+}
+
+// nodeKindRole tells the kind and role of a node from the hind image it
+// runs, or from the agent environment when it runs another image
+func nodeKindRole(node config.Node) (config.Kind, config.Role, error) {
+	types := []struct {
+		kind config.Kind
+		role config.Role
+	}{
+		{config.ConsulNode, config.Server},
+		{config.NomadNode, config.Server},
+		{config.NomadNode, config.Client},
+		{config.VaultNode, config.Server},
+	}
+	for _, t := range types {
+		if path.Base(node.Image.Name) == path.Base(nodeImageKind(t.kind, t.role).ImageName()) {
+			return t.kind, t.role, nil
+		}
+	}
+
+	env := node.Environment
+	switch {
+	case env["CONSUL_AGENT_MODE"] == config.Server.String():
+		return config.ConsulNode, config.Server, nil
+	case env["NOMAD_AGENT_MODE"] == config.Server.String():
+		return config.NomadNode, config.Server, nil
+	case env["NOMAD_AGENT_MODE"] == config.Client.String():
+		return config.NomadNode, config.Client, nil
+	case env["VAULT_SERVER_ADDRESS"] != "":
+		return config.VaultNode, config.Server, nil
+	}
+	return "", "", fmt.Errorf("cannot tell the kind of node '%s' from its image '%s'", node.Name, node.Image.Ref())
+}
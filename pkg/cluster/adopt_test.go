@@ -0,0 +1,190 @@
+package cluster
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider/fake"
+)
+
+func TestManager_Adopt(t *testing.T) {
+	p := fake.New()
+	ctx := context.Background()
+	original := startFakeCluster(t, "dev", p, true).Config()
+
+	m := newFakeManager(t, "dev", p)
+	if err := m.Adopt(ctx); err != nil {
+		t.Fatalf("Adopt() error = %v", err)
+	}
+	if !m.ConfigFileExists() {
+		t.Fatal("Adopt() did not save the config")
+	}
+
+	got := m.Config()
+	if got.Version != original.Version || got.Network.Name != original.Network.Name {
+		t.Errorf("Adopt() version, network = %s, %s, want %s, %s", got.Version, got.Network.Name, original.Version, original.Network.Name)
+	}
+	if len(got.Nodes) != len(original.Nodes) {
+		t.Fatalf("Adopt() = %d nodes, want %d", len(got.Nodes), len(original.Nodes))
+	}
+	for _, want := range original.Nodes {
+		node := m.findNodeConfigByName(want.Name)
+		if node == nil {
+			t.Errorf("Adopt() is missing node %s", want.Name)
+			continue
+		}
+		if node.Kind != want.Kind || node.Role != want.Role || node.Image.Ref() != want.Image.Ref() {
+			t.Errorf("node %s = %s %s %s, want %s %s %s", want.Name,
+				node.Kind, node.Role, node.Image.Ref(), want.Kind, want.Role, want.Image.Ref())
+		}
+		if node.Labels[ClusterLabel] != "" {
+			t.Errorf("node %s labels = %v, want no cluster labels", want.Name, node.Labels)
+		}
+	}
+
+	// The adopted cluster matches its containers, starting it changes nothing
+	before := len(p.CallsTo(fake.MethodCreateContainer))
+	if _, err := m.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if after := len(p.CallsTo(fake.MethodCreateContainer)); after != before {
+		t.Errorf("Start() after Adopt() created %d containers, want 0", after-before)
+	}
+
+	if err := m.Adopt(ctx); err == nil {
+		t.Error("Adopt() of a cluster with a config error = nil, want error")
+	}
+}
+
+func TestManager_Adopt_ClusterSettings(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"acl", []Option{WithACL(true)}},
+		{"tls", []Option{WithTLS(true)}},
+		{"datacenter", []Option{WithLocation("dc2", "")}},
+		{"all", []Option{WithACL(true), WithTLS(true), WithLocation("eu-west", "eu")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := fake.New()
+			ctx := context.Background()
+			original := newFakeManager(t, "dev", p, tt.opts...)
+			if _, err := original.Start(ctx); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			// The saved config is lost, the certificates and tokens are kept
+			if err := os.Remove(original.configFile); err != nil {
+				t.Fatal(err)
+			}
+			want := original.Config()
+
+			m, err := New(original.logger, "dev", WithProvider(p), WithReadinessChecks(false), withSetup(false))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if err := m.Adopt(ctx); err != nil {
+				t.Fatalf("Adopt() error = %v", err)
+			}
+			got := m.Config()
+			if got.ACL != want.ACL || got.TLS != want.TLS || got.Datacenter != want.Datacenter || got.Region != want.Region {
+				t.Errorf("Adopt() acl, tls, datacenter, region = %t, %t, %q, %q, want %t, %t, %q, %q",
+					got.ACL, got.TLS, got.Datacenter, got.Region, want.ACL, want.TLS, want.Datacenter, want.Region)
+			}
+			if m.apiScheme() != original.apiScheme() {
+				t.Errorf("apiScheme() = %s, want %s", m.apiScheme(), original.apiScheme())
+			}
+
+			// The settings are set in the nodes already, nothing changes
+			plan, err := m.Plan(ctx)
+			if err != nil {
+				t.Fatalf("Plan() error = %v", err)
+			}
+			if !plan.IsEmpty() {
+				t.Errorf("Plan() after Adopt() = %+v, want no changes", plan)
+			}
+		})
+	}
+}
+
+func TestAdoptClusterEnvironment_Conflict(t *testing.T) {
+	cfg := &config.Cluster{Name: "dev", Nodes: []config.Node{
+		{Environment: map[string]string{"CONSUL_DATACENTER": "dc1"}},
+		{Environment: map[string]string{"CONSUL_DATACENTER": "dc2"}},
+	}}
+	if err := adoptClusterEnvironment(cfg); err == nil {
+		t.Error("adoptClusterEnvironment() error = nil, want error for nodes in different datacenters")
+	}
+}
+
+func TestManager_Adopt_NoContainers(t *testing.T) {
+	m := newFakeManager(t, "dev", fake.New())
+	if err := m.Adopt(context.Background()); err == nil {
+		t.Error("Adopt() error = nil, want error when no containers are labeled")
+	}
+	if m.ConfigFileExists() {
+		t.Error("Adopt() saved a config without containers")
+	}
+}
+
+func TestNodeKindRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		node     config.Node
+		wantKind config.Kind
+		wantRole config.Role
+		wantErr  bool
+	}{
+		{
+			name:     "consul image",
+			node:     config.Node{Image: config.Image{Name: "docker.io/stenh0use/hind.consul"}},
+			wantKind: config.ConsulNode,
+			wantRole: config.Server,
+		},
+		{
+			name:     "nomad client image without registry",
+			node:     config.Node{Image: config.Image{Name: "hind.nomad-client"}},
+			wantKind: config.NomadNode,
+			wantRole: config.Client,
+		},
+		{
+			name: "custom image with the nomad agent environment",
+			node: config.Node{
+				Image:       config.Image{Name: "example/nomad"},
+				Environment: map[string]string{"CONSUL_AGENT_MODE": "client", "NOMAD_AGENT_MODE": "server"},
+			},
+			wantKind: config.NomadNode,
+			wantRole: config.Server,
+		},
+		{
+			name: "custom image with the vault agent environment",
+			node: config.Node{
+				Image:       config.Image{Name: "example/vault"},
+				Environment: map[string]string{"CONSUL_AGENT_MODE": "client", "VAULT_SERVER_ADDRESS": "hind.dev.vault.01"},
+			},
+			wantKind: config.VaultNode,
+			wantRole: config.Server,
+		},
+		{
+			name:    "unknown",
+			node:    config.Node{Image: config.Image{Name: "debian"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, role, err := nodeKindRole(tt.node)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("nodeKindRole() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if kind != tt.wantKind || role != tt.wantRole {
+				t.Errorf("nodeKindRole() = %s %s, want %s %s", kind, role, tt.wantKind, tt.wantRole)
+			}
+		})
+	}
+}
@@ -17,6 +17,14 @@ const (
 	DefaultConfigParentDir = ".config"
 	DefaultConfigName      = "hind"
 	DefaultProvider        = "dockercli"
+	DefaultClusterName     = "default"
+
+	// Labels identifying the cluster on its containers, networks and volumes
+	ClusterLabel = "hind.cluster"
+	VersionLabel = "hind.version"
+
+	// DefaultConcurrency is the number of containers changed in parallel
+	DefaultConcurrency = 4
 
 	// Container startup timeouts and polling intervals
 	DefaultContainerStartTimeout = 30 * time.Second
@@ -30,6 +38,9 @@ func List() ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	if !fm.DirExists(ClusterConfigDir) {
+		return nil, nil
+	}
 	entries, err := fm.ListDir(ClusterConfigDir)
 	if err != nil {
 		return nil, err
@@ -64,6 +75,18 @@ func GetActiveCluster() (string, error) {
 	return string(data), nil
 }
 
+// ResolveName returns name, or when it is empty the active cluster, falling
+// back to the default cluster when none is active
+func ResolveName(name string) string {
+	if name != "" {
+		return name
+	}
+	if active, err := GetActiveCluster(); err == nil && active != "" {
+		return active
+	}
+	return DefaultClusterName
+}
+
 // SetActiveCluster sets the currently active cluster
 func SetActiveCluster(clusterName string) error {
 	fm, err := file.NewFromHomeDir(DefaultConfigParentDir, DefaultConfigName)
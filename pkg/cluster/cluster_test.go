@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/file"
 )
 
 func TestCountClientNodes(t *testing.T) {
@@ -207,3 +208,29 @@ func TestStartResult(t *testing.T) {
 		t.Errorf("expected 3 unique StartResult values, got %d", len(seen))
 	}
 }
+
+func TestResolveName(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if got := ResolveName(""); got != DefaultClusterName {
+		t.Errorf("ResolveName(\"\") = %q, want %q", got, DefaultClusterName)
+	}
+
+	fm, err := file.NewFromHomeDir(DefaultConfigParentDir, DefaultConfigName)
+	if err != nil {
+		t.Fatalf("failed to create file manager: %v", err)
+	}
+	if err := fm.EnsureDir(file.JoinPath(ClusterConfigDir, "dev")); err != nil {
+		t.Fatalf("failed to create cluster dir: %v", err)
+	}
+	if err := SetActiveCluster("dev"); err != nil {
+		t.Fatalf("SetActiveCluster() error = %v", err)
+	}
+
+	if got := ResolveName(""); got != "dev" {
+		t.Errorf("ResolveName(\"\") = %q, want the active cluster %q", got, "dev")
+	}
+	if got := ResolveName("prod"); got != "prod" {
+		t.Errorf("ResolveName(%q) = %q, want %q", "prod", got, "prod")
+	}
+}
@@ -0,0 +1,188 @@
+package cluster
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"strings"
+
+	"github.com/stenh0use/hind/pkg/build/release"
+	"github.com/stenh0use/hind/pkg/config"
+)
+
+// LoadDefinition reads a cluster definition file and returns the desired
+// cluster configuration with defaults applied. If name is not empty it
+// overrides the name set in the file. The cluster runs on providerName
+// unless the file names a provider.
+func LoadDefinition(path, name, providerName string) (*config.Cluster, error) {
+	cfg, err := config.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if name != "" {
+		cfg.Name = name
+	}
+
+	if err := applyDefinitionDefaults(cfg, providerName); err != nil {
+		return nil, fmt.Errorf("invalid cluster definition %s: %w", path, err)
+	}
+
+	// Relative bind mount sources are relative to the definition file
+	for i := range cfg.Nodes {
+		for j := range cfg.Nodes[i].Volumes {
+			v := &cfg.Nodes[i].Volumes[j]
+			if v.Type == config.BindMount && !filepath.IsAbs(v.Source) {
+				abs, err := filepath.Abs(filepath.Join(filepath.Dir(path), v.Source))
+				if err != nil {
+					return nil, fmt.Errorf("failed to resolve bind mount source '%s': %w", v.Source, err)
+				}
+				v.Source = abs
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// applyDefinitionDefaults validates a cluster definition and fills in the
+// values hind would otherwise generate: network and node names, images and
+// the agent environment each node needs. The provider defaults to
+// providerName.
+func applyDefinitionDefaults(cfg *config.Cluster, providerName string) error {
+	if cfg.Name == "" {
+		return fmt.Errorf("cluster name is required")
+	}
+
+	if cfg.Version == "" {
+		cfg.Version = release.Latest().Hind
+	}
+	v, err := release.Get(cfg.Version)
+	if err != nil {
+		return fmt.Errorf("failed to get version: %w", err)
+	}
+	cfg.Version = v.Hind
+
+	if cfg.Provider == "" {
+		cfg.Provider = providerName
+	}
+	if err := ValidateProvider(cfg.Provider); err != nil {
+		return err
+	}
+
+	if err := validateLocation(cfg); err != nil {
+		return err
+	}
+	if err := validateFederation(cfg); err != nil {
+		return err
+	}
+
+	if cfg.Network.Name == "" {
+		cfg.Network.Name = networkName(cfg.Name)
+	}
+
+	if len(cfg.Nodes) == 0 {
+		return fmt.Errorf("at least one node is required")
+	}
+
+	counts := map[string]int{}
+	seen := map[string]bool{}
+
+	// Short names such as "client.01" are scoped to the cluster
+	prefix := "hind." + cfg.Name + "."
+
+	for i := range cfg.Nodes {
+		node := &cfg.Nodes[i]
+
+		switch node.Kind {
+		case config.ConsulNode, config.NomadNode, config.VaultNode:
+		default:
+			return fmt.Errorf("node %d: unknown kind '%s'", i, node.Kind)
+		}
+		if node.Role == "" {
+			node.Role = config.Server
+		}
+		switch node.Role {
+		case config.Server:
+		case config.Client:
+			if node.Kind != config.NomadNode {
+				return fmt.Errorf("node %d: only nomad nodes can have the client role", i)
+			}
+		default:
+			return fmt.Errorf("node %d: unknown role '%s'", i, node.Role)
+		}
+
+		key := node.Kind.String() + "/" + node.Role.String()
+		counts[key]++
+
+		if node.Name == "" {
+			node.Name = nodeName(cfg.Name, node.Kind, node.Role, counts[key])
+		} else if !strings.HasPrefix(node.Name, prefix) {
+			node.Name = prefix + node.Name
+		}
+		if seen[node.Name] {
+			return fmt.Errorf("duplicate node name '%s'", node.Name)
+		}
+		seen[node.Name] = true
+	}
+
+	for i := range cfg.Nodes {
+		node := &cfg.Nodes[i]
+		defaults := newNode(cfg.Name, node.Kind, node.Role, 1, v.Hind)
+
+		if node.Network == "" {
+			node.Network = cfg.Network.Name
+		}
+		if node.Image.Name == "" {
+			node.Image.Name = defaults.Image.Name
+		}
+		if node.Image.Tag == "" && node.Image.Digest == "" {
+			node.Image.Tag = defaults.Image.Tag
+		}
+		if node.Devices == nil {
+			node.Devices = defaults.Devices
+		}
+
+		// User supplied environment takes precedence over the defaults
+		env := defaults.Environment
+		maps.Copy(env, joinEnvironment(cfg.Nodes, *node))
+		maps.Copy(env, clusterEnvironment(cfg, *node))
+		maps.Copy(env, node.Environment)
+		node.Environment = env
+
+		// Named volumes are scoped to the cluster like node names
+		for j := range node.Volumes {
+			v := &node.Volumes[j]
+			if v.Destination == "" {
+				return fmt.Errorf("node '%s': volume %d: destination is required", node.Name, j)
+			}
+			switch v.MountType() {
+			case config.VolumeMount:
+				if v.Name != "" && !strings.HasPrefix(v.Name, prefix) {
+					v.Name = prefix + v.Name
+				}
+			case config.BindMount:
+				if v.Source == "" {
+					return fmt.Errorf("node '%s': volume %d: bind mounts require a source", node.Name, j)
+				}
+			case config.TmpfsMount:
+				if v.Source != "" || v.Name != "" {
+					return fmt.Errorf("node '%s': volume %d: tmpfs mounts cannot have a source", node.Name, j)
+				}
+			default:
+				return fmt.Errorf("node '%s': volume %d: unknown type '%s'", node.Name, j, v.Type)
+			}
+			v.Type = v.MountType()
+		}
+
+		for j, p := range node.Ports {
+			if p.ContainerPort == 0 {
+				return fmt.Errorf("node '%s': port %d: container port is required", node.Name, j)
+			}
+			if p.Protocol == "" {
+				node.Ports[j].Protocol = "tcp"
+			}
+		}
+	}
+
+	return nil
+}
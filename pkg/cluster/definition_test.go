@@ -0,0 +1,233 @@
+package cluster
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stenh0use/hind/pkg/build/release"
+	"github.com/stenh0use/hind/pkg/config"
+)
+
+func TestApplyDefinitionDefaults(t *testing.T) {
+	cfg := &config.Cluster{
+		Name: "dev",
+		Nodes: []config.Node{
+			{Kind: config.ConsulNode},
+			{Kind: config.NomadNode, Role: config.Server},
+			{Kind: config.NomadNode, Role: config.Client},
+			{
+				Name: "client.big",
+				Kind: config.NomadNode,
+				Role: config.Client,
+				Environment: map[string]string{
+					"NOMAD_AGENT_MODE": "client",
+					"EXTRA":            "value",
+				},
+				Ports: []config.PortMapping{{HostPort: 8080, ContainerPort: 80}},
+			},
+		},
+	}
+
+	if err := applyDefinitionDefaults(cfg, DefaultProvider); err != nil {
+		t.Fatalf("applyDefinitionDefaults() error = %v", err)
+	}
+
+	if cfg.Version != release.Latest().Hind {
+		t.Errorf("Version = %q, want %q", cfg.Version, release.Latest().Hind)
+	}
+	if cfg.Network.Name != "hind.dev" {
+		t.Errorf("Network.Name = %q, want %q", cfg.Network.Name, "hind.dev")
+	}
+
+	wantNames := []string{
+		"hind.dev.consul.01",
+		"hind.dev.nomad.01",
+		"hind.dev.client.01",
+		"hind.dev.client.big",
+	}
+	for i, want := range wantNames {
+		if cfg.Nodes[i].Name != want {
+			t.Errorf("Nodes[%d].Name = %q, want %q", i, cfg.Nodes[i].Name, want)
+		}
+		if cfg.Nodes[i].Network != "hind.dev" {
+			t.Errorf("Nodes[%d].Network = %q, want %q", i, cfg.Nodes[i].Network, "hind.dev")
+		}
+	}
+
+	if cfg.Nodes[0].Role != config.Server {
+		t.Errorf("Nodes[0].Role = %q, want server", cfg.Nodes[0].Role)
+	}
+	if cfg.Nodes[2].Image.Name != release.NomadClient.ImageName() {
+		t.Errorf("Nodes[2].Image.Name = %q, want %q", cfg.Nodes[2].Image.Name, release.NomadClient.ImageName())
+	}
+	if len(cfg.Nodes[2].Devices) != 1 || cfg.Nodes[2].Devices[0] != "/dev/fuse" {
+		t.Errorf("Nodes[2].Devices = %v, want [/dev/fuse]", cfg.Nodes[2].Devices)
+	}
+
+	client := cfg.Nodes[3]
+	if client.Environment["EXTRA"] != "value" {
+		t.Errorf("user environment was not kept: %v", client.Environment)
+	}
+	if client.Environment["CONSUL_SERVER_ADDRESS"] != "hind.dev.consul.01" {
+		t.Errorf("CONSUL_SERVER_ADDRESS = %q, want %q", client.Environment["CONSUL_SERVER_ADDRESS"], "hind.dev.consul.01")
+	}
+	if client.Ports[0].Protocol != "tcp" {
+		t.Errorf("Ports[0].Protocol = %q, want tcp", client.Ports[0].Protocol)
+	}
+}
+
+func TestApplyDefinitionDefaults_ACL(t *testing.T) {
+	cfg := &config.Cluster{
+		Name: "dev",
+		ACL:  true,
+		Nodes: []config.Node{
+			{Kind: config.ConsulNode},
+			{Kind: config.NomadNode, Role: config.Client},
+		},
+	}
+
+	if err := applyDefinitionDefaults(cfg, DefaultProvider); err != nil {
+		t.Fatalf("applyDefinitionDefaults() error = %v", err)
+	}
+	if env := cfg.Nodes[0].Environment; env["CONSUL_ACL_ENABLED"] != "true" || env["NOMAD_ACL_ENABLED"] != "" {
+		t.Errorf("consul server environment = %v, want only consul ACLs enabled", env)
+	}
+	if env := cfg.Nodes[1].Environment; env["CONSUL_ACL_ENABLED"] != "true" || env["NOMAD_ACL_ENABLED"] != "true" {
+		t.Errorf("client environment = %v, want consul and nomad ACLs enabled", env)
+	}
+}
+
+func TestApplyDefinitionDefaults_Location(t *testing.T) {
+	cfg := &config.Cluster{
+		Name:       "dev",
+		Datacenter: "dc2",
+		Region:     "eu",
+		Nodes: []config.Node{
+			{Kind: config.ConsulNode},
+			{Kind: config.NomadNode, Role: config.Client},
+		},
+	}
+
+	if err := applyDefinitionDefaults(cfg, DefaultProvider); err != nil {
+		t.Fatalf("applyDefinitionDefaults() error = %v", err)
+	}
+	if env := cfg.Nodes[0].Environment; env["CONSUL_DATACENTER"] != "dc2" || env["NOMAD_REGION"] != "" {
+		t.Errorf("consul server environment = %v, want only the consul datacenter", env)
+	}
+	if env := cfg.Nodes[1].Environment; env["NOMAD_DATACENTER"] != "dc2" || env["NOMAD_REGION"] != "eu" {
+		t.Errorf("client environment = %v, want the nomad datacenter and region", env)
+	}
+}
+
+func TestApplyDefinitionDefaults_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Cluster
+	}{
+		{
+			name: "missing name",
+			cfg:  config.Cluster{Nodes: []config.Node{{Kind: config.ConsulNode}}},
+		},
+		{
+			name: "no nodes",
+			cfg:  config.Cluster{Name: "dev"},
+		},
+		{
+			name: "unknown version",
+			cfg:  config.Cluster{Name: "dev", Version: "999.0.0", Nodes: []config.Node{{Kind: config.ConsulNode}}},
+		},
+		{
+			name: "unknown kind",
+			cfg:  config.Cluster{Name: "dev", Nodes: []config.Node{{Kind: "boundary"}}},
+		},
+		{
+			name: "invalid datacenter",
+			cfg:  config.Cluster{Name: "dev", Datacenter: "DC 1", Nodes: []config.Node{{Kind: config.ConsulNode}}},
+		},
+		{
+			name: "consul client role",
+			cfg:  config.Cluster{Name: "dev", Nodes: []config.Node{{Kind: config.ConsulNode, Role: config.Client}}},
+		},
+		{
+			name: "duplicate names",
+			cfg: config.Cluster{Name: "dev", Nodes: []config.Node{
+				{Name: "consul.01", Kind: config.ConsulNode},
+				{Name: "hind.dev.consul.01", Kind: config.ConsulNode},
+			}},
+		},
+		{
+			name: "volume without destination",
+			cfg: config.Cluster{Name: "dev", Nodes: []config.Node{
+				{Kind: config.ConsulNode, Volumes: []config.Volume{{Name: "data"}}},
+			}},
+		},
+		{
+			name: "bind mount without source",
+			cfg: config.Cluster{Name: "dev", Nodes: []config.Node{
+				{Kind: config.ConsulNode, Volumes: []config.Volume{{Type: config.BindMount, Destination: "/data"}}},
+			}},
+		},
+		{
+			name: "unknown volume type",
+			cfg: config.Cluster{Name: "dev", Nodes: []config.Node{
+				{Kind: config.ConsulNode, Volumes: []config.Volume{{Type: "nfs", Destination: "/data"}}},
+			}},
+		},
+		{
+			name: "port without container port",
+			cfg: config.Cluster{Name: "dev", Nodes: []config.Node{
+				{Kind: config.ConsulNode, Ports: []config.PortMapping{{HostPort: 8500}}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := applyDefinitionDefaults(&tt.cfg, DefaultProvider); err == nil {
+				t.Error("applyDefinitionDefaults() want error, got nil")
+			}
+		})
+	}
+}
+
+func TestLoadDefinition_Volumes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cluster.yaml")
+	data := `name: dev
+nodes:
+  - kind: consul
+    volumes:
+      - name: consul-data
+        destination: /consul/data
+      - source: ./jobs
+        destination: /jobs
+        readOnly: true
+      - type: tmpfs
+        destination: /scratch
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("failed to write definition: %v", err)
+	}
+
+	cfg, err := LoadDefinition(path, "", DefaultProvider)
+	if err != nil {
+		t.Fatalf("LoadDefinition() error = %v", err)
+	}
+
+	want := []config.Volume{
+		{Type: config.VolumeMount, Name: "hind.dev.consul-data", Destination: "/consul/data"},
+		{Type: config.BindMount, Source: filepath.Join(dir, "jobs"), Destination: "/jobs", ReadOnly: true},
+		{Type: config.TmpfsMount, Destination: "/scratch"},
+	}
+	got := cfg.Nodes[0].Volumes
+	if len(got) != len(want) {
+		t.Fatalf("Volumes = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].Type != want[i].Type || got[i].Name != want[i].Name || got[i].Source != want[i].Source ||
+			got[i].Destination != want[i].Destination || got[i].ReadOnly != want[i].ReadOnly {
+			t.Errorf("Volumes[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/apex/log"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/file"
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+// DiscoveredCluster is a cluster found by its saved config, by the cluster
+// label on its containers and network, or both.
+type DiscoveredCluster struct {
+	Name string
+	// Provider the cluster runs on, or its resources were found on
+	Provider string
+	// HasConfig reports whether the cluster has a saved config
+	HasConfig bool
+	// Containers carrying the cluster label, in any state
+	Containers []provider.ContainerInfo
+	// Network carrying the cluster label, nil if none was found
+	Network *provider.NetworkInfo
+}
+
+// Orphaned reports whether the cluster has containers or a network but no
+// saved config, eg. when the state was lost or a run crashed before saving.
+func (d DiscoveredCluster) Orphaned() bool {
+	return !d.HasConfig
+}
+
+// Missing reports whether the cluster has a saved config but no containers
+func (d DiscoveredCluster) Missing() bool {
+	return d.HasConfig && len(d.Containers) == 0
+}
+
+// Discover returns the clusters with a saved config merged with the
+// clusters found by their label on the provider of each saved cluster and
+// providerName, the provider selected for new clusters. Providers that
+// can't be reached are skipped with a warning.
+func Discover(ctx context.Context, logger *log.Logger, providerName string) ([]DiscoveredCluster, error) {
+	fm, err := file.NewFromHomeDir(DefaultConfigParentDir, DefaultConfigName)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := List()
+	if err != nil {
+		return nil, err
+	}
+
+	configs := readConfigs(logger, fm, names)
+
+	clients := map[string]provider.Client{}
+	providers := []string{providerName}
+	for _, cfg := range configs {
+		providers = append(providers, cfg.Provider)
+	}
+	for _, name := range providers {
+		if _, ok := clients[name]; ok {
+			continue
+		}
+		client, err := newProvider(logger, name)
+		if err != nil {
+			logger.Warnf("Skipping provider '%s': %v", name, err)
+			continue
+		}
+		clients[name] = client
+	}
+
+	return discover(ctx, logger, configs, clients), nil
+}
+
+// readConfigs reads the saved config of each cluster. Clusters with an
+// invalid config are still returned, on the default provider.
+func readConfigs(logger *log.Logger, fm *file.Manager, names []string) []*config.Cluster {
+	var configs []*config.Cluster
+	for _, name := range names {
+		// A crashed first run leaves the directory without a config
+		path := file.JoinPath(ClusterConfigDir, name, ClusterConfigFile)
+		if !fm.FileExists(path) {
+			continue
+		}
+
+		cfg := &config.Cluster{}
+		if data, err := fm.ReadFile(path); err != nil {
+			logger.Warnf("Failed to read config of cluster '%s': %v", name, err)
+		} else if err := config.UnmarshalSaved(data, cfg); err != nil {
+			logger.Warnf("Invalid config of cluster '%s': %v", name, err)
+		}
+
+		cfg.Name = name
+		if cfg.Provider == "" {
+			cfg.Provider = DefaultProvider
+		}
+		configs = append(configs, cfg)
+	}
+	return configs
+}
+
+// discover merges the saved configs with the labeled resources found on
+// each provider. A saved cluster only takes resources from its own provider.
+func discover(ctx context.Context, logger *log.Logger, configs []*config.Cluster, clients map[string]provider.Client) []DiscoveredCluster {
+	clusters := map[string]*DiscoveredCluster{}
+	for _, cfg := range configs {
+		clusters[cfg.Name] = &DiscoveredCluster{Name: cfg.Name, Provider: cfg.Provider, HasConfig: true}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(clients)) {
+		containers, networks, err := labeledResources(ctx, clients[name])
+		if err != nil {
+			logger.Warnf("Failed to discover clusters on provider '%s': %v", name, err)
+			continue
+		}
+
+		claim := func(clusterName string) *DiscoveredCluster {
+			if clusterName == "" {
+				return nil
+			}
+			d, ok := clusters[clusterName]
+			if !ok {
+				d = &DiscoveredCluster{Name: clusterName, Provider: name}
+				clusters[clusterName] = d
+			}
+			// Providers can share an engine, eg. dockercli and dockerapi,
+			// the first to find an unsaved cluster keeps it
+			if d.Provider != name {
+				return nil
+			}
+			return d
+		}
+
+		for _, c := range containers {
+			if d := claim(c.Labels[ClusterLabel]); d != nil {
+				d.Containers = append(d.Containers, c)
+			}
+		}
+		for _, n := range networks {
+			if d := claim(n.Labels[ClusterLabel]); d != nil && d.Network == nil {
+				d.Network = &n
+			}
+		}
+	}
+
+	var discovered []DiscoveredCluster
+	for _, name := range slices.Sorted(maps.Keys(clusters)) {
+		discovered = append(discovered, *clusters[name])
+	}
+	return discovered
+}
+
+// labeledResources lists the containers and networks carrying the cluster label
+func labeledResources(ctx context.Context, client provider.Client) ([]provider.ContainerInfo, []provider.NetworkInfo, error) {
+	filters := []string{"label=" + ClusterLabel}
+
+	containers, err := client.ListContainers(ctx, filters)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	networks, err := client.ListNetworks(ctx, filters)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list networks: %w", err)
+	}
+	return containers, networks, nil
+}
@@ -0,0 +1,90 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/file"
+	"github.com/stenh0use/hind/pkg/provider"
+	"github.com/stenh0use/hind/pkg/provider/fake"
+)
+
+// startFakeCluster starts a cluster on p, removing its saved config when
+// orphaned is set as if the state was lost
+func startFakeCluster(t *testing.T, name string, p *fake.Provider, orphaned bool) *Manager {
+	t.Helper()
+	m := newFakeManager(t, name, p)
+	if _, err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if orphaned {
+		if err := m.fm.RemoveDir(file.JoinPath(ClusterConfigDir, name)); err != nil {
+			t.Fatalf("RemoveDir() error = %v", err)
+		}
+	}
+	return m
+}
+
+func TestDiscover(t *testing.T) {
+	p := fake.New()
+	dev := startFakeCluster(t, "dev", p, false)
+	startFakeCluster(t, "lost", p, true)
+
+	logger := &log.Logger{Handler: discard.New(), Level: log.ErrorLevel}
+	configs := []*config.Cluster{
+		{Name: "dev", Provider: "fake"},
+		{Name: "gone", Provider: "fake"},
+		// Resources are only claimed from the cluster's own provider
+		{Name: "elsewhere", Provider: "podman"},
+	}
+	got := discover(context.Background(), logger, configs, map[string]provider.Client{"fake": p})
+
+	want := []struct {
+		name       string
+		orphaned   bool
+		missing    bool
+		containers int
+		network    bool
+	}{
+		{name: "dev", containers: len(dev.Config().Nodes), network: true},
+		{name: "elsewhere", missing: true},
+		{name: "gone", missing: true},
+		{name: "lost", orphaned: true, containers: len(dev.Config().Nodes), network: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("discover() = %d clusters, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		d := got[i]
+		if d.Name != w.name {
+			t.Errorf("discover()[%d] = %s, want %s", i, d.Name, w.name)
+			continue
+		}
+		if d.Orphaned() != w.orphaned || d.Missing() != w.missing {
+			t.Errorf("%s: orphaned = %t, missing = %t, want %t, %t", d.Name, d.Orphaned(), d.Missing(), w.orphaned, w.missing)
+		}
+		if len(d.Containers) != w.containers || (d.Network != nil) != w.network {
+			t.Errorf("%s: %d containers, network %t, want %d, %t", d.Name, len(d.Containers), d.Network != nil, w.containers, w.network)
+		}
+	}
+	if got[3].Provider != "fake" {
+		t.Errorf("orphaned cluster provider = %q, want fake", got[3].Provider)
+	}
+}
+
+func TestDiscover_ProviderError(t *testing.T) {
+	p := fake.New()
+	p.Fail(fake.Failure{Method: fake.MethodListContainers})
+
+	logger := &log.Logger{Handler: discard.New(), Level: log.ErrorLevel}
+	configs := []*config.Cluster{{Name: "dev", Provider: "fake"}}
+	got := discover(context.Background(), logger, configs, map[string]provider.Client{"fake": p})
+
+	if len(got) != 1 || got[0].Name != "dev" || !got[0].HasConfig {
+		t.Errorf("discover() = %+v, want the saved cluster only", got)
+	}
+}
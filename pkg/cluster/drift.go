@@ -0,0 +1,132 @@
+package cluster
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/stenh0use/hind/pkg/config"
+)
+
+// Recreate reasons reported in a RecreateAction
+const (
+	ReasonUnhealthy      = "unhealthy"
+	ReasonConfigMismatch = "config_mismatch"
+)
+
+// configDrift compares the desired node configuration with the spec of the
+// running container and returns the names of the fields that differ.
+//
+// Environment variables and labels are compared as subsets, the container
+// also carries the ones set by its image. A port with no host port matches
+// any host port the provider picked.
+func configDrift(desired, actual config.Node) []string {
+	var drift []string
+
+	if desired.Image.Ref() != actual.Image.Ref() {
+		drift = append(drift, "image")
+	}
+	if !isSubset(desired.Environment, actual.Environment) {
+		drift = append(drift, "env")
+	}
+	if !portsMatch(desired.Ports, actual.Ports) {
+		drift = append(drift, "ports")
+	}
+	if !slices.Equal(sortedStrings(desired.Devices, normalizeDevice), sortedStrings(actual.Devices, normalizeDevice)) {
+		drift = append(drift, "devices")
+	}
+	if !slices.Equal(sortedStrings(volumeKeys(desired.Volumes), nil), sortedStrings(volumeKeys(actual.Volumes), nil)) {
+		drift = append(drift, "volumes")
+	}
+	if !isSubset(desired.Labels, actual.Labels) {
+		drift = append(drift, "labels")
+	}
+
+	return drift
+}
+
+// isSubset reports whether every key/value pair in want is also in got
+func isSubset(want, got map[string]string) bool {
+	for k, v := range want {
+		if gv, ok := got[k]; !ok || gv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// portsMatch reports whether both lists publish the same ports
+func portsMatch(desired, actual []config.PortMapping) bool {
+	if len(desired) != len(actual) {
+		return false
+	}
+
+	remaining := slices.Clone(actual)
+	for _, d := range desired {
+		if d.Protocol == "" {
+			d.Protocol = "tcp"
+		}
+		i := slices.IndexFunc(remaining, func(a config.PortMapping) bool {
+			return a.ContainerPort == d.ContainerPort &&
+				a.Protocol == d.Protocol &&
+				a.ListenAddress == d.ListenAddress &&
+				(d.HostPort == 0 || a.HostPort == d.HostPort)
+		})
+		if i < 0 {
+			return false
+		}
+		remaining = slices.Delete(remaining, i, i+1)
+	}
+	return true
+}
+
+// normalizeDevice expands a device in the short form used by docker
+// eg. /dev/fuse to /dev/fuse:/dev/fuse:rwm
+func normalizeDevice(d string) string {
+	parts := strings.Split(d, ":")
+	switch len(parts) {
+	case 1:
+		return fmt.Sprintf("%s:%s:rwm", parts[0], parts[0])
+	case 2:
+		return fmt.Sprintf("%s:%s:rwm", parts[0], parts[1])
+	default:
+		return d
+	}
+}
+
+// volumeKeys identifies each volume by its type, source, destination and mode
+func volumeKeys(volumes []config.Volume) []string {
+	keys := make([]string, 0, len(volumes))
+	for _, v := range volumes {
+		source := v.Source
+		if v.MountType() == config.VolumeMount {
+			source = v.Name
+		}
+		keys = append(keys, fmt.Sprintf("%s:%s:%s:%t", v.MountType(), source, v.Destination, v.ReadOnly))
+	}
+	return keys
+}
+
+// sortedStrings returns a sorted copy of values, optionally normalized
+func sortedStrings(values []string, normalize func(string) string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if normalize != nil {
+			v = normalize(v)
+		}
+		out = append(out, v)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// nodeLabels returns the labels applied to a node's container, the node's
+// own labels plus the labels identifying the cluster.
+func (m *Manager) nodeLabels(node config.Node) config.Labels {
+	labels := config.Labels{}
+	maps.Copy(labels, node.Labels)
+	labels[ClusterLabel] = m.config.Name
+	labels[VersionLabel] = m.config.Version
+	return labels
+}
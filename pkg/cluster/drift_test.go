@@ -0,0 +1,127 @@
+package cluster
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stenh0use/hind/pkg/config"
+)
+
+func TestConfigDrift(t *testing.T) {
+	desired := config.Node{
+		Name:        "hind.test.consul.01",
+		Image:       config.Image{Name: "docker.io/stenh0use/hind.consul", Tag: "0.4.0"},
+		Environment: map[string]string{"CONSUL_AGENT_MODE": "server"},
+		Ports:       []config.PortMapping{{HostPort: 8500, ContainerPort: 8500}, {ContainerPort: 8600, Protocol: "udp"}},
+		Devices:     []string{"/dev/fuse"},
+		Volumes:     []config.Volume{{Source: "/srv/data", Destination: "/data"}},
+		Labels:      config.Labels{"hind.cluster": "test"},
+	}
+
+	// running is the spec a provider reports for a container created from desired
+	running := func() config.Node {
+		return config.Node{
+			Name:  "hind.test.consul.01",
+			Image: config.Image{Name: "docker.io/stenh0use/hind.consul", Tag: "0.4.0"},
+			Environment: map[string]string{
+				"CONSUL_AGENT_MODE": "server",
+				"PATH":              "/usr/local/bin:/usr/bin",
+			},
+			Ports: []config.PortMapping{
+				{ContainerPort: 8600, HostPort: 32768, Protocol: "udp"},
+				{ContainerPort: 8500, HostPort: 8500, Protocol: "tcp"},
+			},
+			Devices: []string{"/dev/fuse:/dev/fuse:rwm"},
+			Volumes: []config.Volume{{Source: "/srv/data", Destination: "/data"}},
+			Labels:  config.Labels{"hind.cluster": "test", "org.opencontainers.image.title": "consul"},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(n *config.Node)
+		want   []string
+	}{
+		{
+			name:   "no drift",
+			mutate: func(n *config.Node) {},
+		},
+		{
+			name:   "different image tag",
+			mutate: func(n *config.Node) { n.Image.Tag = "0.3.0" },
+			want:   []string{"image"},
+		},
+		{
+			name:   "pinned by digest",
+			mutate: func(n *config.Node) { n.Image = config.Image{Name: n.Image.Name, Digest: "sha256:abc"} },
+			want:   []string{"image"},
+		},
+		{
+			name:   "changed env value",
+			mutate: func(n *config.Node) { n.Environment["CONSUL_AGENT_MODE"] = "client" },
+			want:   []string{"env"},
+		},
+		{
+			name:   "different host port",
+			mutate: func(n *config.Node) { n.Ports[1].HostPort = 18500 },
+			want:   []string{"ports"},
+		},
+		{
+			name:   "missing port",
+			mutate: func(n *config.Node) { n.Ports = n.Ports[:1] },
+			want:   []string{"ports"},
+		},
+		{
+			name:   "missing device",
+			mutate: func(n *config.Node) { n.Devices = nil },
+			want:   []string{"devices"},
+		},
+		{
+			name:   "different volume source",
+			mutate: func(n *config.Node) { n.Volumes[0].Source = "/srv/other" },
+			want:   []string{"volumes"},
+		},
+		{
+			name:   "missing label",
+			mutate: func(n *config.Node) { delete(n.Labels, "hind.cluster") },
+			want:   []string{"labels"},
+		},
+		{
+			name: "multiple fields",
+			mutate: func(n *config.Node) {
+				n.Image.Tag = "0.3.0"
+				n.Devices = nil
+			},
+			want: []string{"image", "devices"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := running()
+			tt.mutate(&actual)
+
+			got := configDrift(desired, actual)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("configDrift() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeDevice(t *testing.T) {
+	tests := []struct {
+		device string
+		want   string
+	}{
+		{"/dev/fuse", "/dev/fuse:/dev/fuse:rwm"},
+		{"/dev/fuse:/dev/fuse0", "/dev/fuse:/dev/fuse0:rwm"},
+		{"/dev/fuse:/dev/fuse:r", "/dev/fuse:/dev/fuse:r"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeDevice(tt.device); got != tt.want {
+			t.Errorf("normalizeDevice(%q) = %q, want %q", tt.device, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package cluster
+
+import (
+	"context"
+	"maps"
+
+	"github.com/stenh0use/hind/pkg/config"
+)
+
+// ClientEnvironment returns the environment the Consul, Nomad and Vault
+// CLIs need on the host to reach the cluster, through the ports the
+// provider reports as published. Services without a published API port
+// are left out, and VAULT_TOKEN until the root token was collected. With
+// ACLs enabled the management tokens are set once bootstrapped, and with
+// TLS enabled the CA certificate and the client certificate of the CLIs.
+func (m *Manager) ClientEnvironment(ctx context.Context) (map[string]string, error) {
+	endpoints, err := m.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	urls := map[string]string{}
+	for _, e := range endpoints {
+		urls[e.Name] = e.URL
+	}
+
+	env := map[string]string{}
+	for _, svc := range endpointServices {
+		url, ok := urls[svc.name]
+		if !ok {
+			continue
+		}
+		env[agentAddrEnv[svc.kind]] = url
+		maps.Copy(env, m.certEnvironment(m.tlsFile(), CLICertFile, CLIKeyFile, svc.kind))
+	}
+	tokens := m.aclTokens()
+	if _, ok := env[agentAddrEnv[config.ConsulNode]]; ok && tokens.ConsulManagement != "" {
+		env["CONSUL_HTTP_TOKEN"] = tokens.ConsulManagement
+	}
+	if _, ok := env[agentAddrEnv[config.NomadNode]]; ok && tokens.NomadManagement != "" {
+		env["NOMAD_TOKEN"] = tokens.NomadManagement
+	}
+	if _, ok := env[agentAddrEnv[config.VaultNode]]; ok {
+		if token := m.vaultToken(); token != "" {
+			env["VAULT_TOKEN"] = token
+		}
+	}
+	return env, nil
+}
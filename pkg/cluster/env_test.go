@@ -0,0 +1,59 @@
+package cluster
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider/fake"
+)
+
+func TestManager_ClientEnvironment(t *testing.T) {
+	// The default ports are taken, the cluster publishes the next free ones
+	m := newFakeManager(t, "dev", fake.New())
+	withBusyPorts(t, 4646, 8500)
+	if err := m.AllocateHostPorts(); err != nil {
+		t.Fatalf("AllocateHostPorts() error = %v", err)
+	}
+
+	want := map[string]string{
+		"NOMAD_ADDR":       "http://localhost:4647",
+		"CONSUL_HTTP_ADDR": "http://localhost:8501",
+		"VAULT_ADDR":       "http://localhost:8201",
+	}
+	// The token is left out until it was collected
+	if got := clientEnvironment(t, m); !maps.Equal(got, want) {
+		t.Errorf("ClientEnvironment() = %v, want %v", got, want)
+	}
+
+	if err := m.saveVaultCredentials(&VaultCredentials{RootToken: "hvs.root", UnsealKey: "key"}); err != nil {
+		t.Fatal(err)
+	}
+	want["VAULT_TOKEN"] = "hvs.root"
+	if got := clientEnvironment(t, m); !maps.Equal(got, want) {
+		t.Errorf("ClientEnvironment() = %v, want %v", got, want)
+	}
+
+	// Without vault nodes there is no vault address or token
+	var nodes []config.Node
+	for _, n := range m.config.Nodes {
+		if n.Kind != config.VaultNode {
+			nodes = append(nodes, n)
+		}
+	}
+	m.config.Nodes = nodes
+	if got := clientEnvironment(t, m); len(got) != 2 {
+		t.Errorf("ClientEnvironment() = %v, want NOMAD_ADDR and CONSUL_HTTP_ADDR", got)
+	}
+}
+
+// clientEnvironment returns the client environment of a cluster
+func clientEnvironment(t *testing.T, m *Manager) map[string]string {
+	t.Helper()
+	env, err := m.ClientEnvironment(context.Background())
+	if err != nil {
+		t.Fatalf("ClientEnvironment() error = %v", err)
+	}
+	return env
+}
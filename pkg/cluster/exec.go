@@ -0,0 +1,103 @@
+package cluster
+
+import (
+	"context"
+	"maps"
+	"net"
+	"strconv"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+// agentPorts are the container ports of the agent HTTP APIs
+var agentPorts = map[config.Kind]int{
+	config.ConsulNode: 8500,
+	config.NomadNode:  4646,
+	config.VaultNode:  8200,
+}
+
+// agentAddrEnv are the variables the CLIs read the agent address from
+var agentAddrEnv = map[config.Kind]string{
+	config.ConsulNode: "CONSUL_HTTP_ADDR",
+	config.NomadNode:  "NOMAD_ADDR",
+	config.VaultNode:  "VAULT_ADDR",
+}
+
+// ResolveNode returns the node of the cluster matching target, see
+// ResolveNodes. The first node is used when a kind or role matches several,
+// eg. vault is the first vault server.
+func (m *Manager) ResolveNode(target string) (config.Node, error) {
+	nodes, err := m.ResolveNodes(target)
+	if err != nil {
+		return config.Node{}, err
+	}
+	return nodes[0], nil
+}
+
+// Exec runs a command in the node matching target, with the environment of
+// AgentEnvironment added to opts.Env
+func (m *Manager) Exec(ctx context.Context, target string, opts provider.ExecOptions) error {
+	node, err := m.ResolveNode(target)
+	if err != nil {
+		return err
+	}
+
+	if err := m.checkRunning(ctx, node); err != nil {
+		return err
+	}
+
+	env := m.AgentEnvironment(node)
+	maps.Copy(env, opts.Env)
+	opts.Env = env
+
+	return m.provider.Exec(ctx, node.Name, opts)
+}
+
+// AgentEnvironment returns the environment the Consul, Nomad and Vault
+// CLIs need on a node: the local agent when the node runs it, or else the
+// first server of its kind. Every node runs a Consul agent. VAULT_TOKEN is
+// the stored root token, once it was collected, and with ACLs enabled
+// CONSUL_HTTP_TOKEN and NOMAD_TOKEN are the management tokens. With TLS
+// enabled the CLIs use the certificates mounted in the node.
+func (m *Manager) AgentEnvironment(node config.Node) map[string]string {
+	tokens := m.aclTokens()
+	env := m.nodeCertEnvironment()
+	env[agentAddrEnv[config.ConsulNode]] = m.agentAddress("127.0.0.1", config.ConsulNode)
+	if tokens.ConsulManagement != "" {
+		env["CONSUL_HTTP_TOKEN"] = tokens.ConsulManagement
+	}
+
+	if addr := m.agentHost(node, config.NomadNode); addr != "" {
+		env[agentAddrEnv[config.NomadNode]] = m.agentAddress(addr, config.NomadNode)
+		if tokens.NomadManagement != "" {
+			env["NOMAD_TOKEN"] = tokens.NomadManagement
+		}
+	}
+	if addr := m.agentHost(node, config.VaultNode); addr != "" {
+		env[agentAddrEnv[config.VaultNode]] = m.agentAddress(addr, config.VaultNode)
+		if token := m.vaultToken(); token != "" {
+			env["VAULT_TOKEN"] = token
+		}
+	}
+	return env
+}
+
+// agentHost returns the host serving the API of kind to node, empty when
+// the cluster has no such nodes
+func (m *Manager) agentHost(node config.Node, kind config.Kind) string {
+	if node.Kind == kind {
+		return "127.0.0.1"
+	}
+	for _, n := range m.config.Nodes {
+		if n.Kind == kind && n.Role == config.Server {
+			return n.Name
+		}
+	}
+	return ""
+}
+
+// agentAddress returns the address of the API of kind on host
+func (m *Manager) agentAddress(host string, kind config.Kind) string {
+	return m.apiScheme() + "://" + net.JoinHostPort(host, strconv.Itoa(agentPorts[kind]))
+}
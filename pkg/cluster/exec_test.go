@@ -0,0 +1,96 @@
+package cluster
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+	"github.com/stenh0use/hind/pkg/provider/fake"
+)
+
+func TestManager_AgentEnvironment(t *testing.T) {
+	m := newFakeManager(t, "dev", fake.New())
+	if err := m.saveVaultCredentials(&VaultCredentials{RootToken: "hvs.root", UnsealKey: "key"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		node string
+		want map[string]string
+	}{
+		{
+			node: "hind.dev.client.01",
+			want: map[string]string{
+				"CONSUL_HTTP_ADDR": "http://127.0.0.1:8500",
+				"NOMAD_ADDR":       "http://127.0.0.1:4646",
+				"VAULT_ADDR":       "http://hind.dev.vault.01:8200",
+				"VAULT_TOKEN":      "hvs.root",
+			},
+		},
+		{
+			node: "hind.dev.vault.01",
+			want: map[string]string{
+				"CONSUL_HTTP_ADDR": "http://127.0.0.1:8500",
+				"NOMAD_ADDR":       "http://hind.dev.nomad.01:4646",
+				"VAULT_ADDR":       "http://127.0.0.1:8200",
+				"VAULT_TOKEN":      "hvs.root",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.node, func(t *testing.T) {
+			node := m.findNodeConfigByName(tt.node)
+			if node == nil {
+				t.Fatalf("node %s not found", tt.node)
+			}
+			if got := m.AgentEnvironment(*node); !maps.Equal(got, tt.want) {
+				t.Errorf("AgentEnvironment() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Without vault servers there is no vault address or token
+	m.config.Nodes = []config.Node{*m.findNodeConfigByName("hind.dev.consul.01")}
+	if got := m.AgentEnvironment(m.config.Nodes[0]); len(got) != 1 {
+		t.Errorf("AgentEnvironment() = %v, want only CONSUL_HTTP_ADDR", got)
+	}
+}
+
+func TestManager_Exec(t *testing.T) {
+	var (
+		gotNode string
+		gotEnv  map[string]string
+	)
+	p := fake.New(fake.WithExec(func(_ context.Context, name string, opts provider.ExecOptions) error {
+		gotNode, gotEnv = name, opts.Env
+		return nil
+	}))
+	m := newFakeManager(t, "dev", p)
+	if _, err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	opts := provider.ExecOptions{
+		Cmd: []string{"vault", "status"},
+		Env: map[string]string{"VAULT_TOKEN": "custom"},
+	}
+	if err := m.Exec(context.Background(), "vault", opts); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if gotNode != "hind.dev.vault.01" {
+		t.Errorf("Exec() ran in %s, want hind.dev.vault.01", gotNode)
+	}
+	if gotEnv["VAULT_ADDR"] != "http://127.0.0.1:8200" || gotEnv["VAULT_TOKEN"] != "custom" {
+		t.Errorf("Exec() env = %v, want the agent env with the given token", gotEnv)
+	}
+
+	if err := p.SetStatus("hind.dev.vault.01", provider.Stopped); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Exec(context.Background(), "vault", opts); err == nil {
+		t.Error("Exec() in a stopped node error = nil, want error")
+	}
+}
@@ -0,0 +1,243 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+// Reconcile tiers. The nodes of a tier join the nodes of earlier tiers, so
+// a tier is only changed once the containers of the earlier ones are running.
+const (
+	consulServerTier = iota
+	serverTier
+	clientTier
+	numTiers
+)
+
+// nodeTier returns the tier of a node: Consul servers, then the Nomad and
+// Vault servers that join them, then the clients.
+func nodeTier(node config.Node) int {
+	switch {
+	case node.Role == config.Client:
+		return clientTier
+	case node.Kind == config.ConsulNode:
+		return consulServerTier
+	default:
+		return serverTier
+	}
+}
+
+// nodeAction is a change the plan makes to the container of one node
+type nodeAction struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// executeReconcilePlan executes infrastructure changes. The network comes
+// first, then the containers tier by tier, in parallel within a tier.
+func (m *Manager) executeReconcilePlan(ctx context.Context, plan *ReconcilePlan) error {
+	// Step 1: Create network if needed
+	if plan.NetworkToCreate != nil {
+		m.logger.Infof("Creating network '%s'", plan.NetworkToCreate.Name)
+		plan.NetworkToCreate.Labels = config.Labels{
+			ClusterLabel: m.config.Name,
+			VersionLabel: m.config.Version,
+		}
+		id, err := m.provider.CreateNetwork(ctx, *plan.NetworkToCreate)
+		if err != nil {
+			return fmt.Errorf("failed to create network: %w", err)
+		}
+		m.logger.Infof("Created network '%s' (id: %s)", plan.NetworkToCreate.Name, id)
+	}
+
+	// Step 2: Recreate, create and start containers, one tier at a time
+	tiers := m.tierActions(plan)
+	for tier, actions := range tiers {
+		if len(actions) == 0 {
+			continue
+		}
+		if err := m.runActions(ctx, actions); err != nil {
+			return err
+		}
+
+		// Later tiers join this one, wait for it before moving on
+		if hasActions(tiers[tier+1:]) {
+			names := make([]string, 0, len(actions))
+			for _, a := range actions {
+				names = append(names, a.name)
+			}
+			m.logger.Debugf("Waiting for %s to be running", strings.Join(names, ", "))
+			if err := m.waitForNodesRunning(ctx, names, DefaultContainerStartTimeout); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// tierActions groups the container changes of the plan by node tier
+func (m *Manager) tierActions(plan *ReconcilePlan) [numTiers][]nodeAction {
+	var tiers [numTiers][]nodeAction
+
+	for _, action := range plan.ContainersToRecreate {
+		tiers[nodeTier(action.NewConfig)] = append(tiers[nodeTier(action.NewConfig)], nodeAction{
+			name: action.ExistingName,
+			run:  func(ctx context.Context) error { return m.recreateContainer(ctx, action) },
+		})
+	}
+	for _, node := range plan.ContainersToCreate {
+		tiers[nodeTier(node)] = append(tiers[nodeTier(node)], nodeAction{
+			name: node.Name,
+			run:  func(ctx context.Context) error { return m.createContainer(ctx, node) },
+		})
+	}
+	for _, name := range plan.ContainersToStart {
+		tier := clientTier
+		if node := m.findNodeConfigByName(name); node != nil {
+			tier = nodeTier(*node)
+		}
+		tiers[tier] = append(tiers[tier], nodeAction{
+			name: name,
+			run:  func(ctx context.Context) error { return m.startContainer(ctx, name) },
+		})
+	}
+
+	return tiers
+}
+
+func hasActions(tiers [][]nodeAction) bool {
+	for _, actions := range tiers {
+		if len(actions) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// runActions runs the actions in parallel, at most concurrency at a time,
+// and waits for all of them. The errors of failed actions are joined.
+func (m *Manager) runActions(ctx context.Context, actions []nodeAction) error {
+	limit := m.concurrency
+	if limit < 1 {
+		limit = DefaultConcurrency
+	}
+	sem := make(chan struct{}, limit)
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(actions))
+	for i, action := range actions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = fmt.Errorf("container '%s': %w", action.name, ctx.Err())
+				return
+			}
+			errs[i] = action.run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// recreateContainer replaces an unhealthy or drifted container
+func (m *Manager) recreateContainer(ctx context.Context, action RecreateAction) error {
+	if action.Reason == ReasonConfigMismatch {
+		m.logger.Infof("Recreating container '%s', config changed: %s",
+			action.ExistingName, strings.Join(action.Changes, ", "))
+	} else {
+		m.logger.Infof("Recreating %s container '%s'", action.Reason, action.ExistingName)
+	}
+
+	// Stop (ignore errors if already stopped)
+	_ = m.provider.StopContainer(ctx, action.ExistingName)
+
+	if err := m.provider.DeleteContainer(ctx, action.ExistingName); err != nil {
+		return fmt.Errorf("failed to delete container '%s': %w", action.ExistingName, err)
+	}
+
+	action.NewConfig.Labels = m.nodeLabels(action.NewConfig)
+	action.NewConfig.Volumes = m.nodeVolumes(action.NewConfig)
+	env, err := m.containerEnvironment(action.NewConfig)
+	if err != nil {
+		return fmt.Errorf("failed to recreate container '%s': %w", action.ExistingName, err)
+	}
+	action.NewConfig.Environment = env
+	id, err := m.provider.CreateContainer(ctx, action.NewConfig)
+	if err != nil {
+		return fmt.Errorf("failed to recreate container '%s': %w", action.ExistingName, err)
+	}
+	m.logger.Infof("Recreated container '%s' (id: %s)", action.ExistingName, id)
+	return nil
+}
+
+// createContainer creates and starts the container of a new node
+func (m *Manager) createContainer(ctx context.Context, node config.Node) error {
+	m.logger.Infof("Creating container '%s'", node.Name)
+	node.Labels = m.nodeLabels(node)
+	node.Volumes = m.nodeVolumes(node)
+	env, err := m.containerEnvironment(node)
+	if err != nil {
+		return fmt.Errorf("failed to create container '%s': %w", node.Name, err)
+	}
+	node.Environment = env
+	id, err := m.provider.CreateContainer(ctx, node)
+	if err != nil {
+		return fmt.Errorf("failed to create container '%s': %w", node.Name, err)
+	}
+	m.logger.Infof("Created container '%s' (id: %s)", node.Name, id)
+	return nil
+}
+
+// startContainer starts a stopped container
+func (m *Manager) startContainer(ctx context.Context, name string) error {
+	m.logger.Infof("Starting container '%s'", name)
+	if err := m.provider.StartContainer(ctx, name); err != nil {
+		return fmt.Errorf("failed to start container '%s': %w", name, err)
+	}
+	m.logger.Infof("Started container '%s'", name)
+	return nil
+}
+
+// waitForNodesRunning waits for the containers of the named nodes to be running
+func (m *Manager) waitForNodesRunning(ctx context.Context, names []string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for time.Now().Before(deadline) {
+		pending := ""
+		for _, name := range names {
+			info, err := m.provider.InspectContainer(ctx, name)
+			if err != nil {
+				return fmt.Errorf("failed to inspect node '%s': %w", name, err)
+			}
+			if info == nil || info.Status != provider.Running.String() {
+				pending = name
+				break
+			}
+		}
+		if pending == "" {
+			return nil
+		}
+		m.logger.Debugf("Container '%s' is not running yet, waiting...", pending)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(DefaultContainerPollInterval):
+		}
+	}
+
+	return fmt.Errorf("timeout waiting for %s to reach running state", strings.Join(names, ", "))
+}
@@ -0,0 +1,146 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider/fake"
+)
+
+// concurrencyProvider records the most CreateContainer calls in flight at once
+type concurrencyProvider struct {
+	*fake.Provider
+	mu       sync.Mutex
+	inFlight int
+	max      int
+}
+
+func (p *concurrencyProvider) CreateContainer(ctx context.Context, cfg config.Node) (string, error) {
+	p.mu.Lock()
+	p.inFlight++
+	p.max = max(p.max, p.inFlight)
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		p.inFlight--
+		p.mu.Unlock()
+	}()
+	return p.Provider.CreateContainer(ctx, cfg)
+}
+
+func TestNodeTier(t *testing.T) {
+	tests := []struct {
+		node config.Node
+		want int
+	}{
+		{config.Node{Kind: config.ConsulNode, Role: config.Server}, consulServerTier},
+		{config.Node{Kind: config.NomadNode, Role: config.Server}, serverTier},
+		{config.Node{Kind: config.VaultNode, Role: config.Server}, serverTier},
+		{config.Node{Kind: config.NomadNode, Role: config.Client}, clientTier},
+	}
+	for _, tt := range tests {
+		if got := nodeTier(tt.node); got != tt.want {
+			t.Errorf("nodeTier(%s %s) = %d, want %d", tt.node.Kind, tt.node.Role, got, tt.want)
+		}
+	}
+}
+
+func TestExecuteReconcilePlan_TierOrder(t *testing.T) {
+	p := fake.New()
+	topology := DefaultTopology()
+	topology.ConsulServers = 3
+	topology.NomadClients = 4
+	m := newFakeManager(t, "dev", p, WithTopology(topology))
+
+	if _, err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// Every container of a tier is created before any of the next tier
+	lastTier := consulServerTier
+	for _, call := range p.CallsTo(fake.MethodCreateContainer) {
+		node := m.findNodeConfigByName(call.Name)
+		if node == nil {
+			t.Fatalf("created unknown container %s", call.Name)
+		}
+		tier := nodeTier(*node)
+		if tier < lastTier {
+			t.Errorf("created %s (tier %d) after a tier %d container", call.Name, tier, lastTier)
+		}
+		lastTier = tier
+	}
+
+	// The network is created before any container
+	calls := p.Calls()
+	for _, call := range calls {
+		if call.Method == fake.MethodCreateContainer {
+			t.Fatal("container created before the network")
+		}
+		if call.Method == fake.MethodCreateNetwork {
+			break
+		}
+	}
+}
+
+func TestExecuteReconcilePlan_Concurrency(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency int
+	}{
+		{"sequential", 1},
+		{"limited", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &concurrencyProvider{Provider: fake.New(fake.WithLatency(10 * time.Millisecond))}
+			topology := DefaultTopology()
+			topology.NomadClients = 8
+
+			m := newFakeManager(t, "dev", p.Provider, WithTopology(topology), WithConcurrency(tt.concurrency))
+			m.provider = p
+
+			if _, err := m.Start(context.Background()); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			if p.max != tt.concurrency {
+				t.Errorf("max concurrent creates = %d, want %d", p.max, tt.concurrency)
+			}
+		})
+	}
+}
+
+func TestExecuteReconcilePlan_StopsAtFailedTier(t *testing.T) {
+	p := fake.New()
+	m := newFakeManager(t, "dev", p)
+
+	var consul string
+	for _, node := range m.Config().Nodes {
+		if nodeTier(node) == consulServerTier {
+			consul = node.Name
+		}
+	}
+	p.Fail(fake.Failure{Method: fake.MethodCreateContainer, Name: consul})
+
+	_, err := m.Start(context.Background())
+	if !errors.Is(err, fake.ErrInjected) {
+		t.Fatalf("Start() error = %v, want injected failure", err)
+	}
+	for _, call := range p.CallsTo(fake.MethodCreateContainer) {
+		if call.Name != consul {
+			t.Errorf("created %s after the consul tier failed", call.Name)
+		}
+	}
+}
+
+func TestNew_Concurrency(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if _, err := New(nil, "dev", WithConcurrency(0), WithProvider(fake.New())); err == nil {
+		t.Error("New() with concurrency 0 error = nil, want error")
+	}
+}
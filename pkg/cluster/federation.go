@@ -0,0 +1,349 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/stenh0use/hind/pkg/config"
+)
+
+// Commands run on the Consul servers of federated clusters
+var (
+	// consulJoinWANCmd joins the servers at the addresses in SERVERS over
+	// the WAN
+	consulJoinWANCmd = []string{"sh", "-c", `consul join -wan $SERVERS >/dev/null`}
+	// consulForceLeaveWANCmd removes the WAN member NODE of a deleted cluster
+	consulForceLeaveWANCmd = []string{"sh", "-c", `consul force-leave -prune -wan "$NODE" >/dev/null`}
+	// consulBindSubnetCmd makes the server bind to its address on SUBNET
+	// when it restarts. Attached to the network of a peer, the private
+	// address Consul picks by default could be the one on that network.
+	consulBindSubnetCmd = []string{"sh", "-c", `cat > /etc/consul.d/federation.hcl <<EOF
+bind_addr = "{{ GetPrivateInterfaces | include \"network\" \"$SUBNET\" | attr \"address\" }}"
+EOF`}
+)
+
+// validateFederation checks the clusters a cluster config is federated with
+func validateFederation(cfg *config.Cluster) error {
+	if len(cfg.Federation) == 0 {
+		return nil
+	}
+	if err := federationSupported(cfg); err != nil {
+		return err
+	}
+
+	seen := map[string]bool{}
+	for _, name := range cfg.Federation {
+		switch {
+		case name == "":
+			return fmt.Errorf("federation of cluster '%s' has an empty cluster name", cfg.Name)
+		case name == cfg.Name:
+			return fmt.Errorf("cluster '%s' can't be federated with itself", cfg.Name)
+		case seen[name]:
+			return fmt.Errorf("cluster '%s' is federated with '%s' twice", cfg.Name, name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// federationSupported returns an error for clusters that can't join a
+// federation: each cluster bootstraps its own ACLs and generates its own CA
+func federationSupported(cfg *config.Cluster) error {
+	if cfg.ACL || cfg.TLS {
+		return fmt.Errorf("cluster '%s' has ACLs or TLS enabled, only clusters without them can be federated", cfg.Name)
+	}
+	return nil
+}
+
+// Federate joins the Consul servers of the cluster with the ones of a peer
+// cluster over the WAN. The servers of each cluster are attached to the
+// network of the other, the peer switches to the Consul gossip key of the
+// cluster, and both configs record the federation so that it is restored
+// when either cluster is started again.
+func (m *Manager) Federate(ctx context.Context, peerName string) error {
+	peer, err := m.loadPeer(peerName)
+	if err != nil {
+		return err
+	}
+	cfg := *m.config
+	if !slices.Contains(cfg.Federation, peerName) {
+		cfg.Federation = append(slices.Clone(cfg.Federation), peerName)
+	}
+	if err := validateFederation(&cfg); err != nil {
+		return err
+	}
+
+	if err := m.federate(ctx, peer); err != nil {
+		return err
+	}
+	m.config.Federation = cfg.Federation
+	return m.saveConfig()
+}
+
+// loadPeer loads a cluster to federate with, it has to run on the same
+// provider to share networks
+func (m *Manager) loadPeer(name string) (*Manager, error) {
+	peer, err := New(m.logger, name, WithProvider(m.provider), WithReadinessChecks(false))
+	if err != nil {
+		return nil, err
+	}
+	existed, err := peer.Load()
+	if err != nil {
+		return nil, err
+	}
+	if !existed {
+		return nil, fmt.Errorf("cluster '%s' not found", name)
+	}
+	if peer.config.Provider != m.config.Provider {
+		return nil, fmt.Errorf("cluster '%s' runs on %s and '%s' on %s, federated clusters need the same provider",
+			m.config.Name, m.config.Provider, name, peer.config.Provider)
+	}
+	return peer, nil
+}
+
+// federate joins the Consul servers of the cluster and of a running peer
+func (m *Manager) federate(ctx context.Context, peer *Manager) error {
+	if err := federationSupported(peer.config); err != nil {
+		return err
+	}
+	if dc := Datacenter(m.config); dc == Datacenter(peer.config) {
+		return fmt.Errorf("clusters '%s' and '%s' are both in datacenter '%s', federated clusters need their own",
+			m.config.Name, peer.config.Name, dc)
+	}
+
+	servers := m.nodesOf(config.ConsulNode, config.Server)
+	peerServers := peer.nodesOf(config.ConsulNode, config.Server)
+	if len(servers) == 0 || len(peerServers) == 0 {
+		return fmt.Errorf("clusters '%s' and '%s' both need a Consul server to be federated", m.config.Name, peer.config.Name)
+	}
+	if err := m.checkRunning(ctx, servers[0]); err != nil {
+		return err
+	}
+	if err := peer.checkRunning(ctx, peerServers[0]); err != nil {
+		return err
+	}
+
+	// The servers of a WAN pool share the gossip key
+	if err := m.shareGossipKey(ctx, peer); err != nil {
+		return err
+	}
+
+	if err := m.connectServers(ctx, servers, peer.config.Network.Name); err != nil {
+		return err
+	}
+	if err := peer.connectServers(ctx, peerServers, m.config.Network.Name); err != nil {
+		return err
+	}
+
+	addresses, err := peer.serverAddresses(ctx, peerServers)
+	if err != nil {
+		return err
+	}
+	env := m.AgentEnvironment(servers[0])
+	env["SERVERS"] = strings.Join(addresses, " ")
+	if err := m.nodeExec(ctx, servers[0], consulJoinWANCmd, env, io.Discard); err != nil {
+		return fmt.Errorf("failed to join the Consul servers of '%s' over the WAN: %w", peer.config.Name, err)
+	}
+
+	if !slices.Contains(peer.config.Federation, m.config.Name) {
+		peer.config.Federation = append(slices.Clone(peer.config.Federation), m.config.Name)
+		if err := peer.saveConfig(); err != nil {
+			return err
+		}
+	}
+	m.logger.Infof("Federated datacenter '%s' of cluster '%s' with datacenter '%s' of cluster '%s'",
+		Datacenter(m.config), m.config.Name, Datacenter(peer.config), peer.config.Name)
+	return nil
+}
+
+// shareGossipKey makes the peer use the Consul gossip key of the cluster
+func (m *Manager) shareGossipKey(ctx context.Context, peer *Manager) error {
+	keys, err := m.GossipKeys()
+	if err != nil && !errors.Is(err, ErrNoGossipKeys) {
+		return err
+	}
+	peerKeys, err := peer.GossipKeys()
+	if err != nil && !errors.Is(err, ErrNoGossipKeys) {
+		return err
+	}
+
+	switch {
+	case keys == nil && peerKeys == nil:
+		return nil
+	case keys == nil || peerKeys == nil:
+		return fmt.Errorf("only one of clusters '%s' and '%s' encrypts its gossip, recreate the other to federate them",
+			m.config.Name, peer.config.Name)
+	case keys.Consul == peerKeys.Consul:
+		return nil
+	}
+
+	server := peer.nodesOf(config.ConsulNode, config.Server)[0]
+	oldKey := peerKeys.Consul
+	if err := peer.keyringOp(ctx, server, consulKeyringCmd, "install", keys.Consul); err != nil {
+		return err
+	}
+	if err := peer.keyringOp(ctx, server, consulKeyringCmd, "use", keys.Consul); err != nil {
+		return err
+	}
+	peerKeys.Consul = keys.Consul
+	if err := peer.writeSecrets(GossipKeysFile, peerKeys); err != nil {
+		return err
+	}
+	if err := peer.keyringOp(ctx, server, consulKeyringCmd, "remove", oldKey); err != nil {
+		return err
+	}
+	m.logger.Infof("Cluster '%s' switched to the Consul gossip key of '%s'", peer.config.Name, m.config.Name)
+	return nil
+}
+
+// connectServers attaches the Consul servers of the cluster to a network,
+// after pinning their bind address to their own network
+func (m *Manager) connectServers(ctx context.Context, servers []config.Node, network string) error {
+	own, err := m.provider.InspectNetwork(ctx, m.config.Network.Name)
+	if err != nil {
+		return fmt.Errorf("failed to inspect network '%s': %w", m.config.Network.Name, err)
+	}
+	if own == nil || own.Subnet == "" {
+		return fmt.Errorf("network '%s' has no subnet", m.config.Network.Name)
+	}
+
+	for _, s := range servers {
+		info, err := m.provider.InspectContainer(ctx, s.Name)
+		if err != nil {
+			return fmt.Errorf("failed to inspect node '%s': %w", s.Name, err)
+		}
+		if info == nil {
+			return fmt.Errorf("node '%s' not found", s.Name)
+		}
+
+		env := map[string]string{"SUBNET": own.Subnet}
+		if err := m.nodeExec(ctx, s, consulBindSubnetCmd, env, io.Discard); err != nil {
+			return fmt.Errorf("failed to set the bind address of node '%s': %w", s.Name, err)
+		}
+		if _, ok := info.Addresses[network]; ok {
+			continue
+		}
+		if err := m.provider.ConnectNetwork(ctx, network, s.Name); err != nil {
+			return fmt.Errorf("failed to attach node '%s' to network '%s': %w", s.Name, network, err)
+		}
+		m.logger.WithField("name", s.Name).Debugf("attached node to network '%s'", network)
+	}
+	return nil
+}
+
+// serverAddresses returns the addresses of servers on the cluster network
+func (m *Manager) serverAddresses(ctx context.Context, servers []config.Node) ([]string, error) {
+	var addresses []string
+	for _, s := range servers {
+		info, err := m.provider.InspectContainer(ctx, s.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect node '%s': %w", s.Name, err)
+		}
+		if info == nil || info.Addresses[m.config.Network.Name] == "" {
+			return nil, fmt.Errorf("node '%s' has no address on network '%s'", s.Name, m.config.Network.Name)
+		}
+		addresses = append(addresses, info.Addresses[m.config.Network.Name])
+	}
+	return addresses, nil
+}
+
+// rejoinFederation restores the federation with the clusters the config
+// lists: recreated servers lose their attachment to the peer networks and
+// restarted ones may have new addresses. Peers that aren't running are
+// skipped, they rejoin when they are started.
+func (m *Manager) rejoinFederation(ctx context.Context) {
+	for _, name := range m.config.Federation {
+		peer, err := m.loadPeer(name)
+		if err != nil {
+			m.logger.Warnf("Skipping federated cluster '%s': %v", name, err)
+			continue
+		}
+		if !peer.consulRunning(ctx) {
+			m.logger.Infof("Federated cluster '%s' is not running, it rejoins when started", name)
+			continue
+		}
+		if err := m.federate(ctx, peer); err != nil {
+			m.logger.Warnf("Failed to rejoin federated cluster '%s', run 'hind federate %s %s' once it is ready: %v",
+				name, m.config.Name, name, err)
+		}
+	}
+}
+
+// consulRunning reports whether the first Consul server of the cluster is
+// running
+func (m *Manager) consulRunning(ctx context.Context) bool {
+	servers := m.nodesOf(config.ConsulNode, config.Server)
+	return len(servers) > 0 && m.checkRunning(ctx, servers[0]) == nil
+}
+
+// federatedPeers loads the clusters the cluster is federated with, they
+// have to be running
+func (m *Manager) federatedPeers(ctx context.Context) ([]*Manager, error) {
+	var peers []*Manager
+	for _, name := range m.config.Federation {
+		peer, err := m.loadPeer(name)
+		if err != nil {
+			return nil, err
+		}
+		if !peer.consulRunning(ctx) {
+			return nil, fmt.Errorf("federated cluster '%s' is not running", name)
+		}
+		peers = append(peers, peer)
+	}
+	return peers, nil
+}
+
+// leaveFederation detaches the servers of the federated clusters from the
+// network of the cluster, so that it can be deleted, and removes the
+// cluster from their WAN pool and their configs. Run once the containers
+// of the cluster are deleted.
+func (m *Manager) leaveFederation(ctx context.Context) error {
+	for _, name := range m.config.Federation {
+		peer, err := m.loadPeer(name)
+		if err != nil {
+			m.logger.Warnf("Skipping federated cluster '%s': %v", name, err)
+			continue
+		}
+
+		peerServers := peer.nodesOf(config.ConsulNode, config.Server)
+		for _, s := range peerServers {
+			info, err := peer.provider.InspectContainer(ctx, s.Name)
+			if err != nil {
+				return fmt.Errorf("failed to inspect node '%s': %w", s.Name, err)
+			}
+			if info == nil {
+				continue
+			}
+			if _, ok := info.Addresses[m.config.Network.Name]; !ok {
+				continue
+			}
+			if err := peer.provider.DisconnectNetwork(ctx, m.config.Network.Name, s.Name); err != nil {
+				return fmt.Errorf("failed to detach node '%s' from network '%s': %w", s.Name, m.config.Network.Name, err)
+			}
+		}
+
+		// WAN members are named after the node and its datacenter
+		if peer.consulRunning(ctx) {
+			for _, s := range m.nodesOf(config.ConsulNode, config.Server) {
+				env := peer.AgentEnvironment(peerServers[0])
+				env["NODE"] = s.Name + "." + Datacenter(m.config)
+				if err := peer.nodeExec(ctx, peerServers[0], consulForceLeaveWANCmd, env, io.Discard); err != nil {
+					m.logger.Warnf("Failed to remove node '%s' from the WAN pool of '%s': %v", s.Name, name, err)
+				}
+			}
+		}
+
+		peer.config.Federation = slices.DeleteFunc(slices.Clone(peer.config.Federation), func(n string) bool {
+			return n == m.config.Name
+		})
+		if err := peer.saveConfig(); err != nil {
+			return err
+		}
+		m.logger.WithField("name", name).Info("left federated cluster")
+	}
+	return nil
+}
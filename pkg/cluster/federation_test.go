@@ -0,0 +1,290 @@
+package cluster
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+	"github.com/stenh0use/hind/pkg/provider/fake"
+)
+
+// fakeWAN records the commands run on the Consul servers of federated
+// clusters
+type fakeWAN struct {
+	mu     sync.Mutex
+	joins  map[string]string
+	binds  map[string]string
+	leaves []string
+	keys   []string
+}
+
+func newFakeWAN() *fakeWAN {
+	return &fakeWAN{joins: map[string]string{}, binds: map[string]string{}}
+}
+
+func (f *fakeWAN) exec(_ context.Context, name string, opts provider.ExecOptions) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case slices.Equal(opts.Cmd, consulJoinWANCmd):
+		f.joins[name] = opts.Env["SERVERS"]
+	case slices.Equal(opts.Cmd, consulBindSubnetCmd):
+		f.binds[name] = opts.Env["SUBNET"]
+	case slices.Equal(opts.Cmd, consulForceLeaveWANCmd):
+		f.leaves = append(f.leaves, name+" "+opts.Env["NODE"])
+	case slices.Equal(opts.Cmd, consulKeyringCmd):
+		f.keys = append(f.keys, name+" "+opts.Env["OP"])
+	}
+	return nil
+}
+
+// newFakePeers starts two clusters sharing a provider and a config directory,
+// in the datacenters dc1 and dc2
+func newFakePeers(t *testing.T, p *fake.Provider, opts ...Option) (*Manager, *Manager) {
+	t.Helper()
+	a := newFakeManager(t, "a", p, append(opts, WithLocation("dc1", ""))...)
+
+	logger := &log.Logger{Handler: discard.New(), Level: log.ErrorLevel}
+	b, err := New(logger, "b", append(opts, WithLocation("dc2", ""), WithProvider(p), WithReadinessChecks(false), withSetup(false))...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for _, m := range []*Manager{a, b} {
+		if _, err := m.Start(context.Background()); err != nil {
+			t.Fatalf("Start(%s) error = %v", m.config.Name, err)
+		}
+	}
+	return a, b
+}
+
+// connected reports whether a node is attached to a network
+func connected(t *testing.T, p *fake.Provider, node, network string) bool {
+	t.Helper()
+	info, err := p.InspectContainer(context.Background(), node)
+	if err != nil || info == nil {
+		t.Fatalf("InspectContainer(%s) = %v, %v", node, info, err)
+	}
+	_, ok := info.Addresses[network]
+	return ok
+}
+
+func TestManager_Federate(t *testing.T) {
+	wan := newFakeWAN()
+	p := fake.New(fake.WithExec(wan.exec))
+	a, b := newFakePeers(t, p)
+	ctx := context.Background()
+
+	if err := a.Federate(ctx, "b"); err != nil {
+		t.Fatalf("Federate() error = %v", err)
+	}
+
+	// The servers of each cluster are attached to the network of the other
+	// and bind to their own network on restarts
+	if !connected(t, p, "hind.a.consul.01", "hind.b") || !connected(t, p, "hind.b.consul.01", "hind.a") {
+		t.Errorf("Consul servers are not attached to the network of the peer")
+	}
+	if connected(t, p, "hind.a.client.01", "hind.b") {
+		t.Errorf("client is attached to the network of the peer")
+	}
+	aNet, _ := p.InspectNetwork(ctx, "hind.a")
+	if wan.binds["hind.a.consul.01"] != aNet.Subnet {
+		t.Errorf("bind subnet of hind.a.consul.01 = %q, want %q", wan.binds["hind.a.consul.01"], aNet.Subnet)
+	}
+
+	info, _ := p.InspectContainer(ctx, "hind.b.consul.01")
+	if got, want := wan.joins["hind.a.consul.01"], info.Addresses["hind.b"]; got != want {
+		t.Errorf("WAN join servers = %q, want %q", got, want)
+	}
+
+	// b switches to the Consul gossip key of a
+	aKeys, _ := a.GossipKeys()
+	bKeys, _ := b.GossipKeys()
+	if bKeys.Consul != aKeys.Consul || bKeys.Nomad == aKeys.Nomad {
+		t.Errorf("gossip keys of b = %+v, want the Consul key of a only", *bKeys)
+	}
+	wantKeys := []string{"hind.b.consul.01 install", "hind.b.consul.01 use", "hind.b.consul.01 remove"}
+	if !slices.Equal(wan.keys, wantKeys) {
+		t.Errorf("keyring operations = %q, want %q", wan.keys, wantKeys)
+	}
+
+	// Both configs record the federation
+	for _, m := range []*Manager{a, b} {
+		cfg, err := m.loadConfig()
+		if err != nil {
+			t.Fatalf("loadConfig() error = %v", err)
+		}
+		want := []string{"b"}
+		if m == b {
+			want = []string{"a"}
+		}
+		if !slices.Equal(cfg.Federation, want) {
+			t.Errorf("federation of %s = %v, want %v", cfg.Name, cfg.Federation, want)
+		}
+	}
+
+	// Rotating the key of a rotates it across the WAN pool, b stores it too
+	if err := a.RotateGossipKeys(ctx); err != nil {
+		t.Fatalf("RotateGossipKeys() error = %v", err)
+	}
+	aKeys, _ = a.GossipKeys()
+	if bKeys, _ := b.GossipKeys(); bKeys.Consul != aKeys.Consul {
+		t.Errorf("Consul gossip key of b = %q after rotation, want %q", bKeys.Consul, aKeys.Consul)
+	}
+	wan.keys = nil
+
+	// Federating again changes nothing
+	if err := a.Federate(ctx, "b"); err != nil {
+		t.Fatalf("Federate() again error = %v", err)
+	}
+	if len(wan.keys) != 0 || len(a.config.Federation) != 1 {
+		t.Errorf("Federate() again changed keys %q or federation %v", wan.keys, a.config.Federation)
+	}
+}
+
+func TestManager_Federate_Rejoin(t *testing.T) {
+	wan := newFakeWAN()
+	p := fake.New(fake.WithExec(wan.exec))
+	a, _ := newFakePeers(t, p)
+	ctx := context.Background()
+	if err := a.Federate(ctx, "b"); err != nil {
+		t.Fatalf("Federate() error = %v", err)
+	}
+
+	// A recreated server is attached again and rejoins
+	if err := p.StopContainer(ctx, "hind.a.consul.01"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.DeleteContainer(ctx, "hind.a.consul.01"); err != nil {
+		t.Fatal(err)
+	}
+	delete(wan.joins, "hind.a.consul.01")
+	if _, err := a.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if !connected(t, p, "hind.a.consul.01", "hind.b") {
+		t.Errorf("recreated server is not attached to the network of the peer")
+	}
+	if wan.joins["hind.a.consul.01"] == "" {
+		t.Errorf("recreated server did not rejoin the WAN")
+	}
+}
+
+func TestManager_Federate_Delete(t *testing.T) {
+	wan := newFakeWAN()
+	p := fake.New(fake.WithExec(wan.exec))
+	a, b := newFakePeers(t, p)
+	ctx := context.Background()
+	if err := a.Federate(ctx, "b"); err != nil {
+		t.Fatalf("Federate() error = %v", err)
+	}
+
+	if err := a.Delete(ctx, DeleteOptions{}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if n, _ := p.InspectNetwork(ctx, "hind.a"); n != nil {
+		t.Errorf("network hind.a was not deleted")
+	}
+	if connected(t, p, "hind.b.consul.01", "hind.a") {
+		t.Errorf("hind.b.consul.01 is still attached to hind.a")
+	}
+	if want := []string{"hind.b.consul.01 hind.a.consul.01.dc1"}; !slices.Equal(wan.leaves, want) {
+		t.Errorf("WAN force leaves = %q, want %q", wan.leaves, want)
+	}
+	if cfg, _ := b.loadConfig(); len(cfg.Federation) != 0 {
+		t.Errorf("federation of b = %v, want none", cfg.Federation)
+	}
+}
+
+func TestManager_Federate_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		peer    string
+		opts    []Option
+		setup   func(t *testing.T, a, b *Manager)
+		wantErr string
+	}{
+		{
+			name:    "unknown peer",
+			peer:    "c",
+			wantErr: "cluster 'c' not found",
+		},
+		{
+			name:    "itself",
+			peer:    "a",
+			wantErr: "federated with itself",
+		},
+		{
+			name:    "same datacenter",
+			peer:    "b",
+			setup:   func(t *testing.T, a, b *Manager) { b.config.Datacenter = "dc1" },
+			wantErr: "both in datacenter 'dc1'",
+		},
+		{
+			name:    "acl",
+			peer:    "b",
+			opts:    []Option{WithACL(true)},
+			wantErr: "ACLs or TLS enabled",
+		},
+		{
+			name: "peer stopped",
+			peer: "b",
+			setup: func(t *testing.T, a, b *Manager) {
+				if err := b.Stop(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: "is not running",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := newFakePeers(t, fake.New(), tt.opts...)
+			if tt.setup != nil {
+				tt.setup(t, a, b)
+				if err := b.saveConfig(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := a.Federate(context.Background(), tt.peer)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Federate(%s) error = %v, want %s", tt.peer, err, tt.wantErr)
+			}
+			if len(a.config.Federation) != 0 {
+				t.Errorf("federation of a = %v after error, want none", a.config.Federation)
+			}
+		})
+	}
+}
+
+func TestValidateFederation(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.Cluster
+		wantErr bool
+	}{
+		{"none", config.Cluster{Name: "a", ACL: true}, false},
+		{"peers", config.Cluster{Name: "a", Federation: []string{"b", "c"}}, false},
+		{"itself", config.Cluster{Name: "a", Federation: []string{"a"}}, true},
+		{"twice", config.Cluster{Name: "a", Federation: []string{"b", "b"}}, true},
+		{"empty name", config.Cluster{Name: "a", Federation: []string{""}}, true},
+		{"tls", config.Cluster{Name: "a", TLS: true, Federation: []string{"b"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateFederation(&tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("validateFederation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package cluster
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+// GossipKeysFile is the file in the cluster directory holding the gossip
+// encryption keys, readable by the owner only
+const GossipKeysFile = "gossip.json"
+
+// ErrNoGossipKeys is returned for clusters created without gossip
+// encryption
+var ErrNoGossipKeys = errors.New("no gossip keys")
+
+// gossipKeySize is the size of the keys in bytes, Nomad requires 32
+const gossipKeySize = 32
+
+// GossipKeys are the primary keys encrypting the gossip of the Consul
+// agents and of the Nomad servers
+type GossipKeys struct {
+	Consul string `json:"consulKey"`
+	Nomad  string `json:"nomadKey"`
+}
+
+// The variables the keys are passed to the entrypoints in, set when the
+// containers are created
+const (
+	consulGossipKeyEnv = "CONSUL_GOSSIP_KEY"
+	nomadGossipKeyEnv  = "NOMAD_GOSSIP_KEY"
+)
+
+var gossipKeyEnvironment = []string{consulGossipKeyEnv, nomadGossipKeyEnv}
+
+// consulKeyringCmd and nomadKeyringCmd run the keyring operation OP with
+// KEY across the agents of the cluster
+var (
+	consulKeyringCmd = []string{"sh", "-c", `consul keyring -"$OP" "$KEY" >/dev/null`}
+	nomadKeyringCmd  = []string{"sh", "-c", `nomad operator gossip keyring "$OP" "$KEY" >/dev/null`}
+)
+
+// GossipKeys returns the stored gossip keys of the cluster, or
+// ErrNoGossipKeys if it was created without them
+func (m *Manager) GossipKeys() (*GossipKeys, error) {
+	var keys GossipKeys
+	found, err := m.readSecrets(GossipKeysFile, &keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gossip keys: %w", err)
+	}
+	if !found {
+		return nil, ErrNoGossipKeys
+	}
+	return &keys, nil
+}
+
+// ensureGossipKeys generates the gossip keys of a new cluster, keeping the
+// ones of a cluster directory that was left behind
+func (m *Manager) ensureGossipKeys() error {
+	if _, err := m.GossipKeys(); !errors.Is(err, ErrNoGossipKeys) {
+		return err
+	}
+
+	var keys GossipKeys
+	var err error
+	if keys.Consul, err = newGossipKey(); err != nil {
+		return err
+	}
+	if keys.Nomad, err = newGossipKey(); err != nil {
+		return err
+	}
+	if err := m.writeSecrets(GossipKeysFile, &keys); err != nil {
+		return err
+	}
+	m.logger.Debug("Generated gossip keys")
+	return nil
+}
+
+// newGossipKey returns a random base64 encoded key
+func newGossipKey() (string, error) {
+	key := make([]byte, gossipKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate gossip key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// containerEnvironment returns the environment of a node's container: the
+// one of its config and the gossip keys. The keys aren't part of the config,
+// rotating them must not recreate the containers. The agents keep their
+// keyring in their data directory, the keys only apply to new containers.
+func (m *Manager) containerEnvironment(node config.Node) (map[string]string, error) {
+	keys, err := m.GossipKeys()
+	if errors.Is(err, ErrNoGossipKeys) {
+		return node.Environment, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	env := maps.Clone(node.Environment)
+	if env == nil {
+		env = map[string]string{}
+	}
+	env[consulGossipKeyEnv] = keys.Consul
+	if node.Kind == config.NomadNode && node.Role == config.Server {
+		env[nomadGossipKeyEnv] = keys.Nomad
+	}
+	return env, nil
+}
+
+// RotateGossipKeys replaces the gossip keys of the running cluster: a new
+// key is installed in every agent, made the primary key, and the old key
+// removed. The stored key is replaced once the new one is in use, so nodes
+// created afterwards join with it.
+func (m *Manager) RotateGossipKeys(ctx context.Context) error {
+	keys, err := m.GossipKeys()
+	if errors.Is(err, ErrNoGossipKeys) {
+		return fmt.Errorf("cluster '%s' was created without gossip encryption, recreate it to enable it", m.config.Name)
+	}
+	if err != nil {
+		return err
+	}
+	// Consul rotates the key across the WAN pool, the federated clusters
+	// store the new key too
+	peers, err := m.federatedPeers(ctx)
+	if err != nil {
+		return err
+	}
+
+	rotations := []struct {
+		kind config.Kind
+		cmd  []string
+		key  *string
+	}{
+		{config.ConsulNode, consulKeyringCmd, &keys.Consul},
+		{config.NomadNode, nomadKeyringCmd, &keys.Nomad},
+	}
+	for _, r := range rotations {
+		servers := m.nodesOf(r.kind, config.Server)
+		if len(servers) == 0 {
+			continue
+		}
+		if err := m.checkRunning(ctx, servers[0]); err != nil {
+			return err
+		}
+
+		newKey, err := newGossipKey()
+		if err != nil {
+			return err
+		}
+		oldKey := *r.key
+		if err := m.keyringOp(ctx, servers[0], r.cmd, "install", newKey); err != nil {
+			return err
+		}
+		if err := m.keyringOp(ctx, servers[0], r.cmd, "use", newKey); err != nil {
+			return err
+		}
+		*r.key = newKey
+		if err := m.writeSecrets(GossipKeysFile, keys); err != nil {
+			return err
+		}
+		if r.kind == config.ConsulNode {
+			for _, peer := range peers {
+				if err := peer.storeConsulGossipKey(newKey); err != nil {
+					return err
+				}
+			}
+		}
+		if err := m.keyringOp(ctx, servers[0], r.cmd, "remove", oldKey); err != nil {
+			return err
+		}
+		m.logger.Infof("Rotated the %s gossip key", r.kind)
+	}
+	return nil
+}
+
+// storeConsulGossipKey replaces the stored Consul gossip key of the cluster
+func (m *Manager) storeConsulGossipKey(key string) error {
+	keys, err := m.GossipKeys()
+	if err != nil {
+		return err
+	}
+	keys.Consul = key
+	return m.writeSecrets(GossipKeysFile, keys)
+}
+
+// keyringOp runs a keyring operation on a server, with the environment of
+// its CLIs
+func (m *Manager) keyringOp(ctx context.Context, node config.Node, cmd []string, op, key string) error {
+	env := m.AgentEnvironment(node)
+	env["OP"] = op
+	env["KEY"] = key
+	if err := m.nodeExec(ctx, node, cmd, env, io.Discard); err != nil {
+		return fmt.Errorf("failed to %s the %s gossip key: %w", op, node.Kind, err)
+	}
+	return nil
+}
+
+// checkRunning returns an error if the container of a node isn't running
+func (m *Manager) checkRunning(ctx context.Context, node config.Node) error {
+	info, err := m.provider.InspectContainer(ctx, node.Name)
+	if err != nil {
+		return fmt.Errorf("failed to inspect node '%s': %w", node.Name, err)
+	}
+	if info == nil || info.Status != provider.Running.String() {
+		return fmt.Errorf("node '%s' is not running", node.Name)
+	}
+	return nil
+}
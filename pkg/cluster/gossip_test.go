@@ -0,0 +1,124 @@
+package cluster
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+	"github.com/stenh0use/hind/pkg/provider/fake"
+)
+
+func TestManager_GossipKeys(t *testing.T) {
+	p := fake.New()
+	m := newFakeManager(t, "dev", p)
+	ctx := context.Background()
+	if _, err := m.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	keys, err := m.GossipKeys()
+	if err != nil {
+		t.Fatalf("GossipKeys() error = %v", err)
+	}
+	for _, key := range []string{keys.Consul, keys.Nomad} {
+		if raw, err := base64.StdEncoding.DecodeString(key); err != nil || len(raw) != gossipKeySize {
+			t.Errorf("gossip key %q is not %d bytes base64 encoded", key, gossipKeySize)
+		}
+	}
+	if keys.Consul == keys.Nomad {
+		t.Errorf("GossipKeys() = %+v, want different keys", keys)
+	}
+	if info, err := os.Stat(m.clusterFile(GossipKeysFile)); err != nil || info.Mode().Perm() != secretPermissions {
+		t.Errorf("%s = %v, %v, want permissions %o", GossipKeysFile, info, err, secretPermissions)
+	}
+
+	// The containers get the keys, the config doesn't keep them
+	for _, n := range m.config.Nodes {
+		info, err := p.InspectContainer(ctx, n.Name)
+		if err != nil || info == nil {
+			t.Fatalf("InspectContainer(%s) = %v, %v", n.Name, info, err)
+		}
+		if got := info.Spec.Environment[consulGossipKeyEnv]; got != keys.Consul {
+			t.Errorf("%s %s = %q, want %q", n.Name, consulGossipKeyEnv, got, keys.Consul)
+		}
+		want := ""
+		if n.Kind == config.NomadNode && n.Role == config.Server {
+			want = keys.Nomad
+		}
+		if got := info.Spec.Environment[nomadGossipKeyEnv]; got != want {
+			t.Errorf("%s %s = %q, want %q", n.Name, nomadGossipKeyEnv, got, want)
+		}
+		if _, ok := n.Environment[consulGossipKeyEnv]; ok {
+			t.Errorf("%s config environment has the gossip key", n.Name)
+		}
+	}
+
+	// Starting again keeps the keys
+	if _, err := m.Start(ctx); err != nil {
+		t.Fatalf("Start() again error = %v", err)
+	}
+	if again, _ := m.GossipKeys(); *again != *keys {
+		t.Errorf("GossipKeys() after restart = %+v, want %+v", *again, *keys)
+	}
+}
+
+func TestManager_RotateGossipKeys(t *testing.T) {
+	var mu sync.Mutex
+	var ops []string
+	exec := func(_ context.Context, name string, opts provider.ExecOptions) error {
+		mu.Lock()
+		defer mu.Unlock()
+		cli := "consul"
+		if slices.Equal(opts.Cmd, nomadKeyringCmd) {
+			cli = "nomad"
+		}
+		ops = append(ops, strings.Join([]string{name, cli, opts.Env["OP"], opts.Env["KEY"]}, " "))
+		return nil
+	}
+	m := newFakeManager(t, "dev", fake.New(fake.WithExec(exec)))
+	ctx := context.Background()
+	if _, err := m.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	old, err := m.GossipKeys()
+	if err != nil {
+		t.Fatalf("GossipKeys() error = %v", err)
+	}
+
+	if err := m.RotateGossipKeys(ctx); err != nil {
+		t.Fatalf("RotateGossipKeys() error = %v", err)
+	}
+	keys, err := m.GossipKeys()
+	if err != nil {
+		t.Fatalf("GossipKeys() error = %v", err)
+	}
+	if keys.Consul == old.Consul || keys.Nomad == old.Nomad {
+		t.Errorf("GossipKeys() = %+v, want both keys replaced", keys)
+	}
+
+	want := []string{
+		"hind.dev.consul.01 consul install " + keys.Consul,
+		"hind.dev.consul.01 consul use " + keys.Consul,
+		"hind.dev.consul.01 consul remove " + old.Consul,
+		"hind.dev.nomad.01 nomad install " + keys.Nomad,
+		"hind.dev.nomad.01 nomad use " + keys.Nomad,
+		"hind.dev.nomad.01 nomad remove " + old.Nomad,
+	}
+	if !slices.Equal(ops, want) {
+		t.Errorf("keyring operations = %q, want %q", ops, want)
+	}
+}
+
+func TestManager_RotateGossipKeys_NoKeys(t *testing.T) {
+	m := newFakeManager(t, "dev", fake.New())
+	err := m.RotateGossipKeys(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "without gossip encryption") {
+		t.Errorf("RotateGossipKeys() error = %v, want without gossip encryption", err)
+	}
+}
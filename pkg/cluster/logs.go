@@ -0,0 +1,159 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+// LogsOptions selects the journal entries Logs prints
+type LogsOptions struct {
+	// Service is the systemd unit to read, eg. docker or cilium. By default
+	// each node shows the agent of its kind.
+	Service string
+	// Follow streams new entries until the context is done
+	Follow bool
+	// Since only shows entries newer than this, in any format journalctl
+	// accepts, eg. "10 min ago" or "2025-01-02 15:04"
+	Since string
+}
+
+// Logs prints the journal of a service on the nodes matching target, see
+// ResolveNodes. The lines of each node are prefixed with its short name
+// when more than one node matches. Nodes that aren't running are skipped
+// with a warning, unless target names a single node.
+func (m *Manager) Logs(ctx context.Context, target string, opts LogsOptions, stdout, stderr io.Writer) error {
+	nodes, err := m.ResolveNodes(target)
+	if err != nil {
+		return err
+	}
+
+	var running []config.Node
+	for _, n := range nodes {
+		info, err := m.provider.InspectContainer(ctx, n.Name)
+		if err != nil {
+			return fmt.Errorf("failed to inspect node '%s': %w", n.Name, err)
+		}
+		switch {
+		case info != nil && info.Status == provider.Running.String():
+			running = append(running, n)
+		case len(nodes) == 1:
+			return fmt.Errorf("node '%s' is not running", n.Name)
+		default:
+			m.logger.Warnf("Skipping node '%s', it is not running", n.Name)
+		}
+	}
+	if len(running) == 0 {
+		return fmt.Errorf("no node matching '%s' is running", target)
+	}
+
+	if len(running) == 1 {
+		return m.nodeLogs(ctx, running[0], opts, stdout, stderr)
+	}
+
+	width := 0
+	for _, n := range running {
+		width = max(width, len(m.ShortNodeName(n.Name)))
+	}
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs = make([]error, len(running))
+	)
+	for i, n := range running {
+		prefix := fmt.Sprintf("%-*s | ", width, m.ShortNodeName(n.Name))
+		out := &prefixWriter{mu: &mu, w: stdout, prefix: []byte(prefix)}
+		errOut := &prefixWriter{mu: &mu, w: stderr, prefix: []byte(prefix)}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := m.nodeLogs(ctx, n, opts, out, errOut)
+			out.Flush()
+			errOut.Flush()
+			if err != nil {
+				errs[i] = fmt.Errorf("node '%s': %w", n.Name, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// nodeLogs runs journalctl for the service of a node
+func (m *Manager) nodeLogs(ctx context.Context, node config.Node, opts LogsOptions, stdout, stderr io.Writer) error {
+	service := opts.Service
+	if service == "" {
+		service = node.Kind.String()
+	}
+
+	return m.provider.Exec(ctx, node.Name, provider.ExecOptions{
+		Cmd:    journalctlCmd(service, opts),
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+}
+
+// journalctlCmd returns the journalctl command printing the logs of a unit
+func journalctlCmd(service string, opts LogsOptions) []string {
+	cmd := []string{"journalctl", "--no-pager", "--unit", service}
+	if opts.Follow {
+		cmd = append(cmd, "--follow")
+	}
+	if opts.Since != "" {
+		cmd = append(cmd, "--since", opts.Since)
+	}
+	return cmd
+}
+
+// prefixWriter writes each complete line with a prefix. Writers sharing a
+// mutex don't interleave their lines.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix []byte
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+
+	start := 0
+	for {
+		i := bytes.IndexByte(p.buf[start:], '\n')
+		if i < 0 {
+			break
+		}
+		if err := p.writeLine(p.buf[start : start+i+1]); err != nil {
+			return 0, err
+		}
+		start += i + 1
+	}
+	p.buf = p.buf[:copy(p.buf, p.buf[start:])]
+	return len(b), nil
+}
+
+// Flush writes the last line if it doesn't end with a newline
+func (p *prefixWriter) Flush() error {
+	if len(p.buf) == 0 {
+		return nil
+	}
+	line := append(p.buf, '\n')
+	p.buf = nil
+	return p.writeLine(line)
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := p.w.Write(append(p.prefix[:len(p.prefix):len(p.prefix)], line...))
+	return err
+}
@@ -0,0 +1,124 @@
+package cluster
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stenh0use/hind/pkg/provider"
+	"github.com/stenh0use/hind/pkg/provider/fake"
+)
+
+func TestManager_ResolveNodes(t *testing.T) {
+	topology := DefaultTopology()
+	topology.NomadClients = 2
+	m := newFakeManager(t, "dev", fake.New(), WithTopology(topology))
+
+	tests := []struct {
+		target  string
+		want    []string
+		wantErr bool
+	}{
+		{target: "hind.dev.client.02", want: []string{"hind.dev.client.02"}},
+		{target: "client.02", want: []string{"hind.dev.client.02"}},
+		{target: "consul", want: []string{"hind.dev.consul.01"}},
+		{target: "client", want: []string{"hind.dev.client.01", "hind.dev.client.02"}},
+		{target: "nomad", want: []string{"hind.dev.nomad.01", "hind.dev.client.01", "hind.dev.client.02"}},
+		{target: "client.03", wantErr: true},
+		{target: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			nodes, err := m.ResolveNodes(tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveNodes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, n := range nodes {
+				got = append(got, n.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ResolveNodes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	all, err := m.ResolveNodes(AllNodes)
+	if err != nil || len(all) != len(m.config.Nodes) {
+		t.Errorf("ResolveNodes(all) = %d nodes, %v, want %d", len(all), err, len(m.config.Nodes))
+	}
+}
+
+func TestManager_Logs(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		cmds = map[string][]string{}
+	)
+	p := fake.New(fake.WithExec(func(_ context.Context, name string, opts provider.ExecOptions) error {
+		mu.Lock()
+		cmds[name] = opts.Cmd
+		mu.Unlock()
+		_, err := io.WriteString(opts.Stdout, "started\nlistening")
+		return err
+	}))
+	topology := DefaultTopology()
+	topology.NomadClients = 2
+	m := newFakeManager(t, "dev", p, WithTopology(topology))
+	if _, err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	t.Run("single node", func(t *testing.T) {
+		var out strings.Builder
+		opts := LogsOptions{Follow: true, Since: "1h ago"}
+		if err := m.Logs(context.Background(), "consul.01", opts, &out, io.Discard); err != nil {
+			t.Fatalf("Logs() error = %v", err)
+		}
+		if want := "journalctl --no-pager --unit consul --follow --since 1h ago"; strings.Join(cmds["hind.dev.consul.01"], " ") != want {
+			t.Errorf("Logs() ran %q, want %q", cmds["hind.dev.consul.01"], want)
+		}
+		if out.String() != "started\nlistening" {
+			t.Errorf("Logs() output = %q, want it unprefixed", out.String())
+		}
+	})
+
+	t.Run("role", func(t *testing.T) {
+		if err := p.SetStatus("hind.dev.client.02", provider.Stopped); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { p.SetStatus("hind.dev.client.02", provider.Running) })
+
+		var out strings.Builder
+		if err := m.Logs(context.Background(), "nomad", LogsOptions{Service: "docker"}, &out, io.Discard); err != nil {
+			t.Fatalf("Logs() error = %v", err)
+		}
+		if got := cmds["hind.dev.client.01"]; !slices.Contains(got, "docker") {
+			t.Errorf("Logs() ran %q, want the docker unit", got)
+		}
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		slices.Sort(lines)
+		want := []string{
+			"client.01 | listening",
+			"client.01 | started",
+			"nomad.01  | listening",
+			"nomad.01  | started",
+		}
+		if !slices.Equal(lines, want) {
+			t.Errorf("Logs() output = %q, want %q", lines, want)
+		}
+	})
+
+	t.Run("stopped node", func(t *testing.T) {
+		if err := p.SetStatus("hind.dev.vault.01", provider.Stopped); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.Logs(context.Background(), "vault.01", LogsOptions{}, io.Discard, io.Discard); err == nil {
+			t.Error("Logs() of a stopped node error = nil, want error")
+		}
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 	"time"
 
 	"github.com/apex/log"
@@ -11,13 +13,22 @@ import (
 	"github.com/stenh0use/hind/pkg/config"
 	"github.com/stenh0use/hind/pkg/file"
 	"github.com/stenh0use/hind/pkg/provider"
-	"github.com/stenh0use/hind/pkg/provider/dockercli"
 )
 
 // Manager handles cluster lifecycle operations.
 type Manager struct {
-	logger     *log.Logger
-	provider   provider.Client
+	logger   *log.Logger
+	provider provider.Client
+	// fixedProvider is set when the provider was given with WithProvider,
+	// it is kept whatever provider the cluster config names
+	fixedProvider bool
+	// concurrency is the number of containers changed in parallel
+	concurrency int
+	// readinessChecks waits for the services to be ready after reconciling
+	readinessChecks bool
+	// setup runs the cluster setup steps after reconciling, disabled in
+	// tests of providers without agents to set up
+	setup      bool
 	config     *config.Cluster
 	fm         *file.Manager
 	configFile string
@@ -28,18 +39,147 @@ func (m *Manager) Config() *config.Cluster {
 	return m.config
 }
 
-// SetConfig sets the cluster configuration
-func (m *Manager) SetConfig(cfg *config.Cluster) {
+// SetConfig sets the cluster configuration and switches to the provider it names
+func (m *Manager) SetConfig(cfg *config.Cluster) error {
+	if err := m.useProvider(cfg); err != nil {
+		return err
+	}
 	m.config = cfg
+	return nil
+}
+
+// Option configures the defaults used by New
+type Option func(*options)
+
+type options struct {
+	topology     Topology
+	version      string
+	provider     provider.Client
+	providerName string
+	concurrency  int
+	readiness    bool
+	setup        bool
+	acl          bool
+	tls          bool
+	datacenter   string
+	region       string
+}
+
+// WithTopology sets the number of servers and clients for a new cluster.
+// It has no effect on clusters that already exist.
+func WithTopology(t Topology) Option {
+	return func(o *options) {
+		o.topology = t
+	}
+}
+
+// WithVersion sets the hind release a new cluster is created from. An empty
+// version or "latest" selects the latest release. It has no effect on
+// clusters that already exist, they stay pinned to their release.
+func WithVersion(version string) Option {
+	return func(o *options) {
+		o.version = version
+	}
+}
+
+// WithConcurrency sets the number of containers of a reconcile tier that
+// are created or started in parallel.
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		o.concurrency = n
+	}
+}
+
+// WithReadinessChecks sets whether Reconcile waits for Consul, Nomad and
+// Vault to be ready once the containers are running. Enabled by default.
+// The setup steps, eg. the ACL bootstrap, run either way.
+func WithReadinessChecks(enabled bool) Option {
+	return func(o *options) {
+		o.readiness = enabled
+	}
+}
+
+// WithACL enables the ACLs of Consul and Nomad in a new cluster. It has no
+// effect on clusters that already exist.
+func WithACL(enabled bool) Option {
+	return func(o *options) {
+		o.acl = enabled
+	}
+}
+
+// WithTLS enables TLS with a generated CA in a new cluster. It has no
+// effect on clusters that already exist.
+func WithTLS(enabled bool) Option {
+	return func(o *options) {
+		o.tls = enabled
+	}
+}
+
+// WithLocation sets the datacenter and region of a new cluster, empty for
+// the defaults. It has no effect on clusters that already exist.
+func WithLocation(datacenter, region string) Option {
+	return func(o *options) {
+		o.datacenter = datacenter
+		o.region = region
+	}
+}
+
+// WithProviderName sets the provider new clusters are created with, eg.
+// from the --provider flag. Existing clusters keep the provider they were
+// created with.
+func WithProviderName(name string) Option {
+	return func(o *options) {
+		o.providerName = name
+	}
+}
+
+// WithProvider sets the provider client the manager uses, eg. a fake
+// provider in tests, in place of the one the cluster config names.
+func WithProvider(client provider.Client) Option {
+	return func(o *options) {
+		o.provider = client
+	}
 }
 
 // New creates a new cluster manager with the given name and default configuration.
 // It initializes the file manager, provider, and cluster configuration for the specified cluster name.
-func New(logger *log.Logger, name string) (*Manager, error) {
-	cfg, err := newClusterConfig(name, release.Latest().Hind)
+func New(logger *log.Logger, name string, opts ...Option) (*Manager, error) {
+	o := &options{
+		topology:     DefaultTopology(),
+		providerName: DefaultProvider,
+		concurrency:  DefaultConcurrency,
+		readiness:    true,
+		setup:        true,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.concurrency < 1 {
+		return nil, fmt.Errorf("concurrency must be at least 1, got %d", o.concurrency)
+	}
+	if err := ValidateProvider(o.providerName); err != nil {
+		return nil, err
+	}
+
+	rel, err := release.Resolve(o.version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get version: %w", err)
+	}
+
+	cfg, err := newClusterConfig(name, rel.Hind, o.topology)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create default cluster config for '%s': %w", name, err)
 	}
+	cfg.Provider = o.providerName
+	if err := setLocation(cfg, o.datacenter, o.region); err != nil {
+		return nil, err
+	}
+	if o.acl {
+		enableACL(cfg)
+	}
+	if o.tls {
+		enableTLS(cfg)
+	}
 	logger.Debugf("created cluster defaults: %+v", cfg)
 
 	fm, err := file.NewFromHomeDir(DefaultConfigParentDir, DefaultConfigName)
@@ -47,12 +187,23 @@ func New(logger *log.Logger, name string) (*Manager, error) {
 		return nil, fmt.Errorf("failed to create file manager with path: %w", err)
 	}
 
+	client := o.provider
+	if client == nil {
+		if client, err = newProvider(logger, o.providerName); err != nil {
+			return nil, err
+		}
+	}
+
 	m := &Manager{
-		logger:     logger,
-		provider:   dockercli.New(logger),
-		config:     cfg,
-		fm:         fm,
-		configFile: file.JoinPath(fm.GetRootDir(), ClusterConfigDir, name, ClusterConfigFile),
+		logger:          logger,
+		provider:        client,
+		fixedProvider:   o.provider != nil,
+		concurrency:     o.concurrency,
+		readinessChecks: o.readiness,
+		setup:           o.setup,
+		config:          cfg,
+		fm:              fm,
+		configFile:      file.JoinPath(fm.GetRootDir(), ClusterConfigDir, name, ClusterConfigFile),
 	}
 	return m, nil
 }
@@ -66,24 +217,66 @@ func New(logger *log.Logger, name string) (*Manager, error) {
 func (m *Manager) Start(ctx context.Context) (StartResult, error) {
 	m.logger.Debug("Starting cluster")
 
-	existed := m.ConfigFileExists()
+	existed, err := m.Load()
+	if err != nil {
+		return StartResultCreated, err
+	}
 
-	if existed {
-		// Load existing config
-		cfg, err := m.loadConfig()
-		if err != nil {
-			return StartResultCreated, fmt.Errorf("failed to load cluster config: %w", err)
+	// New clusters move their published ports clear of other clusters
+	if !existed {
+		if err := m.AllocateHostPorts(); err != nil {
+			return StartResultCreated, err
 		}
-		m.config = cfg
-		m.logger.Debug("Loaded existing cluster configuration")
-	} else {
-		// Use the config created by New() - it already has defaults
+	}
+
+	return m.apply(ctx, existed)
+}
+
+// Load replaces the in-memory config with the persisted cluster config.
+// Returns false and keeps the in-memory config if the cluster doesn't exist.
+func (m *Manager) Load() (bool, error) {
+	if !m.ConfigFileExists() {
+		return false, nil
+	}
+
+	cfg, err := m.loadConfig()
+	if err != nil {
+		return true, fmt.Errorf("failed to load cluster config: %w", err)
+	}
+	if err := m.useProvider(cfg); err != nil {
+		return true, err
+	}
+	m.config = cfg
+	m.logger.Debug("Loaded existing cluster configuration")
+
+	return true, nil
+}
+
+// Apply makes the cluster match the in-memory configuration, eg. one set
+// with SetConfig from a cluster definition file. Unlike Start the persisted
+// configuration is not loaded, the in-memory configuration replaces it once
+// reconciliation succeeds. If the cluster doesn't exist, it will be created.
+func (m *Manager) Apply(ctx context.Context) (StartResult, error) {
+	m.logger.Debug("Applying cluster configuration")
+
+	return m.apply(ctx, m.ConfigFileExists())
+}
+
+// apply reconciles the in-memory config, creating the cluster directory for
+// clusters that did not exist yet.
+func (m *Manager) apply(ctx context.Context, existed bool) (StartResult, error) {
+	if !existed {
+		// Use the in-memory config - it already has defaults
 		// Just ensure the directory exists
 		clusterDir := file.JoinPath(m.fm.GetRootDir(), ClusterConfigDir, m.config.Name)
 		if err := m.fm.EnsureDir(clusterDir); err != nil {
 			return StartResultCreated, fmt.Errorf("failed to create cluster dir: %w", err)
 		}
 		m.logger.Debugf("Created cluster directory '%s'", clusterDir)
+
+		if err := m.ensureGossipKeys(); err != nil {
+			return StartResultCreated, err
+		}
 	}
 
 	// Reconcile makes reality match config
@@ -188,7 +381,14 @@ func (m *Manager) Stop(ctx context.Context) error {
 	return nil
 }
 
-func (m *Manager) Delete(ctx context.Context) error {
+// DeleteOptions controls what Delete removes besides the containers
+type DeleteOptions struct {
+	// KeepVolumes keeps the volumes hind created for the cluster
+	KeepVolumes bool
+}
+
+// Delete removes the cluster's containers, network, volumes and config
+func (m *Manager) Delete(ctx context.Context, opts DeleteOptions) error {
 	// Load cluster config from disk if not already in memory
 	// This allows Delete to work even if Manager was created without loading config
 	if m.config == nil || m.config.Name == "" {
@@ -199,27 +399,25 @@ func (m *Manager) Delete(ctx context.Context) error {
 		m.config = cfg
 	}
 
-	// TODO: make delete idempotent
-
-	// TODO: get all containers by label and pass as one command
-	// Delete cluster nodes
-	for _, node := range m.config.Nodes {
-		containerInfo, err := m.provider.InspectContainer(ctx, node.Name)
-		if containerInfo == nil {
-			m.logger.WithField("name", node.Name).Debug("container not found, skipping...")
-			continue
-		} else if err != nil {
-			return err
-		} else if containerInfo.Status == provider.Running.String() {
-			if err = m.provider.StopContainer(ctx, node.Name); err != nil {
-				return err
-			}
+	containers, err := m.clusterContainers(ctx)
+	if err != nil {
+		return err
+	}
+	for _, c := range containers {
+		if err := deleteContainer(ctx, m.provider, c); err != nil {
+			return fmt.Errorf("failed to delete node '%s': %w", c.Name, err)
 		}
+		m.logger.WithField("name", c.Name).Info("deleted node")
+	}
 
-		if err := m.provider.DeleteContainer(ctx, node.Name); err != nil {
-			return fmt.Errorf("failed to delete node '%s': %w", node.Name, err)
-		}
-		m.logger.WithField("name", node.Name).Info("deleted node")
+	if err := m.leaveFederation(ctx); err != nil {
+		return err
+	}
+
+	if opts.KeepVolumes {
+		m.logger.WithField("name", m.config.Name).Info("keeping cluster volumes")
+	} else if err := m.deleteVolumes(ctx); err != nil {
+		return err
 	}
 
 	// Check if network exists
@@ -239,6 +437,48 @@ func (m *Manager) Delete(ctx context.Context) error {
 	return nil
 }
 
+// clusterContainers returns the containers of the cluster's nodes and any
+// other carrying its label, eg. left behind by a scale down or an
+// interrupted start
+func (m *Manager) clusterContainers(ctx context.Context) ([]provider.ContainerInfo, error) {
+	containers, err := m.provider.ListContainers(ctx, []string{"label=" + ClusterLabel + "=" + m.config.Name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	for _, node := range m.config.Nodes {
+		if slices.ContainsFunc(containers, func(c provider.ContainerInfo) bool { return c.Name == node.Name }) {
+			continue
+		}
+		info, err := m.provider.InspectContainer(ctx, node.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect node '%s': %w", node.Name, err)
+		}
+		if info == nil {
+			m.logger.WithField("name", node.Name).Debug("container not found, skipping...")
+			continue
+		}
+		containers = append(containers, *info)
+	}
+	return containers, nil
+}
+
+// deleteVolumes removes the volumes labeled as belonging to the cluster
+func (m *Manager) deleteVolumes(ctx context.Context) error {
+	volumes, err := m.provider.ListVolumes(ctx, []string{"label=" + ClusterLabel + "=" + m.config.Name})
+	if err != nil {
+		return fmt.Errorf("failed to list volumes: %w", err)
+	}
+
+	for _, v := range volumes {
+		if err := m.provider.DeleteVolume(ctx, v.Name); err != nil {
+			return fmt.Errorf("failed to delete volume '%s': %w", v.Name, err)
+		}
+		m.logger.WithField("name", v.Name).Info("deleted volume")
+	}
+	return nil
+}
+
 func (m *Manager) Get(ctx context.Context) (*provider.ClusterInfo, error) {
 	state := &provider.ClusterInfo{}
 
@@ -248,7 +488,10 @@ func (m *Manager) Get(ctx context.Context) (*provider.ClusterInfo, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to inspect network: %w", err)
 	}
-	state.Network = *networkInfo
+	// A nil network means it doesn't exist (yet)
+	if networkInfo != nil {
+		state.Network = *networkInfo
+	}
 
 	containerInfos := []provider.ContainerInfo{}
 	for _, node := range m.config.Nodes {
@@ -299,22 +542,9 @@ func (m *Manager) SetClientCount(ctx context.Context, count int) error {
 	}
 
 	for i := 0; i < count; i++ {
-		nomadClient := config.Node{
-			Name:    fmt.Sprintf("hind.%s.client.%.2d", name, i+1),
-			Kind:    config.NomadNode,
-			Role:    config.Client,
-			Network: m.config.Network.Name,
-			Image: config.Image{
-				Name: release.NomadClient.ImageName(),
-				Tag:  v.Hind,
-			},
-			Devices: []string{"/dev/fuse"},
-			Environment: map[string]string{
-				"CONSUL_AGENT_MODE":     "client",
-				"CONSUL_SERVER_ADDRESS": fmt.Sprintf("hind.%s.consul.%.2d", name, 1),
-				"NOMAD_AGENT_MODE":      "client",
-			},
-		}
+		nomadClient := newNode(name, config.NomadNode, config.Client, i+1, v.Hind)
+		maps.Copy(nomadClient.Environment, joinEnvironment(newNodes, nomadClient))
+		maps.Copy(nomadClient.Environment, clusterEnvironment(m.config, nomadClient))
 		newNodes = append(newNodes, nomadClient)
 	}
 
@@ -329,7 +559,7 @@ func (m *Manager) loadConfig() (*config.Cluster, error) {
 	}
 
 	var cfg config.Cluster
-	if err := json.Unmarshal(data, &cfg); err != nil {
+	if err := config.UnmarshalSaved(data, &cfg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal state: %w", err)
 	}
 
@@ -366,6 +596,17 @@ func (m *Manager) CountClientNodes() int {
 	return count
 }
 
+// CountServerNodes returns the number of server nodes of the given kind in the cluster
+func (m *Manager) CountServerNodes(kind config.Kind) int {
+	count := 0
+	for _, node := range m.config.Nodes {
+		if node.Kind == kind && node.Role == config.Server {
+			count++
+		}
+	}
+	return count
+}
+
 // getClientNodes returns all client nodes from the cluster configuration
 func (m *Manager) getClientNodes() []config.Node {
 	clients := []config.Node{}
@@ -391,28 +632,43 @@ func (m *Manager) findNodeConfigByName(name string) *config.Node {
 // This is declarative - it updates the config and reconciles.
 func (m *Manager) Scale(ctx context.Context, targetClientCount int) error {
 	currentClientCount := m.CountClientNodes()
-
 	if targetClientCount == currentClientCount {
-		m.logger.Infof("Cluster already has %d client nodes", currentClientCount)
+		m.logger.Infof("Cluster already has %d client nodes", targetClientCount)
 		return nil
 	}
 
+	direction := "up"
+	if targetClientCount < currentClientCount {
+		direction = "down"
+	}
+	m.logger.Infof("Scaling %s from %d to %d client nodes", direction, currentClientCount, targetClientCount)
+	if err := m.ResizeClients(targetClientCount); err != nil {
+		return err
+	}
+
+	// Reconcile to make reality match config
+	return m.Reconcile(ctx)
+}
+
+// ResizeClients adds or removes client node configs to reach the target
+// number of client nodes. Does NOT change infrastructure - just updates config,
+// eg. to preview a scale with Plan, and leaves reporting the scale to callers.
+func (m *Manager) ResizeClients(targetClientCount int) error {
+	currentClientCount := m.CountClientNodes()
+
 	if targetClientCount > currentClientCount {
 		// Scale up: add node configs
-		m.logger.Infof("Scaling up from %d to %d client nodes", currentClientCount, targetClientCount)
 		if err := m.addClientNodes(targetClientCount - currentClientCount); err != nil {
 			return err
 		}
 	} else {
 		// Scale down: remove node configs
-		m.logger.Infof("Scaling down from %d to %d client nodes", currentClientCount, targetClientCount)
 		if err := m.removeClientNodes(currentClientCount - targetClientCount); err != nil {
 			return err
 		}
 	}
 
-	// Reconcile to make reality match config
-	return m.Reconcile(ctx)
+	return nil
 }
 
 // addClientNodes adds N client node configs to the cluster config.
@@ -430,22 +686,9 @@ func (m *Manager) addClientNodes(count int) error {
 
 	for i := 0; i < count; i++ {
 		nodeNum := currentClientCount + i + 1
-		nomadClient := config.Node{
-			Name:    fmt.Sprintf("hind.%s.client.%.2d", name, nodeNum),
-			Kind:    config.NomadNode,
-			Role:    config.Client,
-			Network: m.config.Network.Name,
-			Image: config.Image{
-				Name: release.NomadClient.ImageName(),
-				Tag:  v.Hind,
-			},
-			Devices: []string{"/dev/fuse"},
-			Environment: map[string]string{
-				"CONSUL_AGENT_MODE":     "client",
-				"CONSUL_SERVER_ADDRESS": fmt.Sprintf("hind.%s.consul.%.2d", name, 1),
-				"NOMAD_AGENT_MODE":      "client",
-			},
-		}
+		nomadClient := newNode(name, config.NomadNode, config.Client, nodeNum, v.Hind)
+		maps.Copy(nomadClient.Environment, joinEnvironment(m.config.Nodes, nomadClient))
+		maps.Copy(nomadClient.Environment, clusterEnvironment(m.config, nomadClient))
 		m.config.Nodes = append(m.config.Nodes, nomadClient)
 	}
 
@@ -0,0 +1,262 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+	"github.com/stenh0use/hind/pkg/provider/fake"
+)
+
+// newFakeManager creates a manager for a new cluster backed by a fake provider
+func newFakeManager(t *testing.T, name string, p *fake.Provider, opts ...Option) *Manager {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	withBusyPorts(t)
+
+	logger := &log.Logger{Handler: discard.New(), Level: log.ErrorLevel}
+	opts = append([]Option{withSetup(false)}, opts...)
+	m, err := New(logger, name, append(opts, WithProvider(p), WithReadinessChecks(false))...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return m
+}
+
+// withSetup sets whether Reconcile runs the setup steps, which need the
+// agents a fake provider doesn't run unless its exec plays them
+func withSetup(enabled bool) Option {
+	return func(o *options) {
+		o.setup = enabled
+	}
+}
+
+func clusterStatuses(t *testing.T, m *Manager) map[string]string {
+	t.Helper()
+	info, err := m.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	statuses := map[string]string{}
+	for _, c := range info.Containers {
+		statuses[c.Name] = c.Status
+	}
+	return statuses
+}
+
+func TestManager_Start(t *testing.T) {
+	p := fake.New()
+	m := newFakeManager(t, "dev", p)
+	ctx := context.Background()
+
+	result, err := m.Start(ctx)
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if result != StartResultCreated {
+		t.Errorf("Start() = %v, want %v", result, StartResultCreated)
+	}
+
+	statuses := clusterStatuses(t, m)
+	if len(statuses) != len(m.Config().Nodes) {
+		t.Fatalf("containers = %v, want one per node", statuses)
+	}
+	for name, status := range statuses {
+		if status != provider.Running.String() {
+			t.Errorf("container %s status = %s, want running", name, status)
+		}
+	}
+	if !m.ConfigFileExists() {
+		t.Error("config was not saved after reconciliation")
+	}
+
+	// A second start changes nothing
+	before := len(p.CallsTo(fake.MethodCreateContainer))
+	if _, err := m.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if after := len(p.CallsTo(fake.MethodCreateContainer)); after != before {
+		t.Errorf("second Start() created %d containers, want 0", after-before)
+	}
+}
+
+func TestManager_Get(t *testing.T) {
+	p := fake.New()
+	m := newFakeManager(t, "dev", p)
+	ctx := context.Background()
+
+	if _, err := m.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	info, err := m.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if info.Network.Subnet == "" || info.Network.Gateway == "" {
+		t.Errorf("Get() network = %+v, want its subnet and gateway", info.Network)
+	}
+
+	subnet, err := netip.ParsePrefix(info.Network.Subnet)
+	if err != nil {
+		t.Fatalf("network subnet %q: %v", info.Network.Subnet, err)
+	}
+	for _, c := range info.Containers {
+		addr, err := netip.ParseAddr(c.Address)
+		if err != nil || !subnet.Contains(addr) {
+			t.Errorf("container %s address = %q, want an address in %s", c.Name, c.Address, subnet)
+		}
+		if c.Network != m.Config().Network.Name {
+			t.Errorf("container %s network = %q, want %q", c.Name, c.Network, m.Config().Network.Name)
+		}
+		if c.Labels["hind.cluster"] != "dev" {
+			t.Errorf("container %s labels = %v, want the cluster label", c.Name, c.Labels)
+		}
+	}
+}
+
+func TestManager_Reconcile_RecoversContainers(t *testing.T) {
+	p := fake.New()
+	m := newFakeManager(t, "dev", p)
+	ctx := context.Background()
+
+	if _, err := m.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	nodes := m.Config().Nodes
+	crashed, stopped := nodes[0].Name, nodes[1].Name
+	if err := p.SetStatus(crashed, provider.Error); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetStatus(stopped, provider.Stopped); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Reconcile(ctx); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	for name, status := range clusterStatuses(t, m) {
+		if status != provider.Running.String() {
+			t.Errorf("container %s status = %s, want running", name, status)
+		}
+	}
+	if calls := p.CallsTo(fake.MethodDeleteContainer); len(calls) != 1 || calls[0].Name != crashed {
+		t.Errorf("DeleteContainer calls = %v, want only %s recreated", calls, crashed)
+	}
+	if calls := p.CallsTo(fake.MethodStartContainer); len(calls) != 1 || calls[0].Name != stopped {
+		t.Errorf("StartContainer calls = %v, want only %s started", calls, stopped)
+	}
+}
+
+func TestManager_Reconcile_Failure(t *testing.T) {
+	p := fake.New()
+	m := newFakeManager(t, "dev", p)
+	failing := m.Config().Nodes[0].Name
+	p.Fail(fake.Failure{Method: fake.MethodCreateContainer, Name: failing, Times: 1})
+
+	_, err := m.Start(context.Background())
+	if !errors.Is(err, fake.ErrInjected) {
+		t.Fatalf("Start() error = %v, want injected failure", err)
+	}
+	if !strings.Contains(err.Error(), failing) {
+		t.Errorf("Start() error = %v, want it to name %s", err, failing)
+	}
+	if m.ConfigFileExists() {
+		t.Error("config was saved after a failed reconciliation")
+	}
+
+	// The failure was one-shot, a retry converges
+	if _, err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start() retry error = %v", err)
+	}
+}
+
+func TestManager_Reconcile_MissingImage(t *testing.T) {
+	p := fake.New(fake.WithImages())
+	m := newFakeManager(t, "dev", p)
+
+	_, err := m.Start(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "has not been built") {
+		t.Fatalf("Start() error = %v, want missing image error", err)
+	}
+	if calls := p.CallsTo(fake.MethodCreateContainer); len(calls) != 0 {
+		t.Errorf("CreateContainer calls = %v, want none before images are built", calls)
+	}
+}
+
+func TestManager_Scale(t *testing.T) {
+	p := fake.New()
+	m := newFakeManager(t, "dev", p)
+	ctx := context.Background()
+
+	if _, err := m.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := m.Scale(ctx, 3); err != nil {
+		t.Fatalf("Scale() error = %v", err)
+	}
+
+	if got := m.CountClientNodes(); got != 3 {
+		t.Errorf("CountClientNodes() = %d, want 3", got)
+	}
+	statuses := clusterStatuses(t, m)
+	for _, node := range m.getClientNodes() {
+		if statuses[node.Name] != provider.Running.String() {
+			t.Errorf("client %s status = %q, want running", node.Name, statuses[node.Name])
+		}
+	}
+}
+
+func TestManager_Delete(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        DeleteOptions
+		wantVolumes bool
+	}{
+		{"delete volumes", DeleteOptions{}, false},
+		{"keep volumes", DeleteOptions{KeepVolumes: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := fake.New()
+			m := newFakeManager(t, "dev", p)
+			ctx := context.Background()
+
+			node := &m.Config().Nodes[0]
+			node.Volumes = append(node.Volumes, config.Volume{Name: "hind.dev.data", Destination: "/data"})
+
+			if _, err := m.Start(ctx); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			if err := m.Delete(ctx, tt.opts); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+
+			if statuses := clusterStatuses(t, m); len(statuses) != 0 {
+				t.Errorf("containers after Delete() = %v, want none", statuses)
+			}
+			if n, _ := p.InspectNetwork(ctx, m.Config().Network.Name); n != nil {
+				t.Errorf("network %s still exists after Delete()", n.Name)
+			}
+			// Only named volumes outlive their containers, anonymous ones
+			// are deleted with them
+			volumes, err := p.ListVolumes(ctx, []string{"label=hind.cluster=dev"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(volumes) > 0; got != tt.wantVolumes {
+				t.Errorf("volumes after Delete() = %v, want kept %v", volumes, tt.wantVolumes)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stenh0use/hind/pkg/config"
+)
+
+// AllNodes selects every node of the cluster in ResolveNodes
+const AllNodes = "all"
+
+// ResolveNodes returns the nodes of the cluster matching target, in config
+// order. The target is a node name, with or without the hind.<cluster>.
+// prefix (eg. client.02), a kind (eg. nomad), a role (eg. server) or all.
+func (m *Manager) ResolveNodes(target string) ([]config.Node, error) {
+	if target == "" {
+		return nil, fmt.Errorf("a node name, kind or role is required")
+	}
+
+	var nodes []config.Node
+	for _, n := range m.config.Nodes {
+		if n.Name == target || m.ShortNodeName(n.Name) == target {
+			return []config.Node{n}, nil
+		}
+		if target == AllNodes || n.Kind.String() == target || n.Role.String() == target {
+			nodes = append(nodes, n)
+		}
+	}
+	if len(nodes) == 0 {
+		var names []string
+		for _, n := range m.config.Nodes {
+			names = append(names, m.ShortNodeName(n.Name))
+		}
+		return nil, fmt.Errorf("no node of cluster '%s' matches '%s', expected one of %s, a kind, a role or %s",
+			m.config.Name, target, strings.Join(names, ", "), AllNodes)
+	}
+	return nodes, nil
+}
+
+// ShortNodeName returns the name of a node without the hind.<cluster>.
+// prefix, eg. client.01
+func (m *Manager) ShortNodeName(name string) string {
+	return strings.TrimPrefix(name, "hind."+m.config.Name+".")
+}
+
+// nodesOf returns the nodes of a kind with a role, in config order
+func (m *Manager) nodesOf(kind config.Kind, role config.Role) []config.Node {
+	var nodes []config.Node
+	for _, n := range m.config.Nodes {
+		if n.Kind == kind && n.Role == role {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
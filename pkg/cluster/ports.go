@@ -0,0 +1,235 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/file"
+)
+
+// MaxPortOffset is how far published host ports are moved from their
+// defaults looking for free ports before giving up.
+const MaxPortOffset = 100
+
+// Endpoint is the address of a service published on the host
+type Endpoint struct {
+	Name string
+	URL  string
+}
+
+// endpointServices maps the container port of each service's HTTP API to
+// the node kind serving it, in display order.
+var endpointServices = []struct {
+	name string
+	kind config.Kind
+	port int32
+}{
+	{"Nomad", config.NomadNode, 4646},
+	{"Consul", config.ConsulNode, 8500},
+	{"Vault", config.VaultNode, 8200},
+}
+
+// portAvailable reports whether a host port can be bound. Replaced in tests.
+var portAvailable = func(address string, port int32, protocol string) bool {
+	addr := net.JoinHostPort(address, strconv.Itoa(int(port)))
+	if protocol == "udp" {
+		conn, err := net.ListenPacket("udp", addr)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return false
+	}
+	l.Close()
+	return true
+}
+
+// Endpoints returns the host addresses of the services published by the
+// cluster, eg. Nomad at http://localhost:4646, or https with TLS enabled.
+// The ports are the ones the provider reports as published, so ports
+// published on a random host port resolve too.
+func (m *Manager) Endpoints(ctx context.Context) ([]Endpoint, error) {
+	var endpoints []Endpoint
+
+	for _, svc := range endpointServices {
+		host, port, err := m.publishedAddress(ctx, svc.kind, svc.port)
+		if err != nil {
+			return nil, err
+		}
+		if port == "" {
+			continue
+		}
+		if host == "" || host == "0.0.0.0" || host == "::" {
+			host = "localhost"
+		}
+		endpoints = append(endpoints, Endpoint{
+			Name: svc.name,
+			URL:  m.apiScheme() + "://" + net.JoinHostPort(host, port),
+		})
+	}
+	return endpoints, nil
+}
+
+// publishedAddress returns the host address and port a container port of a
+// node of the given kind is published on. Nodes whose container doesn't
+// publish the port, eg. it isn't running, fall back to the fixed host port of
+// the config. Both are empty if the port isn't published.
+func (m *Manager) publishedAddress(ctx context.Context, kind config.Kind, containerPort int32) (string, string, error) {
+	for _, node := range m.config.Nodes {
+		if node.Kind != kind {
+			continue
+		}
+		i := slices.IndexFunc(node.Ports, func(p config.PortMapping) bool { return p.ContainerPort == containerPort })
+		if i < 0 {
+			continue
+		}
+
+		info, err := m.provider.InspectContainer(ctx, node.Name)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to inspect node '%s': %w", node.Name, err)
+		}
+		if info != nil {
+			if host, port, ok := findPublishedPort(info.Ports, containerPort); ok {
+				return host, port, nil
+			}
+		}
+		if p := node.Ports[i]; p.HostPort != 0 {
+			return p.ListenAddress, strconv.Itoa(int(p.HostPort)), nil
+		}
+	}
+	return "", "", nil
+}
+
+// findPublishedPort returns the host address and port a tcp container port
+// is published on, from the ports a provider reports for a container, eg.
+// 0.0.0.0:8501->8500/tcp, [::]:8501->8500/tcp or 8501->8500/tcp.
+func findPublishedPort(ports []string, containerPort int32) (string, string, bool) {
+	target := fmt.Sprintf("%d/tcp", containerPort)
+	for _, p := range ports {
+		published, port, ok := strings.Cut(p, "->")
+		if !ok || port != target {
+			continue
+		}
+		if host, hostPort, err := net.SplitHostPort(published); err == nil {
+			return host, hostPort, true
+		}
+		return "", published, true
+	}
+	return "", "", false
+}
+
+// AllocateHostPorts moves the published host ports of the cluster by the
+// smallest offset at which none of them are in use, either bound on the host
+// or claimed by the config of another cluster, so clusters can run side by
+// side. Stopped clusters keep their ports reserved.
+//
+// Start allocates the ports of new clusters, callers previewing a new
+// cluster, eg. plan, allocate them the same way.
+func (m *Manager) AllocateHostPorts() error {
+	reserved, err := m.reservedHostPorts()
+	if err != nil {
+		return err
+	}
+
+	for offset := int32(0); offset <= MaxPortOffset; offset++ {
+		if !m.hostPortsFree(offset, reserved) {
+			continue
+		}
+		if offset == 0 {
+			return nil
+		}
+
+		for i := range m.config.Nodes {
+			for j := range m.config.Nodes[i].Ports {
+				if p := &m.config.Nodes[i].Ports[j]; p.HostPort != 0 {
+					p.HostPort += offset
+				}
+			}
+		}
+		m.logger.Infof("Default ports are in use, cluster '%s' publishes its ports with offset %d", m.config.Name, offset)
+		return nil
+	}
+
+	return fmt.Errorf("no free host ports found within %d of the defaults for cluster '%s'\n"+
+		"Resolution: Stop or delete another cluster with 'hind rm'", MaxPortOffset, m.config.Name)
+}
+
+// hostPortsFree reports whether every published host port, moved by offset,
+// is neither reserved nor bound on the host.
+func (m *Manager) hostPortsFree(offset int32, reserved map[string]bool) bool {
+	for _, node := range m.config.Nodes {
+		for _, p := range node.Ports {
+			if p.HostPort == 0 {
+				continue
+			}
+			port := p.HostPort + offset
+			if reserved[portKey(port, p.Protocol)] || !portAvailable(p.ListenAddress, port, p.Protocol) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// reservedHostPorts returns the host ports published by the other clusters
+// that have a saved config, keyed by portKey.
+func (m *Manager) reservedHostPorts() (map[string]bool, error) {
+	reserved := map[string]bool{}
+
+	if !m.fm.DirExists(ClusterConfigDir) {
+		return reserved, nil
+	}
+	entries, err := m.fm.ListDir(ClusterConfigDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clusters: %w", err)
+	}
+
+	for _, e := range entries {
+		if !e.IsDir() || e.Name() == m.config.Name {
+			continue
+		}
+
+		path := file.JoinPath(ClusterConfigDir, e.Name(), ClusterConfigFile)
+		if !m.fm.FileExists(path) {
+			continue
+		}
+		data, err := m.fm.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config of cluster '%s': %w", e.Name(), err)
+		}
+
+		var cfg config.Cluster
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			m.logger.Warnf("Ignoring ports of cluster '%s', invalid config: %v", e.Name(), err)
+			continue
+		}
+		for _, node := range cfg.Nodes {
+			for _, p := range node.Ports {
+				if p.HostPort != 0 {
+					reserved[portKey(p.HostPort, p.Protocol)] = true
+				}
+			}
+		}
+	}
+
+	return reserved, nil
+}
+
+// portKey identifies a host port and protocol, eg. 8500/tcp
+func portKey(port int32, protocol string) string {
+	if protocol == "" {
+		protocol = "tcp"
+	}
+	return fmt.Sprintf("%d/%s", port, protocol)
+}
@@ -0,0 +1,216 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/file"
+	"github.com/stenh0use/hind/pkg/provider/fake"
+)
+
+// withBusyPorts replaces portAvailable so the given tcp ports are in use
+func withBusyPorts(t *testing.T, busy ...int32) {
+	t.Helper()
+	orig := portAvailable
+	t.Cleanup(func() { portAvailable = orig })
+
+	portAvailable = func(address string, port int32, protocol string) bool {
+		for _, b := range busy {
+			if port == b {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func newPortsTestManager(t *testing.T, name string) *Manager {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	logger := &log.Logger{Handler: discard.New(), Level: log.ErrorLevel}
+	m, err := New(logger, name)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return m
+}
+
+func hostPorts(cfg *config.Cluster) []int32 {
+	var ports []int32
+	for _, node := range cfg.Nodes {
+		for _, p := range node.Ports {
+			ports = append(ports, p.HostPort)
+		}
+	}
+	return ports
+}
+
+func TestAllocateHostPorts(t *testing.T) {
+	tests := []struct {
+		name string
+		busy []int32
+		want []int32
+	}{
+		{
+			name: "defaults are free",
+			want: []int32{8500, 4646, 8200},
+		},
+		{
+			name: "one default is bound on the host",
+			busy: []int32{4646},
+			want: []int32{8501, 4647, 8201},
+		},
+		{
+			name: "first offsets are bound on the host",
+			busy: []int32{8500, 8201},
+			want: []int32{8502, 4648, 8202},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withBusyPorts(t, tt.busy...)
+			m := newPortsTestManager(t, "dev")
+
+			if err := m.AllocateHostPorts(); err != nil {
+				t.Fatalf("AllocateHostPorts() error = %v", err)
+			}
+
+			got := hostPorts(m.Config())
+			if len(got) != len(tt.want) {
+				t.Fatalf("host ports = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("host ports = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestAllocateHostPorts_ReservedByOtherCluster(t *testing.T) {
+	withBusyPorts(t)
+	m := newPortsTestManager(t, "second")
+
+	// A stopped cluster keeps its ports reserved in its config
+	first, err := newClusterConfig("first", m.Config().Version, DefaultTopology())
+	if err != nil {
+		t.Fatalf("newClusterConfig() error = %v", err)
+	}
+	data, err := json.Marshal(first)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if err := m.fm.EnsureDir(file.JoinPath(ClusterConfigDir, "first")); err != nil {
+		t.Fatalf("EnsureDir() error = %v", err)
+	}
+	if err := m.fm.WriteFile(file.JoinPath(ClusterConfigDir, "first", ClusterConfigFile), data); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if err := m.AllocateHostPorts(); err != nil {
+		t.Fatalf("AllocateHostPorts() error = %v", err)
+	}
+
+	want := []int32{8501, 4647, 8201}
+	got := hostPorts(m.Config())
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("host ports = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestAllocateHostPorts_NoFreePorts(t *testing.T) {
+	orig := portAvailable
+	t.Cleanup(func() { portAvailable = orig })
+	portAvailable = func(string, int32, string) bool { return false }
+
+	m := newPortsTestManager(t, "dev")
+	if err := m.AllocateHostPorts(); err == nil {
+		t.Error("AllocateHostPorts() error = nil, want error when no ports are free")
+	}
+}
+
+func TestEndpoints(t *testing.T) {
+	ctx := context.Background()
+	m := newFakeManager(t, "dev", fake.New())
+	for i, n := range m.config.Nodes {
+		switch n.Kind {
+		case config.NomadNode:
+			m.config.Nodes[i].Ports = []config.PortMapping{{ListenAddress: "127.0.0.1", HostPort: 4647, ContainerPort: 4646}}
+		case config.VaultNode:
+			// Ports published on a random host port resolve once the container runs
+			m.config.Nodes[i].Ports = []config.PortMapping{{ContainerPort: 8200}}
+		}
+	}
+
+	// Before the containers run the fixed host ports of the config are used
+	want := []Endpoint{
+		{Name: "Nomad", URL: "http://127.0.0.1:4647"},
+		{Name: "Consul", URL: "http://localhost:8500"},
+	}
+	got, err := m.Endpoints(ctx)
+	if err != nil {
+		t.Fatalf("Endpoints() error = %v", err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Endpoints() = %v, want %v", got, want)
+	}
+
+	if _, err := m.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	info, err := m.provider.InspectContainer(ctx, "hind.dev.vault.01")
+	if err != nil || info == nil {
+		t.Fatalf("InspectContainer() = %v, %v", info, err)
+	}
+	host, port, ok := findPublishedPort(info.Ports, 8200)
+	if !ok || host != "0.0.0.0" || port == "0" {
+		t.Fatalf("vault published ports = %v, want a random host port", info.Ports)
+	}
+
+	want = append(want, Endpoint{Name: "Vault", URL: "http://localhost:" + port})
+	got, err = m.Endpoints(ctx)
+	if err != nil {
+		t.Fatalf("Endpoints() error = %v", err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Endpoints() = %v, want %v", got, want)
+	}
+}
+
+func TestFindPublishedPort(t *testing.T) {
+	tests := []struct {
+		name     string
+		ports    []string
+		wantHost string
+		wantPort string
+		wantOK   bool
+	}{
+		{"ipv4", []string{"0.0.0.0:8501->8500/tcp"}, "0.0.0.0", "8501", true},
+		{"ipv6", []string{"[::]:8501->8500/tcp"}, "::", "8501", true},
+		{"no host address", []string{"8501->8500/tcp"}, "", "8501", true},
+		{"other port", []string{"0.0.0.0:4646->4646/tcp", "127.0.0.1:8600->8600/tcp"}, "", "", false},
+		{"udp", []string{"0.0.0.0:8500->8500/udp"}, "", "", false},
+		{"none", nil, "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, port, ok := findPublishedPort(tt.ports, 8500)
+			if host != tt.wantHost || port != tt.wantPort || ok != tt.wantOK {
+				t.Errorf("findPublishedPort() = %q, %q, %v, want %q, %q, %v", host, port, ok, tt.wantHost, tt.wantPort, tt.wantOK)
+			}
+		})
+	}
+}
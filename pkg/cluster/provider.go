@@ -0,0 +1,74 @@
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apex/log"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+	"github.com/stenh0use/hind/pkg/provider/dockerapi"
+	"github.com/stenh0use/hind/pkg/provider/dockercli"
+	"github.com/stenh0use/hind/pkg/provider/podman"
+)
+
+// Providers that can run cluster containers
+const (
+	DockerCLIProvider = "dockercli"
+	DockerAPIProvider = "dockerapi"
+	PodmanProvider    = "podman"
+)
+
+// Providers lists the names accepted by WithProviderName
+var Providers = []string{DockerCLIProvider, DockerAPIProvider, PodmanProvider}
+
+var providerFactories = map[string]func(*log.Logger) (provider.Client, error){
+	DockerCLIProvider: func(logger *log.Logger) (provider.Client, error) {
+		return dockercli.New(logger), nil
+	},
+	DockerAPIProvider: dockerapi.New,
+	PodmanProvider: func(logger *log.Logger) (provider.Client, error) {
+		return podman.New(logger), nil
+	},
+}
+
+// ValidateProvider returns an error if no provider is registered under name,
+// eg. a mistyped --provider flag
+func ValidateProvider(name string) error {
+	if _, ok := providerFactories[name]; !ok {
+		return fmt.Errorf("unknown provider '%s', expected one of: %s", name, strings.Join(Providers, ", "))
+	}
+	return nil
+}
+
+// newProvider creates the provider client registered under name
+func newProvider(logger *log.Logger, name string) (provider.Client, error) {
+	if err := ValidateProvider(name); err != nil {
+		return nil, err
+	}
+	client, err := providerFactories[name](logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s provider: %w", name, err)
+	}
+	return client, nil
+}
+
+// useProvider switches the manager to the provider the cluster config
+// names. Clusters saved before the provider was recorded used the default.
+func (m *Manager) useProvider(cfg *config.Cluster) error {
+	if cfg.Provider == "" {
+		cfg.Provider = DefaultProvider
+	}
+	if m.fixedProvider || (m.config != nil && m.provider != nil && cfg.Provider == m.config.Provider) {
+		return nil
+	}
+
+	client, err := newProvider(m.logger, cfg.Provider)
+	if err != nil {
+		return fmt.Errorf("cluster '%s': %w", cfg.Name, err)
+	}
+	m.logger.Debugf("Using provider '%s' for cluster '%s'", cfg.Provider, cfg.Name)
+	m.provider = client
+	return nil
+}
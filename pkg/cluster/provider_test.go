@@ -0,0 +1,70 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider/dockercli"
+	"github.com/stenh0use/hind/pkg/provider/podman"
+)
+
+func TestNew_ProviderName(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	logger := &log.Logger{Handler: discard.New(), Level: log.ErrorLevel}
+
+	m, err := New(logger, "dev", WithProviderName(PodmanProvider))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if m.Config().Provider != PodmanProvider {
+		t.Errorf("Provider = %q, want %q", m.Config().Provider, PodmanProvider)
+	}
+	if _, ok := m.provider.(*podman.Client); !ok {
+		t.Errorf("provider = %T, want *podman.Client", m.provider)
+	}
+
+	m, err = New(logger, "dev")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if m.Config().Provider != DefaultProvider {
+		t.Errorf("Provider = %q, want %q", m.Config().Provider, DefaultProvider)
+	}
+
+	if _, err := New(logger, "dev", WithProviderName("lxc")); err == nil {
+		t.Error("New() with unknown provider error = nil, want error")
+	}
+}
+
+func TestSetConfig_Provider(t *testing.T) {
+	m := &Manager{
+		logger: &log.Logger{Handler: discard.New(), Level: log.ErrorLevel},
+		config: &config.Cluster{Name: "dev", Provider: DockerCLIProvider},
+	}
+
+	if err := m.SetConfig(&config.Cluster{Name: "dev", Provider: PodmanProvider}); err != nil {
+		t.Fatalf("SetConfig() error = %v", err)
+	}
+	if _, ok := m.provider.(*podman.Client); !ok {
+		t.Errorf("provider = %T, want *podman.Client", m.provider)
+	}
+
+	// Clusters saved before the provider was recorded use the default
+	legacy := &config.Cluster{Name: "dev"}
+	if err := m.SetConfig(legacy); err != nil {
+		t.Fatalf("SetConfig() error = %v", err)
+	}
+	if legacy.Provider != DefaultProvider {
+		t.Errorf("Provider = %q, want %q", legacy.Provider, DefaultProvider)
+	}
+	if _, ok := m.provider.(*dockercli.Client); !ok {
+		t.Errorf("provider = %T, want *dockercli.Client", m.provider)
+	}
+
+	if err := m.SetConfig(&config.Cluster{Name: "dev", Provider: "lxc"}); err == nil {
+		t.Error("SetConfig() with unknown provider error = nil, want error")
+	}
+}
@@ -0,0 +1,153 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/apex/log"
+
+	"github.com/stenh0use/hind/pkg/file"
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+// Kinds of resources reported by Prune
+const (
+	ContainerResource = "container"
+	NetworkResource   = "network"
+	VolumeResource    = "volume"
+)
+
+// PruneOptions selects the resources Prune deletes
+type PruneOptions struct {
+	// DryRun reports the resources without deleting them
+	DryRun bool
+	// All also deletes named volumes, which hold the data of the cluster
+	All bool
+	// Cluster only prunes the resources of the named cluster
+	Cluster string
+	// Version only prunes the resources of this hind version
+	Version string
+	// Provider searched for the resources, the default provider when empty
+	Provider string
+}
+
+// PrunedResource is a resource Prune deleted, or would delete in a dry run
+type PrunedResource struct {
+	Kind    string
+	Name    string
+	Cluster string
+}
+
+// Prune deletes the containers, networks and anonymous volumes carrying the
+// cluster label of clusters without a saved config, eg. left behind by an
+// interrupted start or rm. Only the provider given in opts is searched, the
+// config of a lost cluster recording its provider is gone and the docker
+// providers share a daemon. It carries on past failures, returning them
+// joined.
+func Prune(ctx context.Context, logger *log.Logger, opts PruneOptions) ([]PrunedResource, error) {
+	fm, err := file.NewFromHomeDir(DefaultConfigParentDir, DefaultConfigName)
+	if err != nil {
+		return nil, err
+	}
+	names, err := List()
+	if err != nil {
+		return nil, err
+	}
+
+	saved := map[string]bool{}
+	for _, name := range names {
+		if fm.FileExists(file.JoinPath(ClusterConfigDir, name, ClusterConfigFile)) {
+			saved[name] = true
+		}
+	}
+
+	providerName := opts.Provider
+	if providerName == "" {
+		providerName = DefaultProvider
+	}
+	client, err := newProvider(logger, providerName)
+	if err != nil {
+		return nil, err
+	}
+	return prune(ctx, logger, client, saved, opts)
+}
+
+// prune deletes the resources selected by opts that don't belong to a saved
+// cluster. Containers go first so their networks and volumes are unused.
+func prune(ctx context.Context, logger *log.Logger, client provider.Client, saved map[string]bool, opts PruneOptions) ([]PrunedResource, error) {
+	filters := []string{"label=" + ClusterLabel}
+	if opts.Cluster != "" {
+		filters[0] += "=" + opts.Cluster
+	}
+	if opts.Version != "" {
+		filters = append(filters, "label="+VersionLabel+"="+opts.Version)
+	}
+
+	var (
+		pruned []PrunedResource
+		errs   []error
+	)
+	remove := func(r PrunedResource, del func() error) {
+		if opts.DryRun {
+			pruned = append(pruned, r)
+			return
+		}
+		if err := del(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete %s '%s': %w", r.Kind, r.Name, err))
+			return
+		}
+		logger.WithField("name", r.Name).Infof("deleted %s", r.Kind)
+		pruned = append(pruned, r)
+	}
+
+	containers, err := client.ListContainers(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	for _, c := range containers {
+		if name := c.Labels[ClusterLabel]; !saved[name] {
+			remove(PrunedResource{ContainerResource, c.Name, name}, func() error {
+				return deleteContainer(ctx, client, c)
+			})
+		}
+	}
+
+	networks, err := client.ListNetworks(ctx, filters)
+	if err != nil {
+		return pruned, errors.Join(append(errs, fmt.Errorf("failed to list networks: %w", err))...)
+	}
+	for _, n := range networks {
+		if name := n.Labels[ClusterLabel]; !saved[name] {
+			remove(PrunedResource{NetworkResource, n.Name, name}, func() error {
+				return client.DeleteNetwork(ctx, n.Name)
+			})
+		}
+	}
+
+	volumes, err := client.ListVolumes(ctx, filters)
+	if err != nil {
+		return pruned, errors.Join(append(errs, fmt.Errorf("failed to list volumes: %w", err))...)
+	}
+	for _, v := range volumes {
+		name := v.Labels[ClusterLabel]
+		if saved[name] || (!opts.All && !provider.IsAnonymousVolume(v.Name)) {
+			continue
+		}
+		remove(PrunedResource{VolumeResource, v.Name, name}, func() error {
+			return client.DeleteVolume(ctx, v.Name)
+		})
+	}
+
+	return pruned, errors.Join(errs...)
+}
+
+// deleteContainer stops the container if it is running and deletes it
+func deleteContainer(ctx context.Context, client provider.Client, c provider.ContainerInfo) error {
+	if c.Status == provider.Running.String() {
+		if err := client.StopContainer(ctx, c.Name); err != nil {
+			return err
+		}
+	}
+	return client.DeleteContainer(ctx, c.Name)
+}
@@ -0,0 +1,154 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/file"
+	"github.com/stenh0use/hind/pkg/provider/fake"
+)
+
+// newPruneTestProvider returns a provider running the saved cluster dev
+// and the cluster lost, whose config is gone. lost has a named volume besides
+// the data volume of its Vault server.
+func newPruneTestProvider(t *testing.T) (*fake.Provider, *Manager) {
+	t.Helper()
+	p := fake.New()
+	ctx := context.Background()
+
+	dev := newFakeManager(t, "dev", p)
+	if _, err := dev.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	lost := newFakeManager(t, "lost", p)
+	node := &lost.Config().Nodes[0]
+	node.Volumes = append(node.Volumes, config.Volume{Name: "hind.lost.data", Destination: "/data"})
+	if _, err := lost.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := lost.fm.RemoveDir(file.JoinPath(ClusterConfigDir, "lost")); err != nil {
+		t.Fatalf("RemoveDir() error = %v", err)
+	}
+	return p, lost
+}
+
+func countPruned(pruned []PrunedResource) map[string]int {
+	counts := map[string]int{}
+	for _, r := range pruned {
+		counts[r.Kind]++
+	}
+	return counts
+}
+
+func TestPrune(t *testing.T) {
+	logger := &log.Logger{Handler: discard.New(), Level: log.ErrorLevel}
+	saved := map[string]bool{"dev": true}
+
+	tests := []struct {
+		name string
+		opts PruneOptions
+		// want is the number of resources of each kind pruned
+		want map[string]int
+		// remaining is the number of containers left
+		remaining int
+	}{
+		{
+			name: "dry run",
+			opts: PruneOptions{DryRun: true},
+			// Anonymous volumes are listed with their containers
+			want:      map[string]int{ContainerResource: 4, NetworkResource: 1, VolumeResource: 4},
+			remaining: 8,
+		},
+		{
+			name:      "orphaned resources",
+			want:      map[string]int{ContainerResource: 4, NetworkResource: 1},
+			remaining: 4,
+		},
+		{
+			name:      "all volumes",
+			opts:      PruneOptions{All: true},
+			want:      map[string]int{ContainerResource: 4, NetworkResource: 1, VolumeResource: 2},
+			remaining: 4,
+		},
+		{
+			name:      "saved cluster",
+			opts:      PruneOptions{Cluster: "dev", All: true},
+			want:      map[string]int{},
+			remaining: 8,
+		},
+		{
+			name:      "other version",
+			opts:      PruneOptions{Version: "0.0.1"},
+			want:      map[string]int{},
+			remaining: 8,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, lost := newPruneTestProvider(t)
+			ctx := context.Background()
+
+			pruned, err := prune(ctx, logger, p, saved, tt.opts)
+			if err != nil {
+				t.Fatalf("prune() error = %v", err)
+			}
+
+			got := countPruned(pruned)
+			for _, kind := range []string{ContainerResource, NetworkResource, VolumeResource} {
+				if got[kind] != tt.want[kind] {
+					t.Errorf("prune() %ss = %d, want %d", kind, got[kind], tt.want[kind])
+				}
+			}
+			for _, r := range pruned {
+				if r.Cluster != lost.Config().Name {
+					t.Errorf("prune() removed %s %s of cluster %s", r.Kind, r.Name, r.Cluster)
+				}
+			}
+
+			remaining, _ := p.ListContainers(ctx, nil)
+			if len(remaining) != tt.remaining {
+				t.Errorf("%d containers remaining, want %d", len(remaining), tt.remaining)
+			}
+		})
+	}
+}
+
+func TestPrune_ContinuesPastFailures(t *testing.T) {
+	logger := &log.Logger{Handler: discard.New(), Level: log.ErrorLevel}
+	p, lost := newPruneTestProvider(t)
+	failing := lost.Config().Nodes[0].Name
+	p.Fail(fake.Failure{Method: fake.MethodDeleteContainer, Name: failing})
+
+	pruned, err := prune(context.Background(), logger, p, map[string]bool{"dev": true}, PruneOptions{})
+	if err == nil {
+		t.Fatal("prune() error = nil, want the failed delete")
+	}
+	if got := countPruned(pruned)[ContainerResource]; got != 3 {
+		t.Errorf("prune() removed %d containers, want the 3 that didn't fail", got)
+	}
+}
+
+func TestManager_Delete_LabeledContainers(t *testing.T) {
+	p := fake.New()
+	m := newFakeManager(t, "dev", p)
+	ctx := context.Background()
+
+	if _, err := m.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// A container the config no longer lists, eg. after a scale down
+	m.config.Nodes = m.config.Nodes[1:]
+	if err := m.Delete(ctx, DeleteOptions{}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if remaining, _ := p.ListContainers(ctx, nil); len(remaining) != 0 {
+		t.Errorf("Delete() left %d containers, want 0", len(remaining))
+	}
+}
@@ -0,0 +1,323 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/stenh0use/hind/pkg/config"
+)
+
+// Readiness check timeouts, counted from when the containers are running
+const (
+	DefaultConsulReadyTimeout  = 2 * time.Minute
+	DefaultNomadReadyTimeout   = 2 * time.Minute
+	DefaultVaultReadyTimeout   = 2 * time.Minute
+	DefaultVaultUnsealTimeout  = 2 * time.Minute
+	DefaultACLBootstrapTimeout = 2 * time.Minute
+)
+
+// readinessPollInterval is how often a check that isn't ready is retried.
+// Replaced in tests.
+var readinessPollInterval = 2 * time.Second
+
+// readinessClient queries the service APIs published by the cluster,
+// see apiClient for clusters with TLS enabled
+var readinessClient = &http.Client{Timeout: 5 * time.Second}
+
+// ReadinessCheck waits for a service of the cluster to be ready
+type ReadinessCheck struct {
+	Name    string
+	Timeout time.Duration
+	// Ready returns nil once the service is ready, or why it isn't yet
+	Ready func(ctx context.Context) error
+}
+
+// clusterSetupSteps returns the steps every start runs once the containers
+// are running, whether or not it waits for the services to be ready: with
+// ACLs enabled the Consul and Nomad ACLs are bootstrapped, and Vault is
+// unsealed once initialised. The steps are safe to repeat, a start after an
+// interrupted one completes them.
+func (m *Manager) clusterSetupSteps() []ReadinessCheck {
+	var steps []ReadinessCheck
+
+	if m.config.ACL && m.countNodes(config.ConsulNode, config.Server) > 0 {
+		steps = append(steps, ReadinessCheck{
+			Name:    "consul acl",
+			Timeout: DefaultACLBootstrapTimeout,
+			Ready:   m.bootstrapConsulACL,
+		})
+	}
+	if m.config.ACL && m.countNodes(config.NomadNode, config.Server) > 0 {
+		steps = append(steps, ReadinessCheck{
+			Name:    "nomad acl",
+			Timeout: DefaultACLBootstrapTimeout,
+			Ready:   m.bootstrapNomadACL,
+		})
+	}
+	if m.countNodes(config.VaultNode, config.Server) > 0 {
+		steps = append(steps, ReadinessCheck{
+			Name:    "vault unseal",
+			Timeout: DefaultVaultUnsealTimeout,
+			Ready:   m.unsealVault,
+		})
+	}
+
+	return steps
+}
+
+// setupCluster runs the setup steps of the cluster, retrying each until it
+// succeeds or its timeout expires
+func (m *Manager) setupCluster(ctx context.Context) error {
+	if !m.setup {
+		return nil
+	}
+	if err := m.waitForReadiness(ctx, m.clusterSetupSteps()); err != nil {
+		return fmt.Errorf("cluster setup did not complete: %w", err)
+	}
+	return nil
+}
+
+// clusterReadinessChecks returns the checks for the services the cluster runs:
+// Consul has a leader and every node's agent joined, Nomad has a leader and
+// every client is ready, and Vault is unsealed on every server. The setup
+// steps bootstrapped the ACLs and unsealed Vault before.
+func (m *Manager) clusterReadinessChecks(ctx context.Context) ([]ReadinessCheck, error) {
+	var checks []ReadinessCheck
+	client := m.apiClient()
+
+	endpoints, err := m.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	consul := m.endpointURL(endpoints, config.ConsulNode)
+	if consul != "" {
+		members := len(m.config.Nodes)
+		checks = append(checks, ReadinessCheck{
+			Name:    "consul",
+			Timeout: DefaultConsulReadyTimeout,
+			Ready: func(ctx context.Context) error {
+				return consulReady(ctx, client, consul, m.aclTokens().ConsulManagement, members)
+			},
+		})
+	}
+
+	if nomad := m.endpointURL(endpoints, config.NomadNode); nomad != "" {
+		clients := m.countNodes(config.NomadNode, config.Client)
+		checks = append(checks, ReadinessCheck{
+			Name:    "nomad",
+			Timeout: DefaultNomadReadyTimeout,
+			Ready: func(ctx context.Context) error {
+				return nomadReady(ctx, client, nomad, m.aclTokens().NomadManagement, clients)
+			},
+		})
+	}
+
+	if vault := m.endpointURL(endpoints, config.VaultNode); vault != "" {
+		servers := m.countNodes(config.VaultNode, config.Server)
+		checks = append(checks, ReadinessCheck{
+			Name:    "vault",
+			Timeout: DefaultVaultReadyTimeout,
+			Ready: func(ctx context.Context) error {
+				return vaultReady(ctx, client, vault, consul, m.aclTokens().ConsulManagement, servers)
+			},
+		})
+	}
+
+	return checks, nil
+}
+
+// waitForReadiness runs the readiness checks one after the other, each
+// within its own timeout, and returns the first that fails.
+func (m *Manager) waitForReadiness(ctx context.Context, checks []ReadinessCheck) error {
+	for _, check := range checks {
+		m.logger.Infof("Waiting for %s to be ready", check.Name)
+		if err := waitReady(ctx, check); err != nil {
+			return err
+		}
+		m.logger.Infof("%s is ready", check.Name)
+	}
+	return nil
+}
+
+// waitReady polls a check until it is ready or its timeout expires
+func waitReady(ctx context.Context, check ReadinessCheck) error {
+	ctx, cancel := context.WithTimeout(ctx, check.Timeout)
+	defer cancel()
+
+	for {
+		err := check.Ready(ctx)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s readiness check failed after %s: %w", check.Name, check.Timeout, err)
+		case <-time.After(readinessPollInterval):
+		}
+	}
+}
+
+// tokenHeader returns the header authenticating a request with an ACL
+// token, nil without a token
+func tokenHeader(name, token string) http.Header {
+	if token == "" {
+		return nil
+	}
+	return http.Header{name: []string{token}}
+}
+
+// endpointURL returns the published API address of a service among the
+// endpoints of the cluster, empty if the cluster doesn't run it or doesn't
+// publish its port
+func (m *Manager) endpointURL(endpoints []Endpoint, kind config.Kind) string {
+	for _, svc := range endpointServices {
+		if svc.kind != kind {
+			continue
+		}
+		for _, e := range endpoints {
+			if e.Name == svc.name {
+				return e.URL
+			}
+		}
+		if m.countNodes(kind, "") > 0 {
+			m.logger.Warnf("Skipping %s readiness check, its API port %d is not published", kind, svc.port)
+		}
+	}
+	return ""
+}
+
+// countNodes counts the nodes of a kind with a role, or any role if empty
+func (m *Manager) countNodes(kind config.Kind, role config.Role) int {
+	count := 0
+	for _, node := range m.config.Nodes {
+		if node.Kind == kind && (role == "" || node.Role == role) {
+			count++
+		}
+	}
+	return count
+}
+
+// consulReady checks that Consul elected a leader and that at least members
+// agents are alive. The token is needed to list the members with ACLs.
+func consulReady(ctx context.Context, client *http.Client, url, token string, members int) error {
+	header := tokenHeader("X-Consul-Token", token)
+
+	var leader string
+	if err := getJSON(ctx, client, url+"/v1/status/leader", header, &leader); err != nil {
+		return err
+	}
+	if leader == "" {
+		return fmt.Errorf("no cluster leader")
+	}
+
+	var agents []struct {
+		Name   string
+		Status int
+	}
+	if err := getJSON(ctx, client, url+"/v1/agent/members", header, &agents); err != nil {
+		return err
+	}
+	// Serf status 1 is alive
+	alive := 0
+	for _, a := range agents {
+		if a.Status == 1 {
+			alive++
+		}
+	}
+	if alive < members {
+		return fmt.Errorf("%d of %d members alive", alive, members)
+	}
+	return nil
+}
+
+// nomadReady checks that Nomad elected a leader and that at least clients
+// nodes are ready. The token is needed to list the nodes with ACLs.
+func nomadReady(ctx context.Context, client *http.Client, url, token string, clients int) error {
+	header := tokenHeader("X-Nomad-Token", token)
+
+	var leader string
+	if err := getJSON(ctx, client, url+"/v1/status/leader", header, &leader); err != nil {
+		return err
+	}
+	if leader == "" {
+		return fmt.Errorf("no cluster leader")
+	}
+
+	var nodes []struct {
+		Name   string
+		Status string
+	}
+	if err := getJSON(ctx, client, url+"/v1/nodes", header, &nodes); err != nil {
+		return err
+	}
+	ready := 0
+	for _, n := range nodes {
+		if n.Status == "ready" {
+			ready++
+		}
+	}
+	if ready < clients {
+		return fmt.Errorf("%d of %d clients ready", ready, clients)
+	}
+	return nil
+}
+
+// vaultReady checks that the published Vault server is initialized and
+// unsealed. With more servers, every one of them has to pass the sealed
+// status check Vault registers in Consul, listed with the Consul token.
+func vaultReady(ctx context.Context, client *http.Client, url, consulURL, consulToken string, servers int) error {
+	var status struct {
+		Initialized bool `json:"initialized"`
+		Sealed      bool `json:"sealed"`
+	}
+	if err := getJSON(ctx, client, url+"/v1/sys/seal-status", nil, &status); err != nil {
+		return err
+	}
+	if !status.Initialized {
+		return fmt.Errorf("not initialized")
+	}
+	if status.Sealed {
+		return fmt.Errorf("sealed")
+	}
+
+	if servers <= 1 || consulURL == "" {
+		return nil
+	}
+	var passing []json.RawMessage
+	if err := getJSON(ctx, client, consulURL+"/v1/health/service/vault?passing=true", tokenHeader("X-Consul-Token", consulToken), &passing); err != nil {
+		return err
+	}
+	if len(passing) < servers {
+		return fmt.Errorf("%d of %d servers unsealed", len(passing), servers)
+	}
+	return nil
+}
+
+// getJSON decodes the JSON response of a GET request into out
+func getJSON(ctx context.Context, client *http.Client, url string, header http.Header, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	maps.Copy(req.Header, header)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %s", strings.SplitN(url, "?", 2)[0], resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response from %s: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider/fake"
+)
+
+// newAPIServer serves a JSON response for each path
+func newAPIServer(t *testing.T, responses map[string]any) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+type member struct {
+	Name   string
+	Status int
+}
+
+type nomadNode struct {
+	Name   string
+	Status string
+}
+
+func TestConsulReady(t *testing.T) {
+	tests := []struct {
+		name    string
+		leader  string
+		members []member
+		wantErr string
+	}{
+		{"ready", "10.0.0.2:8300", []member{{"a", 1}, {"b", 1}}, ""},
+		{"no leader", "", []member{{"a", 1}, {"b", 1}}, "no cluster leader"},
+		{"member not alive", "10.0.0.2:8300", []member{{"a", 1}, {"b", 4}}, "1 of 2 members alive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := newAPIServer(t, map[string]any{
+				"/v1/status/leader": tt.leader,
+				"/v1/agent/members": tt.members,
+			})
+			err := consulReady(context.Background(), readinessClient, url, "", 2)
+			checkReadyErr(t, "consulReady", err, tt.wantErr)
+		})
+	}
+}
+
+func TestConsulReady_Token(t *testing.T) {
+	// With ACLs, members the token can't read are filtered out
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/status/leader":
+			json.NewEncoder(w).Encode("10.0.0.2:8300")
+		case "/v1/agent/members":
+			members := []member{}
+			if r.Header.Get("X-Consul-Token") == "secret" {
+				members = append(members, member{"a", 1})
+			}
+			json.NewEncoder(w).Encode(members)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	checkReadyErr(t, "consulReady", consulReady(context.Background(), readinessClient, srv.URL, "", 1), "0 of 1 members alive")
+	checkReadyErr(t, "consulReady", consulReady(context.Background(), readinessClient, srv.URL, "secret", 1), "")
+}
+
+func TestNomadReady(t *testing.T) {
+	tests := []struct {
+		name    string
+		nodes   []nomadNode
+		wantErr string
+	}{
+		{"ready", []nomadNode{{"c1", "ready"}, {"c2", "ready"}}, ""},
+		{"client initializing", []nomadNode{{"c1", "ready"}, {"c2", "initializing"}}, "1 of 2 clients ready"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := newAPIServer(t, map[string]any{
+				"/v1/status/leader": "10.0.0.3:4647",
+				"/v1/nodes":         tt.nodes,
+			})
+			err := nomadReady(context.Background(), readinessClient, url, "", 2)
+			checkReadyErr(t, "nomadReady", err, tt.wantErr)
+		})
+	}
+}
+
+func TestVaultReady(t *testing.T) {
+	type sealStatus struct {
+		Initialized bool `json:"initialized"`
+		Sealed      bool `json:"sealed"`
+	}
+
+	tests := []struct {
+		name    string
+		status  sealStatus
+		servers int
+		passing int
+		wantErr string
+	}{
+		{"unsealed", sealStatus{true, false}, 1, 0, ""},
+		{"sealed", sealStatus{true, true}, 1, 0, "sealed"},
+		{"not initialized", sealStatus{false, true}, 1, 0, "not initialized"},
+		{"all servers unsealed", sealStatus{true, false}, 3, 3, ""},
+		{"standby sealed", sealStatus{true, false}, 3, 2, "2 of 3 servers unsealed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vault := newAPIServer(t, map[string]any{"/v1/sys/seal-status": tt.status})
+			consul := newAPIServer(t, map[string]any{"/v1/health/service/vault": make([]struct{}, tt.passing)})
+
+			err := vaultReady(context.Background(), readinessClient, vault, consul, "", tt.servers)
+			checkReadyErr(t, "vaultReady", err, tt.wantErr)
+		})
+	}
+}
+
+func checkReadyErr(t *testing.T, fn string, err error, want string) {
+	t.Helper()
+	if want == "" {
+		if err != nil {
+			t.Errorf("%s() error = %v, want nil", fn, err)
+		}
+		return
+	}
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("%s() error = %v, want %q", fn, err, want)
+	}
+}
+
+func TestWaitForReadiness_ReportsFailedCheck(t *testing.T) {
+	orig := readinessPollInterval
+	readinessPollInterval = time.Millisecond
+	t.Cleanup(func() { readinessPollInterval = orig })
+
+	m := &Manager{logger: &log.Logger{Handler: discard.New(), Level: log.ErrorLevel}}
+
+	calls := 0
+	checks := []ReadinessCheck{
+		{Name: "consul", Timeout: time.Second, Ready: func(ctx context.Context) error {
+			if calls++; calls < 3 {
+				return errors.New("no cluster leader")
+			}
+			return nil
+		}},
+		{Name: "nomad", Timeout: 20 * time.Millisecond, Ready: func(ctx context.Context) error {
+			return errors.New("0 of 2 clients ready")
+		}},
+		{Name: "vault", Timeout: time.Second, Ready: func(ctx context.Context) error {
+			t.Error("vault checked after nomad failed")
+			return nil
+		}},
+	}
+
+	err := m.waitForReadiness(context.Background(), checks)
+	if err == nil || !strings.Contains(err.Error(), "nomad readiness check failed") ||
+		!strings.Contains(err.Error(), "0 of 2 clients ready") {
+		t.Errorf("waitForReadiness() error = %v, want the nomad check and its reason", err)
+	}
+	if calls != 3 {
+		t.Errorf("consul check ran %d times, want 3", calls)
+	}
+}
+
+func TestClusterReadinessChecks(t *testing.T) {
+	m := &Manager{
+		logger:   &log.Logger{Handler: discard.New(), Level: log.ErrorLevel},
+		provider: fake.New(),
+		config: &config.Cluster{
+			Nodes: []config.Node{
+				{Name: "hind.dev.consul.01", Kind: config.ConsulNode, Role: config.Server, Ports: []config.PortMapping{{ContainerPort: 8500, HostPort: 8500}}},
+				{Name: "hind.dev.nomad.01", Kind: config.NomadNode, Role: config.Server, Ports: []config.PortMapping{{ContainerPort: 4646, HostPort: 4646}}},
+				{Kind: config.NomadNode, Role: config.Client},
+				// Vault isn't published, so it can only be unsealed
+				{Kind: config.VaultNode, Role: config.Server},
+			},
+		},
+	}
+
+	checks, err := m.clusterReadinessChecks(context.Background())
+	if err != nil {
+		t.Fatalf("clusterReadinessChecks() error = %v", err)
+	}
+	if got, want := checkNames(checks), "consul,nomad"; got != want {
+		t.Errorf("clusterReadinessChecks() = %s, want %s", got, want)
+	}
+	if got, want := checkNames(m.clusterSetupSteps()), "vault unseal"; got != want {
+		t.Errorf("clusterSetupSteps() = %s, want %s", got, want)
+	}
+
+	// With ACLs they are bootstrapped by the setup steps, the readiness
+	// checks stay the same
+	m.config.ACL = true
+	if got, want := checkNames(m.clusterSetupSteps()), "consul acl,nomad acl,vault unseal"; got != want {
+		t.Errorf("clusterSetupSteps() = %s, want %s", got, want)
+	}
+	if checks, err = m.clusterReadinessChecks(context.Background()); err != nil {
+		t.Fatalf("clusterReadinessChecks() error = %v", err)
+	}
+	if got, want := checkNames(checks), "consul,nomad"; got != want {
+		t.Errorf("clusterReadinessChecks() = %s, want %s", got, want)
+	}
+}
+
+// checkNames joins the names of the checks
+func checkNames(checks []ReadinessCheck) string {
+	var names []string
+	for _, c := range checks {
+		names = append(names, c.Name)
+	}
+	return strings.Join(names, ",")
+}
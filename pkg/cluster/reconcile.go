@@ -3,6 +3,7 @@ package cluster
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/stenh0use/hind/pkg/config"
 	"github.com/stenh0use/hind/pkg/provider"
@@ -10,17 +11,18 @@ import (
 
 // ReconcilePlan represents the difference between desired and actual state
 type ReconcilePlan struct {
-	NetworkToCreate      *config.Network
-	ContainersToCreate   []config.Node
-	ContainersToStart    []string
-	ContainersToRecreate []RecreateAction
+	NetworkToCreate      *config.Network  `json:"networkToCreate"`
+	ContainersToCreate   []config.Node    `json:"containersToCreate"`
+	ContainersToStart    []string         `json:"containersToStart"`
+	ContainersToRecreate []RecreateAction `json:"containersToRecreate"`
 }
 
 // RecreateAction describes a container that needs to be recreated
 type RecreateAction struct {
-	ExistingName string
-	NewConfig    config.Node
-	Reason       string // "unhealthy", "config_mismatch", etc
+	ExistingName string      `json:"existingName"`
+	NewConfig    config.Node `json:"newConfig"`
+	Reason       string      `json:"reason"`            // "unhealthy", "config_mismatch", etc
+	Changes      []string    `json:"changes,omitempty"` // Fields that differ for "config_mismatch"
 }
 
 // ActualState represents the current state in Docker
@@ -42,21 +44,16 @@ func (p *ReconcilePlan) IsEmpty() bool {
 func (m *Manager) Reconcile(ctx context.Context) error {
 	m.logger.Debug("Starting reconciliation")
 
-	// 1. Get actual state from Docker
-	actual, err := m.getActualState(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get actual state: %w", err)
-	}
-
-	// 2. Calculate what needs to change
-	plan, err := m.calculateReconcilePlan(ctx, actual)
+	// 1-2. Get actual state from Docker and calculate what needs to change
+	plan, err := m.Plan(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to calculate reconcile plan: %w", err)
+		return err
 	}
 
 	if plan.IsEmpty() {
 		m.logger.Info("Cluster state matches desired configuration")
-		return nil
+		// A previous start may have stopped before the setup completed
+		return m.setupCluster(ctx)
 	}
 
 	m.logger.Infof("Reconciliation plan: create=%d, start=%d, recreate=%d",
@@ -64,26 +61,71 @@ func (m *Manager) Reconcile(ctx context.Context) error {
 		len(plan.ContainersToStart),
 		len(plan.ContainersToRecreate))
 
-	// 3. Execute plan
+	// 3. Make sure the images for the cluster's release are available
+	if err := m.checkImages(ctx, plan); err != nil {
+		return err
+	}
+
+	// 4. Issue the TLS certificates the containers mount
+	if err := m.ensureCertificates(); err != nil {
+		return fmt.Errorf("failed to issue tls certificates: %w", err)
+	}
+
+	// 5. Execute plan
 	if err := m.executeReconcilePlan(ctx, plan); err != nil {
 		return fmt.Errorf("failed to execute reconcile plan: %w", err)
 	}
 
-	// 4. Verify convergence
+	// 6. Verify convergence
 	m.logger.Debug("Waiting for containers to reach running state")
 	if err := m.waitForContainersRunning(ctx, DefaultContainerStartTimeout); err != nil {
 		return fmt.Errorf("cluster did not converge: %w", err)
 	}
 
-	// 5. Persist config only after successful reconciliation
+	// 7. Persist config only after successful reconciliation
 	if err := m.saveConfig(); err != nil {
 		return fmt.Errorf("failed to save config after reconciliation: %w", err)
 	}
 
+	// 8. Set up the services, eg. bootstrap the ACLs, whether or not start
+	// waits for them to be ready
+	if err := m.setupCluster(ctx); err != nil {
+		return err
+	}
+
+	// 9. Wait for the services to be ready, the containers running isn't enough
+	if m.readinessChecks {
+		checks, err := m.clusterReadinessChecks(ctx)
+		if err != nil {
+			return fmt.Errorf("cluster is not ready: %w", err)
+		}
+		if err := m.waitForReadiness(ctx, checks); err != nil {
+			return fmt.Errorf("cluster is not ready: %w", err)
+		}
+	}
+
+	// 10. Rejoin the federated clusters, recreated servers lost their networks
+	m.rejoinFederation(ctx)
+
 	m.logger.Info("Reconciliation completed successfully")
 	return nil
 }
 
+// Plan calculates the changes Reconcile would make to bring the actual state
+// in line with the in-memory config, without making them.
+func (m *Manager) Plan(ctx context.Context) (*ReconcilePlan, error) {
+	actual, err := m.getActualState(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get actual state: %w", err)
+	}
+
+	plan, err := m.calculateReconcilePlan(ctx, actual)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate reconcile plan: %w", err)
+	}
+	return plan, nil
+}
+
 // getActualState queries Docker for current cluster state
 func (m *Manager) getActualState(ctx context.Context) (*ActualState, error) {
 	state := &ActualState{
@@ -126,6 +168,9 @@ func (m *Manager) calculateReconcilePlan(ctx context.Context, actual *ActualStat
 		plan.NetworkToCreate = &m.config.Network
 	}
 
+	// Local image IDs by reference, looked up once per image
+	imageIDs := map[string]string{}
+
 	// Check each desired node
 	for _, desiredNode := range m.config.Nodes {
 		actualContainer := actual.Containers[desiredNode.Name]
@@ -133,90 +178,98 @@ func (m *Manager) calculateReconcilePlan(ctx context.Context, actual *ActualStat
 		if actualContainer == nil {
 			// Container doesn't exist - needs creation
 			plan.ContainersToCreate = append(plan.ContainersToCreate, desiredNode)
-		} else {
-			// Container exists - check state
-			switch actualContainer.Status {
-			case provider.Running.String():
-				// Running - assume good for now
-				// Future: add config drift detection here
-				continue
-
-			case provider.Error.String():
-				// Unhealthy - needs recreation
-				plan.ContainersToRecreate = append(plan.ContainersToRecreate, RecreateAction{
-					ExistingName: desiredNode.Name,
-					NewConfig:    desiredNode,
-					Reason:       "unhealthy",
-				})
-
-			default:
-				// Stopped - needs start
-				plan.ContainersToStart = append(plan.ContainersToStart, desiredNode.Name)
+			continue
+		}
+
+		// Unhealthy - needs recreation
+		if actualContainer.Status == provider.Error.String() {
+			plan.ContainersToRecreate = append(plan.ContainersToRecreate, RecreateAction{
+				ExistingName: desiredNode.Name,
+				NewConfig:    desiredNode,
+				Reason:       ReasonUnhealthy,
+			})
+			continue
+		}
+
+		// Drifted from the config - needs recreation, whether running or not
+		var drift []string
+		if actualContainer.Spec != nil {
+			desired := desiredNode
+			desired.Labels = m.nodeLabels(desiredNode)
+			desired.Volumes = m.nodeVolumes(desiredNode)
+			drift = configDrift(desired, *actualContainer.Spec)
+		}
+		if !slices.Contains(drift, "image") {
+			rebuilt, err := m.imageRebuilt(ctx, imageIDs, desiredNode.Image.Ref(), actualContainer.ImageID)
+			if err != nil {
+				return nil, err
 			}
+			if rebuilt {
+				drift = append([]string{"image"}, drift...)
+			}
+		}
+		if len(drift) > 0 {
+			plan.ContainersToRecreate = append(plan.ContainersToRecreate, RecreateAction{
+				ExistingName: desiredNode.Name,
+				NewConfig:    desiredNode,
+				Reason:       ReasonConfigMismatch,
+				Changes:      drift,
+			})
+			continue
+		}
+
+		// Stopped - needs start, running is already in the desired state
+		if actualContainer.Status != provider.Running.String() {
+			plan.ContainersToStart = append(plan.ContainersToStart, desiredNode.Name)
 		}
 	}
 
 	return plan, nil
 }
 
-// executeReconcilePlan executes infrastructure changes
-func (m *Manager) executeReconcilePlan(ctx context.Context, plan *ReconcilePlan) error {
-	labels := config.Labels{
-		"hind.cluster": m.config.Name,
-		"hind.version": m.config.Version,
+// imageRebuilt reports whether the local image for ref is no longer the
+// image a container was created from, eg. after the tag was rebuilt. It is
+// false when either ID is unknown or the image is no longer local.
+func (m *Manager) imageRebuilt(ctx context.Context, imageIDs map[string]string, ref, containerImageID string) (bool, error) {
+	if containerImageID == "" {
+		return false, nil
 	}
-
-	// Step 1: Create network if needed
-	if plan.NetworkToCreate != nil {
-		m.logger.Infof("Creating network '%s'", plan.NetworkToCreate.Name)
-		plan.NetworkToCreate.Labels = labels
-		id, err := m.provider.CreateNetwork(ctx, *plan.NetworkToCreate)
+	id, ok := imageIDs[ref]
+	if !ok {
+		var err error
+		id, err = m.provider.ImageID(ctx, ref)
 		if err != nil {
-			return fmt.Errorf("failed to create network: %w", err)
+			return false, fmt.Errorf("failed to inspect image '%s': %w", ref, err)
 		}
-		m.logger.Infof("Created network '%s' (id: %s)", plan.NetworkToCreate.Name, id)
+		imageIDs[ref] = id
 	}
+	return id != "" && id != containerImageID, nil
+}
 
-	// Step 2: Recreate unhealthy containers
+// checkImages verifies that the image of every container the plan creates
+// exists locally, so a missing build fails before anything is changed.
+func (m *Manager) checkImages(ctx context.Context, plan *ReconcilePlan) error {
+	nodes := slices.Clone(plan.ContainersToCreate)
 	for _, action := range plan.ContainersToRecreate {
-		m.logger.Infof("Recreating unhealthy container '%s'", action.ExistingName)
-
-		// Stop (ignore errors if already stopped)
-		_ = m.provider.StopContainer(ctx, action.ExistingName)
-
-		// Delete
-		if err := m.provider.DeleteContainer(ctx, action.ExistingName); err != nil {
-			return fmt.Errorf("failed to delete container '%s': %w", action.ExistingName, err)
-		}
+		nodes = append(nodes, action.NewConfig)
+	}
 
-		// Recreate
-		action.NewConfig.Labels = labels
-		id, err := m.provider.CreateContainer(ctx, action.NewConfig)
-		if err != nil {
-			return fmt.Errorf("failed to recreate container '%s': %w", action.ExistingName, err)
+	checked := map[string]bool{}
+	for _, node := range nodes {
+		ref := node.Image.Ref()
+		if checked[ref] {
+			continue
 		}
-		m.logger.Infof("Recreated container '%s' (id: %s)", action.ExistingName, id)
-	}
+		checked[ref] = true
 
-	// Step 3: Create new containers
-	for _, node := range plan.ContainersToCreate {
-		m.logger.Infof("Creating container '%s'", node.Name)
-		node.Labels = labels
-		id, err := m.provider.CreateContainer(ctx, node)
+		exists, err := m.provider.ImageExists(ctx, ref)
 		if err != nil {
-			return fmt.Errorf("failed to create container '%s': %w", node.Name, err)
+			return fmt.Errorf("failed to check image '%s': %w", ref, err)
 		}
-		m.logger.Infof("Created container '%s' (id: %s)", node.Name, id)
-	}
-
-	// Step 4: Start stopped containers
-	for _, name := range plan.ContainersToStart {
-		m.logger.Infof("Starting container '%s'", name)
-		if err := m.provider.StartContainer(ctx, name); err != nil {
-			return fmt.Errorf("failed to start container '%s': %w", name, err)
+		if !exists {
+			return fmt.Errorf("image '%s' for hind release %s has not been built\n"+
+				"Resolution: Run 'hind build all --version %s'", ref, m.config.Version, m.config.Version)
 		}
-		m.logger.Infof("Started container '%s'", name)
 	}
-
 	return nil
 }
@@ -2,10 +2,15 @@ package cluster
 
 import (
 	"context"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
 	"github.com/stenh0use/hind/pkg/config"
 	"github.com/stenh0use/hind/pkg/provider"
+	"github.com/stenh0use/hind/pkg/provider/fake"
 )
 
 func TestReconcilePlan_IsEmpty(t *testing.T) {
@@ -270,3 +275,223 @@ func TestCalculateReconcilePlan_MixedStates(t *testing.T) {
 		t.Error("NetworkToCreate should be nil when network exists")
 	}
 }
+
+func TestCalculateReconcilePlan_ConfigDrift(t *testing.T) {
+	image := config.Image{Name: "docker.io/stenh0use/hind.consul", Tag: "0.4.0"}
+	m := &Manager{
+		config: &config.Cluster{
+			Name:    "test",
+			Version: "0.4.0",
+			Network: config.Network{Name: "hind.test"},
+			Nodes: []config.Node{
+				{Name: "hind.test.consul.01", Kind: config.ConsulNode, Image: image},
+				{Name: "hind.test.consul.02", Kind: config.ConsulNode, Image: image},
+				{Name: "hind.test.consul.03", Kind: config.ConsulNode, Image: image},
+			},
+		},
+	}
+
+	labels := config.Labels{"hind.cluster": "test", "hind.version": "0.4.0"}
+	oldImage := config.Image{Name: image.Name, Tag: "0.3.0"}
+
+	actual := &ActualState{
+		Network: &provider.NetworkInfo{Name: "hind.test"},
+		Containers: map[string]*provider.ContainerInfo{
+			// Matches the config
+			"hind.test.consul.01": {
+				Name:   "hind.test.consul.01",
+				Status: provider.Running.String(),
+				Spec:   &config.Node{Image: image, Labels: labels},
+			},
+			// Running an image from an older release
+			"hind.test.consul.02": {
+				Name:   "hind.test.consul.02",
+				Status: provider.Running.String(),
+				Spec:   &config.Node{Image: oldImage, Labels: labels},
+			},
+			// Stopped with an old image, recreated rather than started
+			"hind.test.consul.03": {
+				Name:   "hind.test.consul.03",
+				Status: "exited",
+				Spec:   &config.Node{Image: oldImage, Labels: labels},
+			},
+		},
+	}
+
+	plan, err := m.calculateReconcilePlan(context.Background(), actual)
+	if err != nil {
+		t.Fatalf("calculateReconcilePlan() error = %v", err)
+	}
+
+	if len(plan.ContainersToStart) != 0 {
+		t.Errorf("ContainersToStart should be empty, got %v", plan.ContainersToStart)
+	}
+
+	if len(plan.ContainersToRecreate) != 2 {
+		t.Fatalf("ContainersToRecreate = %d, want 2", len(plan.ContainersToRecreate))
+	}
+
+	for i, want := range []string{"hind.test.consul.02", "hind.test.consul.03"} {
+		action := plan.ContainersToRecreate[i]
+		if action.ExistingName != want {
+			t.Errorf("ContainersToRecreate[%d] = %s, want %s", i, action.ExistingName, want)
+		}
+		if action.Reason != ReasonConfigMismatch {
+			t.Errorf("Recreate reason = %s, want '%s'", action.Reason, ReasonConfigMismatch)
+		}
+		if len(action.Changes) != 1 || action.Changes[0] != "image" {
+			t.Errorf("Recreate changes = %v, want [image]", action.Changes)
+		}
+	}
+}
+
+func TestManager_Plan_RebuiltImage(t *testing.T) {
+	p := fake.New()
+	m := newFakeManager(t, "test", p)
+	ctx := context.Background()
+	if _, err := m.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// Rebuilding the tag the Consul servers run leaves them on the old image
+	consul := m.nodesOf(config.ConsulNode, config.Server)
+	p.BuildImage(consul[0].Image.Ref())
+
+	plan, err := m.Plan(ctx)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(plan.ContainersToRecreate) != len(consul) {
+		t.Fatalf("ContainersToRecreate = %+v, want the %d Consul servers", plan.ContainersToRecreate, len(consul))
+	}
+	for _, action := range plan.ContainersToRecreate {
+		if action.NewConfig.Kind != config.ConsulNode || !slices.Equal(action.Changes, []string{"image"}) {
+			t.Errorf("recreate %s changes = %v, want a Consul server with [image]", action.ExistingName, action.Changes)
+		}
+	}
+
+	// Recreated containers run the new build
+	if _, err := m.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if plan, err := m.Plan(ctx); err != nil || !plan.IsEmpty() {
+		t.Errorf("Plan() after recreate = %+v, %v, want empty", plan, err)
+	}
+}
+
+// imageProvider reports the images in built as existing locally
+type imageProvider struct {
+	provider.Client
+	built   map[string]bool
+	checked []string
+}
+
+func (p *imageProvider) ImageExists(ctx context.Context, ref string) (bool, error) {
+	p.checked = append(p.checked, ref)
+	return p.built[ref], nil
+}
+
+func TestCheckImages(t *testing.T) {
+	consul := config.Image{Name: "docker.io/stenh0use/hind.consul", Tag: "0.3.0"}
+	nomad := config.Image{Name: "docker.io/stenh0use/hind.nomad", Tag: "0.3.0"}
+
+	tests := []struct {
+		name        string
+		plan        *ReconcilePlan
+		built       map[string]bool
+		wantChecked int
+		wantErr     bool
+	}{
+		{
+			name: "all images built",
+			plan: &ReconcilePlan{
+				ContainersToCreate: []config.Node{
+					{Name: "hind.test.consul.01", Image: consul},
+					{Name: "hind.test.consul.02", Image: consul},
+				},
+				ContainersToRecreate: []RecreateAction{
+					{ExistingName: "hind.test.nomad.01", NewConfig: config.Node{Image: nomad}},
+				},
+			},
+			built:       map[string]bool{consul.Ref(): true, nomad.Ref(): true},
+			wantChecked: 2,
+		},
+		{
+			name: "missing image for recreated container",
+			plan: &ReconcilePlan{
+				ContainersToRecreate: []RecreateAction{
+					{ExistingName: "hind.test.nomad.01", NewConfig: config.Node{Image: nomad}},
+				},
+			},
+			built:       map[string]bool{consul.Ref(): true},
+			wantChecked: 1,
+			wantErr:     true,
+		},
+		{
+			name:        "nothing to create",
+			plan:        &ReconcilePlan{ContainersToStart: []string{"hind.test.consul.01"}},
+			wantChecked: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &imageProvider{built: tt.built}
+			m := &Manager{
+				provider: p,
+				config:   &config.Cluster{Name: "test", Version: "0.3.0"},
+			}
+
+			err := m.checkImages(context.Background(), tt.plan)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkImages() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "hind build all --version 0.3.0") {
+				t.Errorf("checkImages() error = %v, want build resolution", err)
+			}
+			if len(p.checked) != tt.wantChecked {
+				t.Errorf("checkImages() checked %v, want %d images", p.checked, tt.wantChecked)
+			}
+		})
+	}
+}
+
+// volumeProvider records the volumes deleted from a stub volume list
+type volumeProvider struct {
+	provider.Client
+	volumes []provider.VolumeInfo
+	filters []string
+	deleted []string
+}
+
+func (p *volumeProvider) ListVolumes(ctx context.Context, filters []string) ([]provider.VolumeInfo, error) {
+	p.filters = filters
+	return p.volumes, nil
+}
+
+func (p *volumeProvider) DeleteVolume(ctx context.Context, name string) error {
+	p.deleted = append(p.deleted, name)
+	return nil
+}
+
+func TestDeleteVolumes(t *testing.T) {
+	p := &volumeProvider{
+		volumes: []provider.VolumeInfo{{Name: "hind.test.consul-data"}, {Name: "hind.test.nomad-data"}},
+	}
+	m := &Manager{
+		logger:   &log.Logger{Handler: discard.New(), Level: log.ErrorLevel},
+		provider: p,
+		config:   &config.Cluster{Name: "test"},
+	}
+
+	if err := m.deleteVolumes(context.Background()); err != nil {
+		t.Fatalf("deleteVolumes() error = %v", err)
+	}
+
+	if len(p.filters) != 1 || p.filters[0] != "label=hind.cluster=test" {
+		t.Errorf("ListVolumes() filters = %v, want [label=hind.cluster=test]", p.filters)
+	}
+	if len(p.deleted) != 2 {
+		t.Errorf("deleted volumes = %v, want both cluster volumes", p.deleted)
+	}
+}
@@ -0,0 +1,48 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/stenh0use/hind/pkg/file"
+)
+
+// secretPermissions restricts the files holding the secrets of a cluster,
+// eg. its Vault credentials and ACL tokens, to their owner
+const secretPermissions = 0600
+
+// clusterFile returns the path of a file in the cluster directory
+func (m *Manager) clusterFile(name string) string {
+	return file.JoinPath(m.fm.GetRootDir(), ClusterConfigDir, m.config.Name, name)
+}
+
+// readSecrets decodes a secrets file of the cluster directory into v.
+// Returns false if the file doesn't exist.
+func (m *Manager) readSecrets(name string, v any) (bool, error) {
+	path := m.clusterFile(name)
+	if !m.fm.FileExists(path) {
+		return false, nil
+	}
+
+	data, err := m.fm.ReadFile(path)
+	if err != nil {
+		return true, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return true, fmt.Errorf("failed to unmarshal %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// writeSecrets stores v in a secrets file of the cluster directory,
+// readable by the owner only
+func (m *Manager) writeSecrets(name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+	if err := m.fm.WriteFileMode(m.clusterFile(name), data, secretPermissions); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,351 @@
+package cluster
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/file"
+)
+
+// TLSDir is the directory in the cluster directory holding the CA of a
+// cluster with TLS enabled, the certificate of the CLIs on the host and a
+// directory with the certificates of each node
+const TLSDir = "tls"
+
+// Files of the TLS directory, the keys are readable by the owner only
+const (
+	CACertFile  = "ca.pem"
+	caKeyFile   = "ca-key.pem"
+	CLICertFile = "cli.pem"
+	CLIKeyFile  = "cli-key.pem"
+	certFile    = "cert.pem"
+	keyFile     = "key.pem"
+)
+
+// nodeTLSDir is where the directory with the certificates of a node is
+// mounted in its container. The entrypoints copy them for the agents.
+const nodeTLSDir = "/etc/hind/tls"
+
+// Validity of the generated certificates. Expired certificates are issued
+// again when the cluster starts.
+const (
+	caValidity   = 10 * 365 * 24 * time.Hour
+	certValidity = 365 * 24 * time.Hour
+)
+
+// certAuthority is the CA issuing the certificates of a cluster
+type certAuthority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+// CACertFile returns the path of the CA certificate of the cluster on the
+// host, it exists once the cluster was started with TLS enabled
+func (m *Manager) CACertFile() string {
+	return m.tlsFile(CACertFile)
+}
+
+// tlsFile returns the path of a file in the TLS directory of the cluster
+func (m *Manager) tlsFile(elem ...string) string {
+	return m.clusterFile(file.JoinPath(append([]string{TLSDir}, elem...)...))
+}
+
+// apiScheme returns the scheme of the agent HTTP APIs
+func (m *Manager) apiScheme() string {
+	if m.config.TLS {
+		return "https"
+	}
+	return "http"
+}
+
+// ensureCertificates generates the CA of the cluster and issues the
+// certificates of the CLIs and every node that are missing, expired or
+// don't match the names of the node anymore. The CA and valid
+// certificates are kept across restarts.
+func (m *Manager) ensureCertificates() error {
+	if !m.config.TLS {
+		return nil
+	}
+
+	ca, err := m.certAuthority()
+	if err != nil {
+		return err
+	}
+
+	if err := m.ensureCertificate(ca, m.tlsFile(CLICertFile), m.tlsFile(CLIKeyFile), "hind cli", nil, x509.ExtKeyUsageClientAuth); err != nil {
+		return err
+	}
+
+	for _, n := range m.config.Nodes {
+		if err := m.fm.WriteFile(m.tlsFile(n.Name, CACertFile), ca.pem); err != nil {
+			return fmt.Errorf("failed to write the CA certificate of '%s': %w", n.Name, err)
+		}
+		err := m.ensureCertificate(ca, m.tlsFile(n.Name, certFile), m.tlsFile(n.Name, keyFile), n.Name, nodeCertNames(m.config, n),
+			x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// certAuthority loads the CA of the cluster, or generates it on first use
+func (m *Manager) certAuthority() (*certAuthority, error) {
+	certPath, keyPath := m.tlsFile(CACertFile), m.tlsFile(caKeyFile)
+	if m.fm.FileExists(certPath) && m.fm.FileExists(keyPath) {
+		return m.loadCertAuthority(certPath, keyPath)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate CA key: %w", err)
+	}
+	template, err := certTemplate(fmt.Sprintf("hind %s CA", m.config.Name), caValidity)
+	if err != nil {
+		return nil, err
+	}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CA certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA certificate: %w", err)
+	}
+	ca := &certAuthority{cert: cert, key: key, pem: encodePEM("CERTIFICATE", der)}
+
+	if err := m.writeKeyPair(certPath, keyPath, ca.pem, key); err != nil {
+		return nil, err
+	}
+	m.logger.Infof("Generated the CA of cluster '%s'", m.config.Name)
+	return ca, nil
+}
+
+// loadCertAuthority reads the CA certificate and key
+func (m *Manager) loadCertAuthority(certPath, keyPath string) (*certAuthority, error) {
+	certPEM, err := m.fm.ReadFile(certPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+	cert, err := parseCertificate(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate %s: %w", certPath, err)
+	}
+
+	keyPEM, err := m.fm.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA key: %w", err)
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("failed to read CA key %s: no PEM data", keyPath)
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA key %s: %w", keyPath, err)
+	}
+	return &certAuthority{cert: cert, key: key, pem: certPEM}, nil
+}
+
+// ensureCertificate issues a certificate for the names when the one at
+// certPath isn't valid for them
+func (m *Manager) ensureCertificate(ca *certAuthority, certPath, keyPath, commonName string, names []string, usages ...x509.ExtKeyUsage) error {
+	if m.fm.FileExists(keyPath) && m.certificateValid(ca, certPath, names, usages) {
+		return nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate key for %s: %w", commonName, err)
+	}
+	template, err := certTemplate(commonName, certValidity)
+	if err != nil {
+		return err
+	}
+	template.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	template.ExtKeyUsage = usages
+	for _, name := range names {
+		if ip := net.ParseIP(name); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, name)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return fmt.Errorf("failed to create certificate for %s: %w", commonName, err)
+	}
+	if err := m.writeKeyPair(certPath, keyPath, encodePEM("CERTIFICATE", der), key); err != nil {
+		return err
+	}
+	m.logger.Debugf("Issued certificate for %s", commonName)
+	return nil
+}
+
+// certificateValid reports whether the certificate at path was issued by
+// the CA, hasn't expired and is valid for every name
+func (m *Manager) certificateValid(ca *certAuthority, path string, names []string, usages []x509.ExtKeyUsage) bool {
+	if !m.fm.FileExists(path) {
+		return false
+	}
+	data, err := m.fm.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	cert, err := parseCertificate(data)
+	if err != nil {
+		return false
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	if _, err := cert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: usages}); err != nil {
+		return false
+	}
+	for _, name := range names {
+		if cert.VerifyHostname(name) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// writeKeyPair writes a certificate and its key, readable by the owner only
+func (m *Manager) writeKeyPair(certPath, keyPath string, certPEM []byte, key *ecdsa.PrivateKey) error {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("failed to marshal key: %w", err)
+	}
+	if err := m.fm.WriteFileMode(keyPath, encodePEM("EC PRIVATE KEY", der), secretPermissions); err != nil {
+		return fmt.Errorf("failed to write key: %w", err)
+	}
+	if err := m.fm.WriteFile(certPath, certPEM); err != nil {
+		return fmt.Errorf("failed to write certificate: %w", err)
+	}
+	return nil
+}
+
+// nodeCertNames returns the names the certificate of a node is valid for:
+// its hostname and the loopback addresses the agents in the node use, and
+// the names Consul and Nomad verify for RPC in the cluster's datacenter and
+// region
+func nodeCertNames(cfg *config.Cluster, node config.Node) []string {
+	names := []string{node.Name, "localhost", "127.0.0.1", "::1"}
+	switch {
+	case node.Kind == config.ConsulNode && node.Role == config.Server:
+		names = append(names, "server."+Datacenter(cfg)+".consul", "consul.service.consul")
+	case node.Kind == config.NomadNode && node.Role == config.Server:
+		names = append(names, "server."+Region(cfg)+".nomad", "nomad.service.consul")
+	case node.Kind == config.NomadNode:
+		names = append(names, "client."+Region(cfg)+".nomad")
+	case node.Kind == config.VaultNode:
+		names = append(names, "vault.service.consul")
+	}
+	return names
+}
+
+// certTemplate returns a certificate template with a random serial number
+func certTemplate(commonName string, validity time.Duration) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"hind"}},
+		// Allow for clock skew between the host and the containers
+		NotBefore: now.Add(-time.Hour),
+		NotAfter:  now.Add(validity),
+	}, nil
+}
+
+func encodePEM(blockType string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+}
+
+// parseCertificate parses the first certificate of PEM data
+func parseCertificate(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("no PEM certificate")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// nodeVolumes returns the volumes of a node's container: the ones of its
+// config, the data volume of Vault servers and, with TLS enabled, its
+// certificates mounted read only
+func (m *Manager) nodeVolumes(node config.Node) []config.Volume {
+	volumes := node.Volumes
+	if v, ok := vaultDataVolume(node); ok {
+		volumes = append(slices.Clone(volumes), v)
+	}
+	if !m.config.TLS {
+		return volumes
+	}
+	return append(slices.Clone(volumes), config.Volume{
+		Type:        config.BindMount,
+		Source:      m.tlsFile(node.Name),
+		Destination: nodeTLSDir,
+		ReadOnly:    true,
+	})
+}
+
+// certEnvironment returns the variables pointing the CLIs to the CA
+// certificate and a client certificate in dir, empty without TLS
+func (m *Manager) certEnvironment(dir, cert, key string, kinds ...config.Kind) map[string]string {
+	env := map[string]string{}
+	if !m.config.TLS {
+		return env
+	}
+	for _, kind := range kinds {
+		prefix := strings.ToUpper(kind.String())
+		env[prefix+"_CACERT"] = file.JoinPath(dir, CACertFile)
+		env[prefix+"_CLIENT_CERT"] = file.JoinPath(dir, cert)
+		env[prefix+"_CLIENT_KEY"] = file.JoinPath(dir, key)
+	}
+	return env
+}
+
+// nodeCertEnvironment returns the variables pointing the CLIs in a node to
+// the certificates mounted in it, empty without TLS
+func (m *Manager) nodeCertEnvironment() map[string]string {
+	return m.certEnvironment(nodeTLSDir, certFile, keyFile, config.ConsulNode, config.NomadNode, config.VaultNode)
+}
+
+// apiClient returns the client querying the published APIs of the cluster,
+// trusting the CA of the cluster with TLS enabled
+func (m *Manager) apiClient() *http.Client {
+	if !m.config.TLS {
+		return readinessClient
+	}
+
+	roots := x509.NewCertPool()
+	if data, err := m.fm.ReadFile(m.CACertFile()); err != nil || !roots.AppendCertsFromPEM(data) {
+		m.logger.Warnf("Failed to read the CA certificate %s: %v", m.CACertFile(), err)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+	return &http.Client{Timeout: readinessClient.Timeout, Transport: transport}
+}
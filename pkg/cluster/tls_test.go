@@ -0,0 +1,175 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider/fake"
+)
+
+func TestNodeCertNames(t *testing.T) {
+	tests := []struct {
+		kind config.Kind
+		role config.Role
+		want string
+	}{
+		{config.ConsulNode, config.Server, "server.local.consul"},
+		{config.NomadNode, config.Server, "server.global.nomad"},
+		{config.NomadNode, config.Client, "client.global.nomad"},
+		{config.VaultNode, config.Server, "vault.service.consul"},
+	}
+
+	for _, tt := range tests {
+		node := newNode("dev", tt.kind, tt.role, 1, "v1")
+		names := nodeCertNames(&config.Cluster{}, node)
+		for _, want := range []string{node.Name, "localhost", "127.0.0.1", tt.want} {
+			if !slices.Contains(names, want) {
+				t.Errorf("nodeCertNames(%s) = %v, want %s", node.Name, names, want)
+			}
+		}
+	}
+
+	// The server names follow the datacenter and region of the cluster
+	cfg := &config.Cluster{Datacenter: "dc2", Region: "eu"}
+	for _, tt := range tests[:3] {
+		want := strings.NewReplacer("local", "dc2", "global", "eu").Replace(tt.want)
+		if names := nodeCertNames(cfg, newNode("dev", tt.kind, tt.role, 1, "v1")); !slices.Contains(names, want) {
+			t.Errorf("nodeCertNames() in dc2/eu = %v, want %s", names, want)
+		}
+	}
+}
+
+func TestManager_EnsureCertificates(t *testing.T) {
+	m := newFakeManager(t, "dev", fake.New(), WithTLS(true))
+	if err := m.ensureCertificates(); err != nil {
+		t.Fatalf("ensureCertificates() error = %v", err)
+	}
+
+	caPEM, err := os.ReadFile(m.CACertFile())
+	if err != nil {
+		t.Fatalf("CA certificate not written: %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(caPEM)
+
+	for _, n := range m.config.Nodes {
+		data, err := os.ReadFile(m.tlsFile(n.Name, certFile))
+		if err != nil {
+			t.Fatalf("certificate of %s not written: %v", n.Name, err)
+		}
+		cert, err := parseCertificate(data)
+		if err != nil {
+			t.Fatalf("certificate of %s: %v", n.Name, err)
+		}
+		for _, name := range nodeCertNames(m.config, n) {
+			_, err := cert.Verify(x509.VerifyOptions{DNSName: name, Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}})
+			if err != nil {
+				t.Errorf("certificate of %s is not valid for %s: %v", n.Name, name, err)
+			}
+		}
+		if nodeCA, _ := os.ReadFile(m.tlsFile(n.Name, CACertFile)); !bytes.Equal(nodeCA, caPEM) {
+			t.Errorf("CA certificate of %s differs from the cluster CA", n.Name)
+		}
+	}
+
+	for _, key := range []string{m.tlsFile(caKeyFile), m.tlsFile(CLIKeyFile), m.tlsFile("hind.dev.client.01", keyFile)} {
+		info, err := os.Stat(key)
+		if err != nil {
+			t.Fatalf("key not written: %v", err)
+		}
+		if perm := info.Mode().Perm(); perm != secretPermissions {
+			t.Errorf("%s permissions = %o, want %o", key, perm, secretPermissions)
+		}
+	}
+
+	// Restarts keep the CA and the valid certificates, certificates of new
+	// nodes are issued by the same CA
+	cert, _ := os.ReadFile(m.tlsFile("hind.dev.client.01", certFile))
+	if err := m.ResizeClients(2); err != nil {
+		t.Fatalf("ResizeClients() error = %v", err)
+	}
+	if err := m.ensureCertificates(); err != nil {
+		t.Fatalf("ensureCertificates() again error = %v", err)
+	}
+	if got, _ := os.ReadFile(m.CACertFile()); !bytes.Equal(got, caPEM) {
+		t.Errorf("CA certificate was generated again")
+	}
+	if got, _ := os.ReadFile(m.tlsFile("hind.dev.client.01", certFile)); !bytes.Equal(got, cert) {
+		t.Errorf("valid certificate of hind.dev.client.01 was issued again")
+	}
+	data, err := os.ReadFile(m.tlsFile("hind.dev.client.02", certFile))
+	if err != nil {
+		t.Fatalf("certificate of added client not written: %v", err)
+	}
+	added, _ := parseCertificate(data)
+	if _, err := added.Verify(x509.VerifyOptions{DNSName: "client.global.nomad", Roots: roots}); err != nil {
+		t.Errorf("certificate of added client is not valid: %v", err)
+	}
+}
+
+func TestManager_TLSStart(t *testing.T) {
+	p := fake.New()
+	m := newFakeManager(t, "dev", p, WithTLS(true))
+	ctx := context.Background()
+	if _, err := m.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// Every container mounts its certificates and enables TLS in its agents
+	for _, n := range m.config.Nodes {
+		info, err := p.InspectContainer(ctx, n.Name)
+		if err != nil || info == nil {
+			t.Fatalf("InspectContainer(%s) = %v, %v", n.Name, info, err)
+		}
+		mounted := slices.ContainsFunc(info.Spec.Volumes, func(v config.Volume) bool {
+			return v.Destination == nodeTLSDir && v.Source == m.tlsFile(n.Name) && v.ReadOnly
+		})
+		if !mounted {
+			t.Errorf("%s volumes = %+v, want the certificates mounted at %s", n.Name, info.Spec.Volumes, nodeTLSDir)
+		}
+		if info.Spec.Environment["CONSUL_TLS_ENABLED"] != "true" {
+			t.Errorf("%s environment = %v, want CONSUL_TLS_ENABLED", n.Name, info.Spec.Environment)
+		}
+	}
+
+	// The mount isn't seen as drift
+	plan, err := m.Plan(ctx)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if !plan.IsEmpty() {
+		t.Errorf("Plan() = %+v, want no changes", plan)
+	}
+
+	endpoints, err := m.Endpoints(ctx)
+	if err != nil {
+		t.Fatalf("Endpoints() error = %v", err)
+	}
+	for _, e := range endpoints {
+		if !strings.HasPrefix(e.URL, "https://") {
+			t.Errorf("endpoint %s = %s, want https", e.Name, e.URL)
+		}
+	}
+
+	env := clientEnvironment(t, m)
+	for name, want := range map[string]string{
+		"NOMAD_CACERT":       m.CACertFile(),
+		"CONSUL_CLIENT_CERT": m.tlsFile(CLICertFile),
+		"VAULT_CLIENT_KEY":   m.tlsFile(CLIKeyFile),
+	} {
+		if env[name] != want {
+			t.Errorf("ClientEnvironment()[%s] = %q, want %q", name, env[name], want)
+		}
+	}
+
+	env = m.AgentEnvironment(*m.findNodeConfigByName("hind.dev.client.01"))
+	if env["CONSUL_HTTP_ADDR"] != "https://127.0.0.1:8500" || env["NOMAD_CACERT"] != nodeTLSDir+"/ca.pem" {
+		t.Errorf("AgentEnvironment() = %v, want https with the mounted CA", env)
+	}
+}
@@ -1,7 +1,12 @@
 package cluster
 
 import (
+	"cmp"
 	"fmt"
+	"maps"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/stenh0use/hind/pkg/build/release"
 	"github.com/stenh0use/hind/pkg/config"
@@ -14,6 +19,52 @@ const (
 	DefaultVaultServers  = 1
 )
 
+// The datacenter and region of the agents when the cluster config leaves
+// them empty, as baked in the images
+const (
+	DefaultDatacenter = "local"
+	DefaultRegion     = "global"
+)
+
+// locationName matches the datacenter and region names Consul and Nomad
+// accept, eg. eu-west-1a
+var locationName = regexp.MustCompile(`^[a-z0-9]([a-z0-9_-]*[a-z0-9])?$`)
+
+// Topology is the number of nodes of each type in a cluster
+type Topology struct {
+	ConsulServers int
+	NomadServers  int
+	VaultServers  int
+	NomadClients  int
+}
+
+// DefaultTopology returns the topology used for new clusters
+func DefaultTopology() Topology {
+	return Topology{
+		ConsulServers: DefaultConsulServers,
+		NomadServers:  DefaultNomadServers,
+		VaultServers:  DefaultVaultServers,
+		NomadClients:  DefaultNomadClients,
+	}
+}
+
+// Validate checks the topology has at least one node of each type
+func (t Topology) Validate() error {
+	if t.ConsulServers < 1 {
+		return fmt.Errorf("consul server count must be at least 1")
+	}
+	if t.NomadServers < 1 {
+		return fmt.Errorf("nomad server count must be at least 1")
+	}
+	if t.VaultServers < 1 {
+		return fmt.Errorf("vault server count must be at least 1")
+	}
+	if t.NomadClients < 1 {
+		return fmt.Errorf("client count must be at least 1")
+	}
+	return nil
+}
+
 // StartResult indicates the outcome of a cluster start operation
 type StartResult int
 
@@ -26,29 +77,19 @@ const (
 	StartResultAlreadyRunning
 )
 
-func newClusterConfig(name string, version string) (*config.Cluster, error) {
+func newClusterConfig(name string, version string, topology Topology) (*config.Cluster, error) {
 	v, err := release.Get(version)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get version: %w", err)
 	}
-	networkName := "hind." + name
+	if err := topology.Validate(); err != nil {
+		return nil, err
+	}
 
 	var nodes []config.Node
 
-	for count := range DefaultConsulServers {
-		consulServer := config.Node{
-			Name:    fmt.Sprintf("hind.%s.consul.%.2d", name, count+1),
-			Kind:    config.ConsulNode,
-			Role:    config.Server,
-			Network: networkName,
-			Image: config.Image{
-				Name: release.Consul.ImageName(),
-				Tag:  v.Hind,
-			},
-			Environment: map[string]string{
-				"CONSUL_AGENT_MODE": "server",
-			},
-		}
+	for count := range topology.ConsulServers {
+		consulServer := newNode(name, config.ConsulNode, config.Server, count+1, v.Hind)
 		// expose the port only on the first instance
 		if count == 0 {
 			consulServer.Ports = []config.PortMapping{
@@ -62,22 +103,8 @@ func newClusterConfig(name string, version string) (*config.Cluster, error) {
 		nodes = append(nodes, consulServer)
 	}
 
-	for count := range DefaultNomadServers {
-		nomadServer := config.Node{
-			Name:    fmt.Sprintf("hind.%s.nomad.%.2d", name, count+1),
-			Kind:    config.NomadNode,
-			Role:    config.Server,
-			Network: networkName,
-			Image: config.Image{
-				Name: release.Nomad.ImageName(),
-				Tag:  v.Hind,
-			},
-			Environment: map[string]string{
-				"CONSUL_AGENT_MODE":     "client",
-				"CONSUL_SERVER_ADDRESS": fmt.Sprintf("hind.%s.consul.%.2d", name, 1),
-				"NOMAD_AGENT_MODE":      "server",
-			},
-		}
+	for count := range topology.NomadServers {
+		nomadServer := newNode(name, config.NomadNode, config.Server, count+1, v.Hind)
 		// expose the port only on the first instance
 		if count == 0 {
 			nomadServer.Ports = []config.PortMapping{
@@ -91,48 +118,12 @@ func newClusterConfig(name string, version string) (*config.Cluster, error) {
 		nodes = append(nodes, nomadServer)
 	}
 
-	for count := range DefaultNomadClients {
-		nomadClient := config.Node{
-			Name:    fmt.Sprintf("hind.%s.client.%.2d", name, count+1),
-			Kind:    config.NomadNode,
-			Role:    config.Client,
-			Network: networkName,
-			Image: config.Image{
-				Name: release.NomadClient.ImageName(),
-				Tag:  v.Hind,
-			},
-			Devices: []string{"/dev/fuse"},
-			Environment: map[string]string{
-				"CONSUL_AGENT_MODE":     "client",
-				"CONSUL_SERVER_ADDRESS": fmt.Sprintf("hind.%s.consul.%.2d", name, 1),
-				"NOMAD_AGENT_MODE":      "client",
-			},
-		}
-		nodes = append(nodes, nomadClient)
-	}
-
-	for count := range DefaultVaultServers {
-		vaultServer := config.Node{
-			Name:    fmt.Sprintf("hind.%s.vault.%.2d", name, count+1),
-			Kind:    config.VaultNode,
-			Role:    config.Server,
-			Network: networkName,
-			Image: config.Image{
-				Name: release.Vault.ImageName(),
-				Tag:  v.Hind,
-			},
-			Ports: []config.PortMapping{
-				{
-					HostPort:      8200,
-					ContainerPort: 8200,
-					Protocol:      "tcp",
-				},
-			},
-			Environment: map[string]string{
-				"CONSUL_AGENT_MODE":     "client",
-				"CONSUL_SERVER_ADDRESS": fmt.Sprintf("hind.%s.consul.%.2d", name, 1),
-			},
-		}
+	for count := range topology.NomadClients {
+		nodes = append(nodes, newNode(name, config.NomadNode, config.Client, count+1, v.Hind))
+	}
+
+	for count := range topology.VaultServers {
+		vaultServer := newNode(name, config.VaultNode, config.Server, count+1, v.Hind)
 		// expose the port only on the first instance
 		if count == 0 {
 			vaultServer.Ports = []config.PortMapping{
@@ -146,12 +137,224 @@ func newClusterConfig(name string, version string) (*config.Cluster, error) {
 		nodes = append(nodes, vaultServer)
 	}
 
+	for i := range nodes {
+		maps.Copy(nodes[i].Environment, joinEnvironment(nodes, nodes[i]))
+	}
+
 	cluster := &config.Cluster{
 		Name:    name,
 		Nodes:   nodes,
-		Network: config.Network{Name: "hind." + name},
+		Network: config.Network{Name: networkName(name)},
 		Version: v.Hind,
 	}
 
 	return cluster, nil
 }
+
+// newNode returns the default node configuration for the given kind and role.
+// index is the 1-based position of the node amongst nodes of the same type.
+func newNode(clusterName string, kind config.Kind, role config.Role, index int, tag string) config.Node {
+	node := config.Node{
+		Name:    nodeName(clusterName, kind, role, index),
+		Kind:    kind,
+		Role:    role,
+		Network: networkName(clusterName),
+		Image: config.Image{
+			Name: nodeImageKind(kind, role).ImageName(),
+			Tag:  tag,
+		},
+		Environment: nodeEnvironment(kind, role),
+	}
+	if role == config.Client {
+		node.Devices = []string{"/dev/fuse"}
+	}
+	return node
+}
+
+// networkName returns the docker network name for a cluster
+func networkName(clusterName string) string {
+	return "hind." + clusterName
+}
+
+// nodeName returns the container name for a node, eg. hind.default.client.01
+func nodeName(clusterName string, kind config.Kind, role config.Role, index int) string {
+	nodeType := kind.String()
+	if role == config.Client {
+		nodeType = config.Client.String()
+	}
+	return fmt.Sprintf("hind.%s.%s.%.2d", clusterName, nodeType, index)
+}
+
+// nodeImageKind returns the hind image used to run a node of the given kind and role
+func nodeImageKind(kind config.Kind, role config.Role) release.ImageKind {
+	switch {
+	case kind == config.ConsulNode:
+		return release.Consul
+	case kind == config.VaultNode:
+		return release.Vault
+	case kind == config.NomadNode && role == config.Client:
+		return release.NomadClient
+	default:
+		return release.Nomad
+	}
+}
+
+// nodeEnvironment returns the environment the node entrypoints need to
+// configure the agents for the given kind and role.
+func nodeEnvironment(kind config.Kind, role config.Role) map[string]string {
+	switch kind {
+	case config.ConsulNode:
+		return map[string]string{
+			"CONSUL_AGENT_MODE": "server",
+		}
+	case config.NomadNode:
+		return map[string]string{
+			"CONSUL_AGENT_MODE": "client",
+			"NOMAD_AGENT_MODE":  role.String(),
+		}
+	default:
+		return map[string]string{
+			"CONSUL_AGENT_MODE": "client",
+		}
+	}
+}
+
+// enableACL turns on the ACLs of a cluster and the agents of its nodes
+func enableACL(cfg *config.Cluster) {
+	cfg.ACL = true
+	for i := range cfg.Nodes {
+		maps.Copy(cfg.Nodes[i].Environment, aclEnvironment(cfg.Nodes[i]))
+	}
+}
+
+// aclEnvironment returns the environment enabling ACLs in the agents of a
+// node. Every node runs a Consul agent.
+func aclEnvironment(node config.Node) map[string]string {
+	env := map[string]string{"CONSUL_ACL_ENABLED": "true"}
+	if node.Kind == config.NomadNode {
+		env["NOMAD_ACL_ENABLED"] = "true"
+	}
+	return env
+}
+
+// enableTLS turns on TLS in a cluster and the agents of its nodes
+func enableTLS(cfg *config.Cluster) {
+	cfg.TLS = true
+	for i := range cfg.Nodes {
+		maps.Copy(cfg.Nodes[i].Environment, tlsEnvironment(cfg.Nodes[i]))
+	}
+}
+
+// tlsEnvironment returns the environment enabling TLS in the agents of a
+// node, with the certificates mounted by hind
+func tlsEnvironment(node config.Node) map[string]string {
+	env := map[string]string{"CONSUL_TLS_ENABLED": "true"}
+	switch node.Kind {
+	case config.NomadNode:
+		env["NOMAD_TLS_ENABLED"] = "true"
+	case config.VaultNode:
+		env["VAULT_TLS_ENABLED"] = "true"
+	}
+	return env
+}
+
+// Datacenter returns the datacenter of the Consul and Nomad agents of a
+// cluster
+func Datacenter(cfg *config.Cluster) string {
+	return cmp.Or(cfg.Datacenter, DefaultDatacenter)
+}
+
+// Region returns the Nomad region of a cluster
+func Region(cfg *config.Cluster) string {
+	return cmp.Or(cfg.Region, DefaultRegion)
+}
+
+// validateLocation checks the datacenter and region names of a cluster
+func validateLocation(cfg *config.Cluster) error {
+	if cfg.Datacenter != "" && !locationName.MatchString(cfg.Datacenter) {
+		return fmt.Errorf("invalid datacenter '%s', use lowercase letters, digits, '-' and '_'", cfg.Datacenter)
+	}
+	if cfg.Region != "" && !locationName.MatchString(cfg.Region) {
+		return fmt.Errorf("invalid region '%s', use lowercase letters, digits, '-' and '_'", cfg.Region)
+	}
+	return nil
+}
+
+// setLocation sets the datacenter and region of a cluster and the agents
+// of its nodes
+func setLocation(cfg *config.Cluster, datacenter, region string) error {
+	cfg.Datacenter, cfg.Region = datacenter, region
+	if err := validateLocation(cfg); err != nil {
+		return err
+	}
+	for i := range cfg.Nodes {
+		maps.Copy(cfg.Nodes[i].Environment, locationEnvironment(cfg, cfg.Nodes[i]))
+	}
+	return nil
+}
+
+// locationEnvironment returns the environment setting the datacenter and
+// region the cluster config sets in the agents of a node
+func locationEnvironment(cfg *config.Cluster, node config.Node) map[string]string {
+	env := map[string]string{}
+	if cfg.Datacenter != "" {
+		env["CONSUL_DATACENTER"] = cfg.Datacenter
+		if node.Kind == config.NomadNode {
+			env["NOMAD_DATACENTER"] = cfg.Datacenter
+		}
+	}
+	if cfg.Region != "" && node.Kind == config.NomadNode {
+		env["NOMAD_REGION"] = cfg.Region
+	}
+	return env
+}
+
+// clusterEnvironment returns the environment of a node for the settings of
+// the whole cluster: its datacenter and region, and ACL and TLS modes
+func clusterEnvironment(cfg *config.Cluster, node config.Node) map[string]string {
+	env := locationEnvironment(cfg, node)
+	if cfg.ACL {
+		maps.Copy(env, aclEnvironment(node))
+	}
+	if cfg.TLS {
+		maps.Copy(env, tlsEnvironment(node))
+	}
+	return env
+}
+
+// joinEnvironment returns the environment a node's agents need to form a
+// cluster with the given nodes: the servers to retry join and, for servers,
+// the number of servers to expect before bootstrapping.
+func joinEnvironment(nodes []config.Node, node config.Node) map[string]string {
+	servers := map[config.Kind][]string{}
+	for _, n := range nodes {
+		if n.Role == config.Server {
+			servers[n.Kind] = append(servers[n.Kind], n.Name)
+		}
+	}
+
+	env := map[string]string{}
+	if consul := servers[config.ConsulNode]; len(consul) > 0 {
+		env["CONSUL_SERVER_ADDRESS"] = strings.Join(consul, ",")
+	}
+
+	switch node.Kind {
+	case config.ConsulNode:
+		if node.Role == config.Server {
+			env["CONSUL_BOOTSTRAP_EXPECT"] = strconv.Itoa(len(servers[config.ConsulNode]))
+		}
+	case config.NomadNode:
+		if nomad := servers[config.NomadNode]; len(nomad) > 0 {
+			env["NOMAD_SERVER_ADDRESS"] = strings.Join(nomad, ",")
+		}
+		if node.Role == config.Server {
+			env["NOMAD_BOOTSTRAP_EXPECT"] = strconv.Itoa(len(servers[config.NomadNode]))
+		}
+	case config.VaultNode:
+		if vault := servers[config.VaultNode]; len(vault) > 0 {
+			env["VAULT_SERVER_ADDRESS"] = strings.Join(vault, ",")
+		}
+	}
+
+	return env
+}
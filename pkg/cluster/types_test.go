@@ -0,0 +1,253 @@
+package cluster
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+	"github.com/stenh0use/hind/pkg/build/release"
+	"github.com/stenh0use/hind/pkg/config"
+)
+
+func TestNewClusterConfig_Topology(t *testing.T) {
+	topology := Topology{
+		ConsulServers: 3,
+		NomadServers:  3,
+		VaultServers:  3,
+		NomadClients:  2,
+	}
+
+	cfg, err := newClusterConfig("ha", release.Latest().Hind, topology)
+	if err != nil {
+		t.Fatalf("newClusterConfig() error = %v", err)
+	}
+
+	if len(cfg.Nodes) != 11 {
+		t.Fatalf("len(Nodes) = %d, want 11", len(cfg.Nodes))
+	}
+
+	published := map[string]int{}
+	for _, node := range cfg.Nodes {
+		if len(node.Ports) > 0 {
+			published[node.Kind.String()+"/"+node.Role.String()]++
+		}
+	}
+	for _, key := range []string{"consul/server", "nomad/server", "vault/server"} {
+		if published[key] != 1 {
+			t.Errorf("%s nodes publishing ports = %d, want 1", key, published[key])
+		}
+	}
+
+	consulServers := "hind.ha.consul.01,hind.ha.consul.02,hind.ha.consul.03"
+	nomadServers := "hind.ha.nomad.01,hind.ha.nomad.02,hind.ha.nomad.03"
+
+	tests := []struct {
+		node string
+		env  map[string]string
+	}{
+		{
+			node: "hind.ha.consul.02",
+			env: map[string]string{
+				"CONSUL_AGENT_MODE":       "server",
+				"CONSUL_BOOTSTRAP_EXPECT": "3",
+				"CONSUL_SERVER_ADDRESS":   consulServers,
+			},
+		},
+		{
+			node: "hind.ha.nomad.03",
+			env: map[string]string{
+				"NOMAD_AGENT_MODE":       "server",
+				"NOMAD_BOOTSTRAP_EXPECT": "3",
+				"NOMAD_SERVER_ADDRESS":   nomadServers,
+				"CONSUL_SERVER_ADDRESS":  consulServers,
+			},
+		},
+		{
+			node: "hind.ha.client.02",
+			env: map[string]string{
+				"NOMAD_AGENT_MODE":       "client",
+				"NOMAD_SERVER_ADDRESS":   nomadServers,
+				"NOMAD_BOOTSTRAP_EXPECT": "",
+			},
+		},
+		{
+			node: "hind.ha.vault.01",
+			env: map[string]string{
+				"VAULT_SERVER_ADDRESS": "hind.ha.vault.01,hind.ha.vault.02,hind.ha.vault.03",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.node, func(t *testing.T) {
+			var node *config.Node
+			for i := range cfg.Nodes {
+				if cfg.Nodes[i].Name == tt.node {
+					node = &cfg.Nodes[i]
+				}
+			}
+			if node == nil {
+				t.Fatalf("node %s not found", tt.node)
+			}
+			for k, want := range tt.env {
+				if got := node.Environment[k]; got != want {
+					t.Errorf("Environment[%s] = %q, want %q", k, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTopology_Validate(t *testing.T) {
+	if err := DefaultTopology().Validate(); err != nil {
+		t.Errorf("DefaultTopology().Validate() error = %v", err)
+	}
+
+	invalid := []Topology{
+		{ConsulServers: 0, NomadServers: 1, VaultServers: 1, NomadClients: 1},
+		{ConsulServers: 1, NomadServers: 0, VaultServers: 1, NomadClients: 1},
+		{ConsulServers: 1, NomadServers: 1, VaultServers: 0, NomadClients: 1},
+		{ConsulServers: 1, NomadServers: 1, VaultServers: 1, NomadClients: 0},
+	}
+	for _, topology := range invalid {
+		if err := topology.Validate(); err == nil {
+			t.Errorf("Validate(%+v) want error, got nil", topology)
+		}
+	}
+}
+
+func TestNew_Version(t *testing.T) {
+	logger := &log.Logger{Handler: discard.New(), Level: log.ErrorLevel}
+
+	tests := []struct {
+		name        string
+		version     string
+		wantVersion string
+		wantErr     bool
+	}{
+		{
+			name:        "default is the latest release",
+			version:     "",
+			wantVersion: release.Latest().Hind,
+		},
+		{
+			name:        "older release",
+			version:     "0.3.0",
+			wantVersion: "0.3.0",
+		},
+		{
+			name:    "unknown release",
+			version: "0.0.1",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := New(logger, "test", WithVersion(tt.version))
+			if tt.wantErr {
+				if !errors.Is(err, release.ErrUnknownRelease) {
+					t.Errorf("New(WithVersion(%q)) error = %v, want ErrUnknownRelease", tt.version, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New(WithVersion(%q)) unexpected error: %v", tt.version, err)
+			}
+
+			if got := m.Config().Version; got != tt.wantVersion {
+				t.Errorf("Config().Version = %q, want %q", got, tt.wantVersion)
+			}
+			for _, node := range m.Config().Nodes {
+				if node.Image.Tag != tt.wantVersion {
+					t.Errorf("node '%s' image tag = %q, want %q", node.Name, node.Image.Tag, tt.wantVersion)
+				}
+			}
+		})
+	}
+}
+
+func TestNew_Location(t *testing.T) {
+	logger := &log.Logger{Handler: discard.New(), Level: log.ErrorLevel}
+
+	tests := []struct {
+		name       string
+		datacenter string
+		region     string
+		want       map[config.Kind]map[string]string
+		wantErr    bool
+	}{
+		{
+			name: "defaults leave the agents unchanged",
+			want: map[config.Kind]map[string]string{
+				config.ConsulNode: {},
+				config.NomadNode:  {},
+			},
+		},
+		{
+			name:       "datacenter and region",
+			datacenter: "eu-west-1a",
+			region:     "eu",
+			want: map[config.Kind]map[string]string{
+				config.ConsulNode: {"CONSUL_DATACENTER": "eu-west-1a"},
+				config.NomadNode:  {"CONSUL_DATACENTER": "eu-west-1a", "NOMAD_DATACENTER": "eu-west-1a", "NOMAD_REGION": "eu"},
+			},
+		},
+		{
+			name:       "invalid datacenter",
+			datacenter: "EU West",
+			wantErr:    true,
+		},
+		{
+			name:    "invalid region",
+			region:  "eu.",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := New(logger, "test", WithLocation(tt.datacenter, tt.region))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("New(WithLocation(%q, %q)) error = nil, want error", tt.datacenter, tt.region)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New(WithLocation(%q, %q)) unexpected error: %v", tt.datacenter, tt.region, err)
+			}
+
+			for _, node := range m.Config().Nodes {
+				want, ok := tt.want[node.Kind]
+				if !ok {
+					continue
+				}
+				for _, key := range []string{"CONSUL_DATACENTER", "NOMAD_DATACENTER", "NOMAD_REGION"} {
+					if got := node.Environment[key]; got != want[key] {
+						t.Errorf("node '%s' %s = %q, want %q", node.Name, key, got, want[key])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDatacenterRegion(t *testing.T) {
+	cfg := &config.Cluster{}
+	if got := Datacenter(cfg); got != DefaultDatacenter {
+		t.Errorf("Datacenter() = %q, want %q", got, DefaultDatacenter)
+	}
+	if got := Region(cfg); got != DefaultRegion {
+		t.Errorf("Region() = %q, want %q", got, DefaultRegion)
+	}
+
+	cfg = &config.Cluster{Datacenter: "dc2", Region: "eu"}
+	if got := Datacenter(cfg); got != "dc2" {
+		t.Errorf("Datacenter() = %q, want dc2", got)
+	}
+	if got := Region(cfg); got != "eu" {
+		t.Errorf("Region() = %q, want eu", got)
+	}
+}
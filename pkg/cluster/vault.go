@@ -0,0 +1,189 @@
+package cluster
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+// VaultCredentialsFile is the file in the cluster directory holding the
+// Vault credentials, readable by the owner only
+const VaultCredentialsFile = "vault.json"
+
+// ErrNoVaultCredentials is returned when the credentials of a cluster's
+// Vault haven't been collected yet
+var ErrNoVaultCredentials = errors.New("no vault credentials")
+
+// VaultCredentials are the secrets the vault bootstrap generates when it
+// initialises Vault
+type VaultCredentials struct {
+	RootToken string `json:"rootToken"`
+	UnsealKey string `json:"unsealKey"`
+}
+
+// vaultDataDir is where Vault servers keep their raft storage
+const vaultDataDir = "/vault/data"
+
+// vaultEnvCmd prints the environment file vault-bootstrap writes the
+// credentials to
+var vaultEnvCmd = []string{"sh", "-c", `cat "${VAULT_CONFIG_DIR:-/etc/vault.d}/vault.env"`}
+
+// vaultUnsealCmd unseals the local Vault server with the key in
+// VAULT_UNSEAL_KEY when it is sealed. vault status exits with 2 when
+// sealed, and 1 when Vault can't be reached yet.
+var vaultUnsealCmd = []string{"sh", "-c", `vault status >/dev/null 2>&1
+case $? in
+0) exit 0 ;;
+2) vault operator unseal "$VAULT_UNSEAL_KEY" >/dev/null ;;
+*) echo "vault is not running" >&2; exit 1 ;;
+esac`}
+
+// VaultCredentials returns the stored Vault credentials of the cluster, or
+// ErrNoVaultCredentials if none were collected
+func (m *Manager) VaultCredentials() (*VaultCredentials, error) {
+	var creds VaultCredentials
+	found, err := m.readSecrets(VaultCredentialsFile, &creds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault credentials: %w", err)
+	}
+	if !found {
+		return nil, ErrNoVaultCredentials
+	}
+	return &creds, nil
+}
+
+// saveVaultCredentials stores the Vault credentials of the cluster
+func (m *Manager) saveVaultCredentials(creds *VaultCredentials) error {
+	if err := m.writeSecrets(VaultCredentialsFile, creds); err != nil {
+		return err
+	}
+	m.logger.Debug("Updated vault credentials")
+	return nil
+}
+
+// vaultToken returns the root token of the cluster's Vault, empty when it
+// wasn't collected yet
+func (m *Manager) vaultToken() string {
+	creds, err := m.VaultCredentials()
+	if err != nil {
+		if !errors.Is(err, ErrNoVaultCredentials) {
+			m.logger.Warnf("Failed to read vault credentials: %v", err)
+		}
+		return ""
+	}
+	return creds.RootToken
+}
+
+// vaultDataVolume returns the named volume holding the raft storage of a
+// Vault server, so a recreated node keeps its data and the stored
+// credentials still unseal it. The volume carries the labels of the
+// container, rm and prune --all delete it with the cluster. A volume the
+// node config mounts there already takes its place.
+func vaultDataVolume(node config.Node) (config.Volume, bool) {
+	if node.Kind != config.VaultNode {
+		return config.Volume{}, false
+	}
+	for _, v := range node.Volumes {
+		if v.Destination == vaultDataDir {
+			return config.Volume{}, false
+		}
+	}
+	return config.Volume{Type: config.VolumeMount, Name: node.Name + ".data", Destination: vaultDataDir}, true
+}
+
+// unsealVault collects the credentials from the first Vault server once it
+// is initialised, then unseals every Vault server that is sealed, eg. after
+// a restart or when the node was recreated. Credentials on the first server
+// that differ from the stored ones mean Vault lost its data and was
+// initialised again, they replace the stored ones with a warning.
+func (m *Manager) unsealVault(ctx context.Context) error {
+	servers := m.nodesOf(config.VaultNode, config.Server)
+	if len(servers) == 0 {
+		return nil
+	}
+
+	creds, err := m.collectVaultCredentials(ctx, servers[0])
+	if err != nil {
+		return err
+	}
+
+	for _, n := range servers {
+		env := m.nodeCertEnvironment()
+		env[agentAddrEnv[config.VaultNode]] = m.agentAddress("127.0.0.1", config.VaultNode)
+		env["VAULT_UNSEAL_KEY"] = creds.UnsealKey
+		if err := m.nodeExec(ctx, n, vaultUnsealCmd, env, io.Discard); err != nil {
+			return fmt.Errorf("failed to unseal '%s': %w", n.Name, err)
+		}
+	}
+	return nil
+}
+
+// collectVaultCredentials reads the credentials from a Vault server and
+// stores them, falling back to the stored ones when the server doesn't have
+// them anymore
+func (m *Manager) collectVaultCredentials(ctx context.Context, node config.Node) (*VaultCredentials, error) {
+	stored, err := m.VaultCredentials()
+	if err != nil && !errors.Is(err, ErrNoVaultCredentials) {
+		return nil, err
+	}
+
+	var stdout bytes.Buffer
+	err = m.provider.Exec(ctx, node.Name, provider.ExecOptions{
+		Cmd:    vaultEnvCmd,
+		Stdout: &stdout,
+		Stderr: io.Discard,
+	})
+	// The file is missing until the bootstrap ran, or in recreated nodes
+	var exitErr *provider.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return nil, fmt.Errorf("failed to read vault credentials from '%s': %w", node.Name, err)
+	}
+
+	found := parseVaultCredentials(stdout.Bytes())
+	switch {
+	case found != nil && (stored == nil || *found != *stored):
+		if stored != nil {
+			m.logger.Warnf("Vault on '%s' was initialised again and its previous data is lost, replacing the credentials stored in %s",
+				node.Name, m.clusterFile(VaultCredentialsFile))
+		}
+		if err := m.saveVaultCredentials(found); err != nil {
+			return nil, err
+		}
+		return found, nil
+	case stored != nil:
+		return stored, nil
+	default:
+		return nil, fmt.Errorf("vault on '%s' is not initialised yet", node.Name)
+	}
+}
+
+// parseVaultCredentials reads the credentials from the environment file
+// written by vault-bootstrap, nil when they aren't all set. Later
+// assignments win, the file is appended to.
+func parseVaultCredentials(env []byte) *VaultCredentials {
+	var creds VaultCredentials
+	scanner := bufio.NewScanner(bytes.NewReader(env))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "VAULT_ROOT_TOKEN":
+			creds.RootToken = value
+		case "VAULT_UNSEAL_KEY":
+			creds.UnsealKey = value
+		}
+	}
+	if creds.RootToken == "" || creds.UnsealKey == "" {
+		return nil
+	}
+	return &creds
+}
@@ -0,0 +1,248 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/memory"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+	"github.com/stenh0use/hind/pkg/provider/fake"
+)
+
+func TestParseVaultCredentials(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		want *VaultCredentials
+	}{
+		{
+			name: "bootstrapped",
+			env:  "VAULT_CONFIG_DIR=/etc/vault.d\nVAULT_ROOT_TOKEN=hvs.one\nVAULT_UNSEAL_KEY=key1\n",
+			want: &VaultCredentials{RootToken: "hvs.one", UnsealKey: "key1"},
+		},
+		{
+			name: "initialised again",
+			env:  "VAULT_ROOT_TOKEN=hvs.one\nVAULT_UNSEAL_KEY=key1\nVAULT_ROOT_TOKEN=hvs.two\nVAULT_UNSEAL_KEY=key2\n",
+			want: &VaultCredentials{RootToken: "hvs.two", UnsealKey: "key2"},
+		},
+		{
+			name: "not bootstrapped",
+			env:  "VAULT_CONFIG_DIR=/etc/vault.d\nVAULT_ADDR=http://127.0.0.1:8200\n",
+		},
+		{
+			name: "empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseVaultCredentials([]byte(tt.env))
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("parseVaultCredentials() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManager_UnsealVault(t *testing.T) {
+	env := ""
+	unsealed := map[string]string{}
+	p := fake.New(fake.WithExec(func(_ context.Context, name string, opts provider.ExecOptions) error {
+		switch opts.Cmd[len(opts.Cmd)-1] {
+		case vaultEnvCmd[len(vaultEnvCmd)-1]:
+			if env == "" {
+				return &provider.ExitError{Code: 1}
+			}
+			io.WriteString(opts.Stdout, env)
+		case vaultUnsealCmd[len(vaultUnsealCmd)-1]:
+			unsealed[name] = opts.Env["VAULT_UNSEAL_KEY"]
+		}
+		return nil
+	}))
+	m := newFakeManager(t, "dev", p, WithTopology(Topology{
+		ConsulServers: 1, NomadServers: 1, VaultServers: 2, NomadClients: 1,
+	}))
+	ctx := context.Background()
+	if _, err := m.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// Not bootstrapped yet, there is nothing to unseal with
+	if err := m.unsealVault(ctx); err == nil {
+		t.Fatal("unsealVault() before the bootstrap error = nil, want error")
+	}
+	if _, err := m.VaultCredentials(); !errors.Is(err, ErrNoVaultCredentials) {
+		t.Errorf("VaultCredentials() error = %v, want %v", err, ErrNoVaultCredentials)
+	}
+
+	env = "VAULT_ROOT_TOKEN=hvs.one\nVAULT_UNSEAL_KEY=key1\n"
+	if err := m.unsealVault(ctx); err != nil {
+		t.Fatalf("unsealVault() error = %v", err)
+	}
+	want := &VaultCredentials{RootToken: "hvs.one", UnsealKey: "key1"}
+	got, err := m.VaultCredentials()
+	if err != nil || *got != *want {
+		t.Fatalf("VaultCredentials() = %v, %v, want %v", got, err, want)
+	}
+	for _, name := range []string{"hind.dev.vault.01", "hind.dev.vault.02"} {
+		if unsealed[name] != "key1" {
+			t.Errorf("%s unsealed with %q, want key1", name, unsealed[name])
+		}
+	}
+
+	info, err := os.Stat(m.clusterFile(VaultCredentialsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != secretPermissions {
+		t.Errorf("credentials file permissions = %o, want %o", perm, secretPermissions)
+	}
+
+	// A recreated leader lost its copy, the stored key still unseals
+	env = ""
+	clear(unsealed)
+	if err := m.unsealVault(ctx); err != nil {
+		t.Fatalf("unsealVault() after recreation error = %v", err)
+	}
+	if unsealed["hind.dev.vault.02"] != "key1" {
+		t.Errorf("hind.dev.vault.02 unsealed with %q, want key1", unsealed["hind.dev.vault.02"])
+	}
+
+	// Vault was initialised again, the new credentials replace the stored
+	// ones with a warning
+	logs := memory.New()
+	m.logger = &log.Logger{Handler: logs, Level: log.WarnLevel}
+	env = "VAULT_ROOT_TOKEN=hvs.two\nVAULT_UNSEAL_KEY=key2\n"
+	if err := m.unsealVault(ctx); err != nil {
+		t.Fatalf("unsealVault() error = %v", err)
+	}
+	if got, _ := m.VaultCredentials(); got == nil || got.RootToken != "hvs.two" {
+		t.Errorf("VaultCredentials() = %v, want the new credentials", got)
+	}
+	if len(logs.Entries) != 1 || !strings.Contains(logs.Entries[0].Message, "initialised again") {
+		t.Errorf("logged %v, want a warning that Vault was initialised again", logs.Entries)
+	}
+}
+
+func TestManager_VaultDataVolume(t *testing.T) {
+	p := fake.New()
+	m := newFakeManager(t, "dev", p)
+	ctx := context.Background()
+	if _, err := m.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	const volume = "hind.dev.vault.01.data"
+	info, err := p.InspectContainer(ctx, "hind.dev.vault.01")
+	if err != nil || info == nil {
+		t.Fatalf("InspectContainer() = %v, %v", info, err)
+	}
+	if !slices.ContainsFunc(info.Spec.Volumes, func(v config.Volume) bool {
+		return v.Name == volume && v.Destination == vaultDataDir
+	}) {
+		t.Errorf("vault volumes = %+v, want %s at %s", info.Spec.Volumes, volume, vaultDataDir)
+	}
+	if info, _ := p.InspectContainer(ctx, "hind.dev.consul.01"); len(info.Spec.Volumes) != 0 {
+		t.Errorf("consul volumes = %+v, want none", info.Spec.Volumes)
+	}
+
+	// A recreated node mounts the same volume, rm deletes it
+	if err := p.StopContainer(ctx, "hind.dev.vault.01"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.DeleteContainer(ctx, "hind.dev.vault.01"); err != nil {
+		t.Fatal(err)
+	}
+	if vols, _ := p.ListVolumes(ctx, []string{"name=" + volume}); len(vols) != 1 {
+		t.Errorf("volumes after the node was deleted = %v, want %s", vols, volume)
+	}
+	if _, err := m.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := m.Delete(ctx, DeleteOptions{}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if vols, _ := p.ListVolumes(ctx, []string{"name=" + volume}); len(vols) != 0 {
+		t.Errorf("volumes after rm = %v, want none", vols)
+	}
+}
+
+func TestManager_UnsealVault_Sealed(t *testing.T) {
+	p := fake.New(fake.WithExec(func(_ context.Context, _ string, opts provider.ExecOptions) error {
+		if opts.Cmd[len(opts.Cmd)-1] == vaultUnsealCmd[len(vaultUnsealCmd)-1] {
+			return &provider.ExitError{Code: 1}
+		}
+		io.WriteString(opts.Stdout, "VAULT_ROOT_TOKEN=hvs.one\nVAULT_UNSEAL_KEY=key1\n")
+		return nil
+	}))
+	m := newFakeManager(t, "dev", p)
+	if _, err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// Vault isn't running yet, the readiness check retries
+	if err := m.unsealVault(context.Background()); err == nil {
+		t.Error("unsealVault() error = nil, want error")
+	}
+}
+
+func TestManager_Start_UnsealsVault(t *testing.T) {
+	orig := readinessPollInterval
+	readinessPollInterval = time.Millisecond
+	t.Cleanup(func() { readinessPollInterval = orig })
+
+	// The first start gives up before vault-bootstrap initialised Vault
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var initialised atomic.Bool
+	var mu sync.Mutex
+	unsealed := map[string]string{}
+	p := fake.New(fake.WithExec(func(_ context.Context, name string, opts provider.ExecOptions) error {
+		switch opts.Cmd[len(opts.Cmd)-1] {
+		case vaultEnvCmd[len(vaultEnvCmd)-1]:
+			if !initialised.Load() {
+				cancel()
+				return &provider.ExitError{Code: 1}
+			}
+			io.WriteString(opts.Stdout, "VAULT_ROOT_TOKEN=hvs.one\nVAULT_UNSEAL_KEY=key1\n")
+		case vaultUnsealCmd[len(vaultUnsealCmd)-1]:
+			mu.Lock()
+			unsealed[name] = opts.Env["VAULT_UNSEAL_KEY"]
+			mu.Unlock()
+		}
+		return nil
+	}))
+	// newFakeManager skips the readiness checks, like start --wait=false
+	m := newFakeManager(t, "dev", p, withSetup(true))
+	if _, err := m.Start(ctx); err == nil {
+		t.Fatal("Start() error = nil, want the setup to fail")
+	}
+
+	// The containers run, the next start has nothing to reconcile but
+	// unseals Vault
+	plan, err := m.Plan(context.Background())
+	if err != nil || !plan.IsEmpty() {
+		t.Fatalf("Plan() = %+v, %v, want no changes", plan, err)
+	}
+	initialised.Store(true)
+	if _, err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start() again error = %v", err)
+	}
+	if creds, err := m.VaultCredentials(); err != nil || creds.UnsealKey != "key1" {
+		t.Errorf("VaultCredentials() = %v, %v, want the collected credentials", creds, err)
+	}
+	if unsealed["hind.dev.vault.01"] != "key1" {
+		t.Errorf("hind.dev.vault.01 unsealed with %q, want key1", unsealed["hind.dev.vault.01"])
+	}
+}
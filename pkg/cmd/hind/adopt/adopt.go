@@ -0,0 +1,54 @@
+package adopt
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+	"github.com/stenh0use/hind/pkg/cluster"
+	"github.com/stenh0use/hind/pkg/cmd/hind/flags"
+)
+
+// DefaultAdoptTimeout is the default timeout for adopting a cluster
+const DefaultAdoptTimeout = 30 * time.Second
+
+// NewCommand creates the cluster adopt command
+func NewCommand(logger *log.Logger) *cobra.Command {
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "adopt [cluster-name]",
+		Short: "Adopt a hind cluster without a config",
+		Long: `Rebuild the config of a cluster from the containers labeled with its name,
+eg. after the saved state was lost or a run crashed before saving it.
+Orphaned clusters are shown by 'hind list'. The containers are found with
+the provider selected by --provider.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(cmd.Context(), logger, timeout, args[0], flags.Provider(cmd))
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", DefaultAdoptTimeout, "Timeout for adopting the cluster")
+
+	return cmd
+}
+
+func runE(ctx context.Context, logger *log.Logger, timeout time.Duration, clusterName, providerName string) error {
+	adoptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	clusterMgr, err := cluster.New(logger, clusterName, cluster.WithProviderName(providerName))
+	if err != nil {
+		return fmt.Errorf("failed to create cluster manager: %w", err)
+	}
+
+	if err := clusterMgr.Adopt(adoptCtx); err != nil {
+		return fmt.Errorf("failed to adopt cluster: %w", err)
+	}
+
+	logger.Infof("Run 'hind start %s' to reconcile the cluster", clusterName)
+	return nil
+}
@@ -0,0 +1,29 @@
+package adopt
+
+import (
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+)
+
+func TestNewCommand(t *testing.T) {
+	logger := &log.Logger{
+		Handler: discard.New(),
+		Level:   log.ErrorLevel,
+	}
+
+	cmd := NewCommand(logger)
+
+	if cmd.Use != "adopt [cluster-name]" {
+		t.Errorf("Expected Use to be 'adopt [cluster-name]', got '%s'", cmd.Use)
+	}
+
+	if err := cmd.Args(cmd, []string{}); err == nil {
+		t.Error("Expected an error without a cluster name")
+	}
+
+	if flag := cmd.Flags().Lookup("timeout"); flag == nil || flag.DefValue != "30s" {
+		t.Errorf("Expected 'timeout' flag with default '30s', got %v", flag)
+	}
+}
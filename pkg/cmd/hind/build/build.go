@@ -21,6 +21,7 @@ const (
 
 func NewCommand(logger *log.Logger) *cobra.Command {
 	var timeout time.Duration
+	var version string
 
 	cmd := &cobra.Command{
 		Use:       fmt.Sprintf("build [%s]", strings.Join(image.BuildTargets(), "|")),
@@ -35,17 +36,18 @@ func NewCommand(logger *log.Logger) *cobra.Command {
 		},
 
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runE(cmd.Context(), logger, timeout, args)
+			return runE(cmd.Context(), logger, timeout, version, args)
 		},
 	}
 
 	cmd.Flags().DurationVar(&timeout, "timeout", DefaultBuildTimeout, "Timeout for building a single image")
+	cmd.Flags().StringVar(&version, "version", release.LatestAlias, "Hind release to build the images for")
 	// TODO: add cache/file cleanup/etc flags
 
 	return cmd
 }
 
-func runE(ctx context.Context, logger *log.Logger, timeout time.Duration, args []string) error {
+func runE(ctx context.Context, logger *log.Logger, timeout time.Duration, version string, args []string) error {
 	target := args[0]
 
 	var kinds []release.ImageKind
@@ -62,7 +64,7 @@ func runE(ctx context.Context, logger *log.Logger, timeout time.Duration, args [
 		defer cancel()
 
 		logger.WithField("timeout", timeout).Debug("Building image with timeout")
-		builder, err := image.NewBuilder(logger, k)
+		builder, err := image.NewBuilder(logger, k, version)
 		if err != nil {
 			return err
 		}
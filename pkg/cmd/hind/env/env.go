@@ -0,0 +1,134 @@
+// Package env implements the `env` command
+package env
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+	"github.com/stenh0use/hind/pkg/cluster"
+)
+
+// Shells the environment can be printed for
+const (
+	Bash       = "bash"
+	Zsh        = "zsh"
+	Fish       = "fish"
+	PowerShell = "powershell"
+	Dotenv     = "dotenv"
+)
+
+// Shells are the supported shell formats
+var Shells = []string{Bash, Zsh, Fish, PowerShell, Dotenv}
+
+// dotenvPlain matches the values written without quotes in dotenv files
+var dotenvPlain = regexp.MustCompile(`^[A-Za-z0-9_./:@%+,=-]*$`)
+
+// NewCommand creates the cluster env command
+func NewCommand(logger *log.Logger) *cobra.Command {
+	var shell string
+
+	cmd := &cobra.Command{
+		Use:   "env [cluster-name]",
+		Short: "Print the environment to use a hind cluster",
+		Long: strings.Join([]string{
+			"Print the exports of NOMAD_ADDR, CONSUL_HTTP_ADDR, VAULT_ADDR and",
+			"VAULT_TOKEN for the ports a hind cluster publishes, to configure the",
+			"CLIs on the host, eg. eval $(hind env dev). With ACLs enabled",
+			"NOMAD_TOKEN and CONSUL_HTTP_TOKEN are the management tokens, and",
+			"with TLS enabled NOMAD_CACERT, CONSUL_CACERT and VAULT_CACERT point",
+			"to the CA of the cluster. The shell defaults to the one in $SHELL.",
+		}, " "),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var clusterName string
+			if len(args) > 0 {
+				clusterName = args[0]
+			}
+			return runE(cmd.Context(), cmd.OutOrStdout(), logger, clusterName, shell)
+		},
+	}
+
+	cmd.Flags().StringVar(&shell, "shell", defaultShell(), "Shell format ("+strings.Join(Shells, "|")+")")
+
+	return cmd
+}
+
+func runE(ctx context.Context, w io.Writer, logger *log.Logger, clusterName, shell string) error {
+	if !slices.Contains(Shells, shell) {
+		return fmt.Errorf("unsupported shell '%s', expected one of %s", shell, strings.Join(Shells, ", "))
+	}
+
+	// If no cluster name provided, use the active cluster or fall back to "default"
+	clusterName = cluster.ResolveName(clusterName)
+
+	clusterMgr, err := cluster.New(logger, clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to create cluster manager: %w", err)
+	}
+
+	existed, err := clusterMgr.Load()
+	if err != nil {
+		return err
+	}
+	if !existed {
+		return fmt.Errorf("cluster '%s' not found", clusterName)
+	}
+
+	env, err := clusterMgr.ClientEnvironment(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to resolve the cluster endpoints: %w", err)
+	}
+	return writeEnv(w, shell, env)
+}
+
+// defaultShell returns the shell format of $SHELL, or bash
+func defaultShell() string {
+	shell := filepath.Base(os.Getenv("SHELL"))
+	switch shell {
+	case Zsh, Fish:
+		return shell
+	case "pwsh", PowerShell:
+		return PowerShell
+	}
+	return Bash
+}
+
+// writeEnv writes the environment as statements of the shell, sorted by
+// name
+func writeEnv(w io.Writer, shell string, env map[string]string) error {
+	for _, k := range slices.Sorted(maps.Keys(env)) {
+		v := env[k]
+
+		var line string
+		switch shell {
+		case Bash, Zsh:
+			line = fmt.Sprintf("export %s='%s'", k, strings.ReplaceAll(v, `'`, `'\''`))
+		case Fish:
+			v = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v)
+			line = fmt.Sprintf("set -gx %s '%s';", k, v)
+		case PowerShell:
+			line = fmt.Sprintf("$Env:%s = '%s'", k, strings.ReplaceAll(v, `'`, `''`))
+		case Dotenv:
+			if !dotenvPlain.MatchString(v) {
+				v = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+			}
+			line = fmt.Sprintf("%s=%s", k, v)
+		default:
+			return fmt.Errorf("unsupported shell '%s'", shell)
+		}
+
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
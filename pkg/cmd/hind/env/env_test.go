@@ -0,0 +1,98 @@
+package env
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+)
+
+func TestNewCommand(t *testing.T) {
+	logger := &log.Logger{
+		Handler: discard.New(),
+		Level:   log.ErrorLevel,
+	}
+
+	cmd := NewCommand(logger)
+
+	if cmd.Use != "env [cluster-name]" {
+		t.Errorf("Expected Use to be 'env [cluster-name]', got '%s'", cmd.Use)
+	}
+
+	if cmd.Flags().Lookup("shell") == nil {
+		t.Error("Expected 'shell' flag to exist")
+	}
+}
+
+func TestDefaultShell(t *testing.T) {
+	tests := []struct {
+		shell string
+		want  string
+	}{
+		{"/bin/bash", Bash},
+		{"/usr/bin/zsh", Zsh},
+		{"/opt/homebrew/bin/fish", Fish},
+		{"/usr/local/bin/pwsh", PowerShell},
+		{"/bin/sh", Bash},
+		{"", Bash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			t.Setenv("SHELL", tt.shell)
+			if got := defaultShell(); got != tt.want {
+				t.Errorf("defaultShell() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteEnv(t *testing.T) {
+	env := map[string]string{
+		"VAULT_TOKEN": "it's",
+		"NOMAD_ADDR":  "http://localhost:4646",
+	}
+
+	tests := []struct {
+		shell string
+		want  string
+	}{
+		{
+			shell: Bash,
+			want:  "export NOMAD_ADDR='http://localhost:4646'\nexport VAULT_TOKEN='it'\\''s'\n",
+		},
+		{
+			shell: Zsh,
+			want:  "export NOMAD_ADDR='http://localhost:4646'\nexport VAULT_TOKEN='it'\\''s'\n",
+		},
+		{
+			shell: Fish,
+			want:  "set -gx NOMAD_ADDR 'http://localhost:4646';\nset -gx VAULT_TOKEN 'it\\'s';\n",
+		},
+		{
+			shell: PowerShell,
+			want:  "$Env:NOMAD_ADDR = 'http://localhost:4646'\n$Env:VAULT_TOKEN = 'it''s'\n",
+		},
+		{
+			shell: Dotenv,
+			want:  "NOMAD_ADDR=http://localhost:4646\nVAULT_TOKEN=\"it's\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeEnv(&buf, tt.shell, env); err != nil {
+				t.Fatalf("writeEnv() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("writeEnv() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+
+	if err := writeEnv(&bytes.Buffer{}, "tcsh", env); err == nil {
+		t.Error("writeEnv(tcsh) error = nil, want error")
+	}
+}
@@ -0,0 +1,106 @@
+// Package exec implements the `exec` command
+package exec
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+	"github.com/stenh0use/hind/pkg/cluster"
+	"github.com/stenh0use/hind/pkg/provider"
+	"golang.org/x/term"
+)
+
+// NewCommand creates the node exec command
+func NewCommand(logger *log.Logger) *cobra.Command {
+	var tty bool
+
+	cmd := &cobra.Command{
+		Use:   "exec [cluster-name] <node> -- <command> [args...]",
+		Short: "Run a command in a hind cluster node",
+		Long: strings.Join([]string{
+			"Run a command in a node of a hind cluster, with NOMAD_ADDR,",
+			"CONSUL_HTTP_ADDR, VAULT_ADDR and VAULT_TOKEN set for the agent CLIs.",
+			"The node is a name such as client.02, or a kind or role to use its",
+			"first node, eg. vault. Stdin is attached and a terminal is",
+			"allocated when stdin is one.",
+		}, " "),
+		Args: validateArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dash := cmd.ArgsLenAtDash()
+			target := args[:dash]
+
+			var clusterName string
+			if len(target) > 1 {
+				clusterName = target[0]
+			}
+			return Run(cmd.Context(), logger, clusterName, target[len(target)-1], args[dash:], tty)
+		},
+	}
+
+	cmd.Flags().BoolVar(&tty, "tty", true, "Allocate a terminal when stdin is a terminal")
+
+	return cmd
+}
+
+// validateArgs checks for a node, optionally preceded by the cluster, and
+// a command after --
+func validateArgs(cmd *cobra.Command, args []string) error {
+	dash := cmd.ArgsLenAtDash()
+	if dash < 0 || dash == len(args) {
+		return fmt.Errorf("a command is required after --, eg. hind exec client.01 -- nomad node status")
+	}
+	if dash < 1 || dash > 2 {
+		return fmt.Errorf("expected [cluster-name] <node> before --, got %d args", dash)
+	}
+	return nil
+}
+
+// Run runs a command in the node of a cluster matching target, attached to
+// the standard streams. It runs in a terminal in raw mode when tty is set
+// and stdin and stdout are terminals.
+func Run(ctx context.Context, logger *log.Logger, clusterName, target string, command []string, tty bool) error {
+	// If no cluster name provided, use the active cluster or fall back to "default"
+	clusterName = cluster.ResolveName(clusterName)
+
+	clusterMgr, err := cluster.New(logger, clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to create cluster manager: %w", err)
+	}
+
+	existed, err := clusterMgr.Load()
+	if err != nil {
+		return err
+	}
+	if !existed {
+		return fmt.Errorf("cluster '%s' not found", clusterName)
+	}
+
+	opts := provider.ExecOptions{
+		Cmd:    command,
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	}
+
+	stdin := int(os.Stdin.Fd())
+	stdout := int(os.Stdout.Fd())
+	if tty && term.IsTerminal(stdin) && term.IsTerminal(stdout) {
+		opts.TTY = true
+		if width, height, err := term.GetSize(stdout); err == nil {
+			opts.ConsoleSize = &[2]uint{uint(height), uint(width)}
+		}
+
+		// Raw mode sends keys like ctrl-c to the command instead of hind
+		state, err := term.MakeRaw(stdin)
+		if err != nil {
+			return fmt.Errorf("failed to set raw mode: %w", err)
+		}
+		defer term.Restore(stdin, state)
+	}
+
+	return clusterMgr.Exec(ctx, target, opts)
+}
@@ -0,0 +1,59 @@
+package exec
+
+import (
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+)
+
+func TestNewCommand(t *testing.T) {
+	logger := &log.Logger{
+		Handler: discard.New(),
+		Level:   log.ErrorLevel,
+	}
+
+	cmd := NewCommand(logger)
+
+	if cmd.Use != "exec [cluster-name] <node> -- <command> [args...]" {
+		t.Errorf("Expected Use to be 'exec [cluster-name] <node> -- <command> [args...]', got '%s'", cmd.Use)
+	}
+
+	if f := cmd.Flags().Lookup("tty"); f == nil || f.DefValue != "true" {
+		t.Error("Expected 'tty' flag to default to true")
+	}
+}
+
+func TestCommandArgs(t *testing.T) {
+	logger := &log.Logger{
+		Handler: discard.New(),
+		Level:   log.ErrorLevel,
+	}
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantError bool
+	}{
+		{name: "node and command", args: []string{"client.01", "--", "nomad", "node", "status"}},
+		{name: "cluster, node and command", args: []string{"dev", "vault", "--", "vault", "status"}},
+		{name: "no dash", args: []string{"client.01", "hostname"}, wantError: true},
+		{name: "no command", args: []string{"client.01", "--"}, wantError: true},
+		{name: "no node", args: []string{"--", "hostname"}, wantError: true},
+		{name: "too many args", args: []string{"dev", "client.01", "extra", "--", "hostname"}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewCommand(logger)
+			// Parse the args so cobra records the position of --
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatalf("ParseFlags() error = %v", err)
+			}
+			err := cmd.Args(cmd, cmd.Flags().Args())
+			if (err != nil) != tt.wantError {
+				t.Errorf("Args validation error = %v, wantError %v", err, tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// Package federate implements the `federate` command
+package federate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+	"github.com/stenh0use/hind/pkg/cluster"
+)
+
+// NewCommand creates the federate command
+func NewCommand(logger *log.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "federate <cluster-name> <peer-name>",
+		Short: "Join the Consul servers of two hind clusters over the WAN",
+		Long: `Join the Consul servers of two running hind clusters over the WAN, for
+cross datacenter service discovery and prepared query failover. The clusters
+need their own datacenter, see start --datacenter. The Consul servers of each
+cluster are attached to the network of the other, the peer switches to the
+Consul gossip key of the cluster, and the federation is restored when either
+cluster starts again.
+
+Limitations: only Consul is federated, Nomad regions stay separate. Clusters
+started with --acl or --tls can't be federated, each bootstraps its own ACL
+tokens and generates its own CA. Create both clusters without them to
+federate.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFederate(cmd.Context(), logger, args[0], args[1])
+		},
+	}
+
+	return cmd
+}
+
+func runFederate(ctx context.Context, logger *log.Logger, clusterName, peerName string) error {
+	clusterMgr, err := cluster.New(logger, clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to create cluster manager: %w", err)
+	}
+
+	existed, err := clusterMgr.Load()
+	if err != nil {
+		return err
+	}
+	if !existed {
+		return fmt.Errorf("cluster '%s' not found", clusterName)
+	}
+
+	if err := clusterMgr.Federate(ctx, peerName); err != nil {
+		return fmt.Errorf("failed to federate cluster '%s' with '%s': %w", clusterName, peerName, err)
+	}
+	logger.Infof("Federated cluster '%s' with '%s'", clusterName, peerName)
+	return nil
+}
@@ -0,0 +1,34 @@
+package federate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+)
+
+func TestNewCommand(t *testing.T) {
+	logger := &log.Logger{
+		Handler: discard.New(),
+		Level:   log.ErrorLevel,
+	}
+
+	cmd := NewCommand(logger)
+
+	if cmd.Use != "federate <cluster-name> <peer-name>" {
+		t.Errorf("Expected Use to be 'federate <cluster-name> <peer-name>', got '%s'", cmd.Use)
+	}
+
+	// The ACL and TLS limitation is shown before federate refuses a cluster
+	if !strings.Contains(cmd.Long, "--acl or --tls can't be federated") {
+		t.Errorf("Expected the help to state that ACL and TLS clusters can't be federated")
+	}
+
+	if err := cmd.Args(cmd, []string{"dev"}); err == nil {
+		t.Errorf("Expected an error with a single cluster name")
+	}
+	if err := cmd.Args(cmd, []string{"dev", "prod"}); err != nil {
+		t.Errorf("Expected two cluster names to be accepted, got %v", err)
+	}
+}
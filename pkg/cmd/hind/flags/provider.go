@@ -0,0 +1,28 @@
+package flags
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stenh0use/hind/pkg/cluster"
+)
+
+// ProviderFlag is the name of the global provider flag
+const ProviderFlag = "provider"
+
+// AddProviderFlag adds the persistent --provider flag to the root command
+func AddProviderFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().String(ProviderFlag, cluster.DefaultProvider,
+		"Container provider for new clusters ("+strings.Join(cluster.Providers, "|")+")")
+}
+
+// Provider returns the provider selected with the --provider flag, or the
+// default provider when the command isn't run from the root command, eg. in
+// tests
+func Provider(cmd *cobra.Command) string {
+	if f := cmd.Flag(ProviderFlag); f != nil {
+		return f.Value.String()
+	}
+	return cluster.DefaultProvider
+}
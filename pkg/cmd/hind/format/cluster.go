@@ -0,0 +1,164 @@
+package format
+
+import (
+	"time"
+
+	"github.com/stenh0use/hind/pkg/cluster"
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+// NotFound is the status of a cluster or node without containers
+const NotFound = "not-found"
+
+// Cluster is the output schema of `hind get`. Every field is always set,
+// missing values are empty strings and lists.
+type Cluster struct {
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	Version    string     `json:"version"`
+	Provider   string     `json:"provider"`
+	Datacenter string     `json:"datacenter"`
+	Region     string     `json:"region"`
+	Network    Network    `json:"network"`
+	ACL        ACL        `json:"acl"`
+	TLS        TLS        `json:"tls"`
+	Federation []string   `json:"federation"`
+	Endpoints  []Endpoint `json:"endpoints"`
+	Nodes      []Node     `json:"nodes"`
+}
+
+// ACL is the ACL mode of the cluster and the management tokens hind
+// bootstrapped, empty until they are
+type ACL struct {
+	Enabled     bool   `json:"enabled"`
+	ConsulToken string `json:"consulToken"`
+	NomadToken  string `json:"nomadToken"`
+}
+
+// TLS is the TLS mode of the cluster and the path of its CA certificate on
+// the host, empty without TLS
+type TLS struct {
+	Enabled bool   `json:"enabled"`
+	CACert  string `json:"caCert"`
+}
+
+// Network is the cluster network
+type Network struct {
+	Name    string `json:"name"`
+	Subnet  string `json:"subnet"`
+	Gateway string `json:"gateway"`
+}
+
+// Endpoint is the address of a service API published on the host
+type Endpoint struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// Node is a node of the cluster and the state of its container
+type Node struct {
+	Name    string   `json:"name"`
+	Kind    string   `json:"kind"`
+	Role    string   `json:"role"`
+	Image   string   `json:"image"`
+	Status  string   `json:"status"`
+	ID      string   `json:"id"`
+	Address string   `json:"address"`
+	Ports   []string `json:"ports"`
+}
+
+// ClusterSummary is the output schema of a cluster in `hind list`
+type ClusterSummary struct {
+	Name         string `json:"name"`
+	Active       bool   `json:"active"`
+	Status       string `json:"status"`
+	Provider     string `json:"provider"`
+	Version      string `json:"version"`
+	RunningNodes int    `json:"runningNodes"`
+	TotalNodes   int    `json:"totalNodes"`
+	// Created is the creation time of the oldest container in RFC 3339
+	// format, empty when unknown
+	Created string `json:"created"`
+}
+
+// NewCluster builds the output of a cluster from its config and the state
+// reported by its provider. Nodes follow the order of the config. tokens is
+// nil when the ACLs weren't bootstrapped.
+func NewCluster(cfg *config.Cluster, info *provider.ClusterInfo, endpoints []cluster.Endpoint, tokens *cluster.ACLTokens) Cluster {
+	c := Cluster{
+		Name:       cfg.Name,
+		Status:     AggregateClusterStatus(info, cfg).Status,
+		Version:    cfg.Version,
+		Provider:   cfg.Provider,
+		Datacenter: cluster.Datacenter(cfg),
+		Region:     cluster.Region(cfg),
+		Network: Network{
+			Name:    cfg.Network.Name,
+			Subnet:  info.Network.Subnet,
+			Gateway: info.Network.Gateway,
+		},
+		ACL:        ACL{Enabled: cfg.ACL},
+		TLS:        TLS{Enabled: cfg.TLS},
+		Federation: append([]string{}, cfg.Federation...),
+		Endpoints:  []Endpoint{},
+		Nodes:      []Node{},
+	}
+	if cfg.ACL && tokens != nil {
+		c.ACL.ConsulToken = tokens.ConsulManagement
+		c.ACL.NomadToken = tokens.NomadManagement
+	}
+	if c.Provider == "" {
+		c.Provider = cluster.DefaultProvider
+	}
+	if info.Network.Name != "" {
+		c.Network.Name = info.Network.Name
+	}
+
+	for _, e := range endpoints {
+		c.Endpoints = append(c.Endpoints, Endpoint{Name: e.Name, URL: e.URL})
+	}
+
+	containers := make(map[string]provider.ContainerInfo, len(info.Containers))
+	for _, ci := range info.Containers {
+		containers[ci.Name] = ci
+	}
+	for _, n := range cfg.Nodes {
+		node := Node{
+			Name:   n.Name,
+			Kind:   n.Kind.String(),
+			Role:   n.Role.String(),
+			Image:  n.Image.Ref(),
+			Status: NotFound,
+			Ports:  []string{},
+		}
+		if ci, ok := containers[n.Name]; ok {
+			node.Status = ci.Status
+			node.ID = ci.ID
+			node.Address = ci.Address
+			if ci.Image != "" {
+				node.Image = ci.Image
+			}
+			node.Ports = append(node.Ports, ci.Ports...)
+		}
+		c.Nodes = append(c.Nodes, node)
+	}
+	return c
+}
+
+// NewClusterSummary builds the output of a cluster in a list
+func NewClusterSummary(name, providerName, version string, active bool, status *ClusterStatus) ClusterSummary {
+	s := ClusterSummary{
+		Name:         name,
+		Active:       active,
+		Status:       status.Status,
+		Provider:     providerName,
+		Version:      version,
+		RunningNodes: status.RunningNodes,
+		TotalNodes:   status.TotalNodes,
+	}
+	if !status.Created.IsZero() {
+		s.Created = status.Created.UTC().Format(time.RFC3339)
+	}
+	return s
+}
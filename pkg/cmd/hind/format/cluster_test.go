@@ -0,0 +1,141 @@
+package format
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stenh0use/hind/pkg/cluster"
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+func testConfig() *config.Cluster {
+	return &config.Cluster{
+		Name:    "dev",
+		Version: "0.4.0",
+		Network: config.Network{Name: "hind.dev"},
+		Nodes: []config.Node{
+			{
+				Name:  "hind.dev.consul.01",
+				Kind:  config.ConsulNode,
+				Role:  config.Server,
+				Image: config.Image{Name: "hind.consul.server", Tag: "0.4.0"},
+			},
+			{
+				Name:  "hind.dev.client.01",
+				Kind:  config.NomadNode,
+				Role:  config.Client,
+				Image: config.Image{Name: "hind.nomad.client", Tag: "0.4.0"},
+			},
+		},
+	}
+}
+
+func TestNewCluster(t *testing.T) {
+	info := &provider.ClusterInfo{
+		Network: provider.NetworkInfo{Name: "hind.dev", Subnet: "172.18.0.0/16", Gateway: "172.18.0.1"},
+		Containers: []provider.ContainerInfo{
+			{
+				ID:      "0123456789abcdef",
+				Name:    "hind.dev.consul.01",
+				Status:  provider.Running.String(),
+				Image:   "hind.consul.server:0.4.0",
+				Address: "172.18.0.2",
+				Ports:   []string{"8500:8500/tcp"},
+				Created: time.Now().Format(time.RFC3339),
+			},
+		},
+	}
+	endpoints := []cluster.Endpoint{{Name: "Consul", URL: "http://localhost:8500"}}
+
+	got := NewCluster(testConfig(), info, endpoints, nil)
+
+	if got.Status != "partial" {
+		t.Errorf("NewCluster() status = %v, want partial", got.Status)
+	}
+	if got.Provider != cluster.DefaultProvider {
+		t.Errorf("NewCluster() provider = %v, want %v", got.Provider, cluster.DefaultProvider)
+	}
+	if got.Federation == nil || len(got.Federation) != 0 {
+		t.Errorf("NewCluster() federation = %#v, want an empty list", got.Federation)
+	}
+	if got.Datacenter != cluster.DefaultDatacenter || got.Region != cluster.DefaultRegion {
+		t.Errorf("NewCluster() location = %s/%s, want %s/%s", got.Datacenter, got.Region, cluster.DefaultDatacenter, cluster.DefaultRegion)
+	}
+	if got.Network != (Network{Name: "hind.dev", Subnet: "172.18.0.0/16", Gateway: "172.18.0.1"}) {
+		t.Errorf("NewCluster() network = %+v", got.Network)
+	}
+	if len(got.Endpoints) != 1 || got.Endpoints[0].URL != "http://localhost:8500" {
+		t.Errorf("NewCluster() endpoints = %+v", got.Endpoints)
+	}
+	if len(got.Nodes) != 2 {
+		t.Fatalf("NewCluster() nodes = %d, want 2", len(got.Nodes))
+	}
+
+	consul := got.Nodes[0]
+	if consul.Kind != "consul" || consul.Role != "server" || consul.Status != "running" ||
+		consul.Address != "172.18.0.2" || consul.ID != "0123456789abcdef" || len(consul.Ports) != 1 {
+		t.Errorf("NewCluster() nodes[0] = %+v", consul)
+	}
+
+	client := got.Nodes[1]
+	if client.Status != NotFound || client.Image != "hind.nomad.client:0.4.0" || client.Ports == nil {
+		t.Errorf("NewCluster() nodes[1] = %+v, want not-found with config image and empty ports", client)
+	}
+}
+
+func TestNewCluster_Running(t *testing.T) {
+	cfg := testConfig()
+	info := &provider.ClusterInfo{}
+	for _, n := range cfg.Nodes {
+		info.Containers = append(info.Containers, provider.ContainerInfo{Name: n.Name, Status: provider.Running.String()})
+	}
+
+	if got := NewCluster(cfg, info, nil, nil); got.Status != "running" || got.Endpoints == nil {
+		t.Errorf("NewCluster() = %+v, want running with empty endpoints", got)
+	}
+}
+
+func TestNewCluster_ACL(t *testing.T) {
+	cfg := testConfig()
+	tokens := &cluster.ACLTokens{ConsulManagement: "consul-secret", ConsulAgent: "agent-secret", NomadManagement: "nomad-secret"}
+
+	// Stale tokens of a cluster without ACLs aren't shown
+	if got := NewCluster(cfg, &provider.ClusterInfo{}, nil, tokens); got.ACL != (ACL{}) {
+		t.Errorf("NewCluster() acl = %+v, want disabled", got.ACL)
+	}
+
+	cfg.ACL = true
+	want := ACL{Enabled: true, ConsulToken: "consul-secret", NomadToken: "nomad-secret"}
+	if got := NewCluster(cfg, &provider.ClusterInfo{}, nil, tokens); got.ACL != want {
+		t.Errorf("NewCluster() acl = %+v, want %+v", got.ACL, want)
+	}
+	if got := NewCluster(cfg, &provider.ClusterInfo{}, nil, nil); got.ACL != (ACL{Enabled: true}) {
+		t.Errorf("NewCluster() acl = %+v, want enabled without tokens", got.ACL)
+	}
+}
+
+func TestNewClusterSummary(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		status *ClusterStatus
+		want   string
+	}{
+		{"created", &ClusterStatus{Status: "running", Created: created}, "2026-01-02T03:04:05Z"},
+		{"unknown", &ClusterStatus{Status: "error"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewClusterSummary("dev", "podman", "0.4.0", true, tt.status)
+			if got.Created != tt.want {
+				t.Errorf("NewClusterSummary() created = %v, want %v", got.Created, tt.want)
+			}
+			if got.Status != tt.status.Status || got.Provider != "podman" || !got.Active {
+				t.Errorf("NewClusterSummary() = %+v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats selected with the global -o flag
+const (
+	Table = "table"
+	Wide  = "wide"
+	JSON  = "json"
+	YAML  = "yaml"
+)
+
+// OutputFlag is the name of the global output flag
+const OutputFlag = "output"
+
+// Formats are the supported output formats
+var Formats = []string{Table, Wide, JSON, YAML}
+
+// AddOutputFlag adds the persistent -o flag to the root command
+func AddOutputFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringP(OutputFlag, "o", Table,
+		"Output format ("+strings.Join(Formats, "|")+")")
+}
+
+// Output returns the output format selected with the -o flag, or table when
+// the command isn't run from the root command, eg. in tests
+func Output(cmd *cobra.Command) string {
+	if f := cmd.Flag(OutputFlag); f != nil {
+		return f.Value.String()
+	}
+	return Table
+}
+
+// Validate returns an error if format is not a supported output format
+func Validate(format string) error {
+	if !slices.Contains(Formats, format) {
+		return fmt.Errorf("unsupported output format '%s', expected one of %s", format, strings.Join(Formats, ", "))
+	}
+	return nil
+}
+
+// Structured reports whether format is a machine-readable format
+func Structured(format string) bool {
+	return format == JSON || format == YAML
+}
+
+// Write writes v as indented JSON or as YAML with the same keys
+func Write(w io.Writer, format string, v any) error {
+	switch format {
+	case JSON:
+		return WriteJSON(w, v)
+	case YAML:
+		return WriteYAML(w, v)
+	}
+	return fmt.Errorf("output format '%s' is not structured", format)
+}
+
+// WriteJSON writes v as indented JSON
+func WriteJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	return nil
+}
+
+// WriteYAML writes v as YAML. It goes through JSON so both formats share the
+// keys set by the json tags.
+func WriteYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode YAML: %w", err)
+	}
+
+	// JSON is valid YAML, decoding it keeps the key order
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	blockStyle(&doc)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// blockStyle clears the flow and quoted styles decoded from JSON, the
+// encoder still quotes strings that would read as another type
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
@@ -0,0 +1,87 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr bool
+	}{
+		{Table, false},
+		{Wide, false},
+		{JSON, false},
+		{YAML, false},
+		{"xml", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if err := Validate(tt.format); (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%q) error = %v, wantErr %v", tt.format, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWrite_SameSchema(t *testing.T) {
+	summary := ClusterSummary{
+		Name:         "dev",
+		Active:       true,
+		Status:       "running",
+		Provider:     "dockercli",
+		Version:      "1.10",
+		RunningNodes: 4,
+		TotalNodes:   4,
+	}
+
+	var jsonOut, yamlOut bytes.Buffer
+	if err := Write(&jsonOut, JSON, []ClusterSummary{summary}); err != nil {
+		t.Fatalf("Write(json) error = %v", err)
+	}
+	if err := Write(&yamlOut, YAML, []ClusterSummary{summary}); err != nil {
+		t.Fatalf("Write(yaml) error = %v", err)
+	}
+
+	var fromJSON, fromYAML []map[string]any
+	if err := json.Unmarshal(jsonOut.Bytes(), &fromJSON); err != nil {
+		t.Fatalf("Write(json) wrote invalid JSON: %v", err)
+	}
+	if err := yaml.Unmarshal(yamlOut.Bytes(), &fromYAML); err != nil {
+		t.Fatalf("Write(yaml) wrote invalid YAML: %v", err)
+	}
+
+	if len(fromYAML) != 1 {
+		t.Fatalf("Write(yaml) = %s, want one cluster", yamlOut.String())
+	}
+	for key, want := range fromJSON[0] {
+		got, ok := fromYAML[0][key]
+		if !ok {
+			t.Errorf("Write(yaml) is missing key %q", key)
+			continue
+		}
+		// JSON decodes every number as float64
+		if n, isInt := got.(int); isInt {
+			got = float64(n)
+		}
+		if got != want {
+			t.Errorf("Write(yaml) %s = %v (%T), want %v (%T)", key, got, got, want, want)
+		}
+	}
+
+	if bytes.Contains(yamlOut.Bytes(), []byte("{")) {
+		t.Errorf("Write(yaml) = %s, want block style", yamlOut.String())
+	}
+}
+
+func TestWrite_Unstructured(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, Table, nil); err == nil {
+		t.Error("Write(table) error = nil, want error")
+	}
+}
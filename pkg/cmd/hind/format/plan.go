@@ -0,0 +1,75 @@
+package format
+
+import "github.com/stenh0use/hind/pkg/cluster"
+
+// Plan is the output schema of `hind plan`, one change per network or
+// container in the order start makes them. Every field is always set,
+// missing values are empty strings and lists.
+type Plan struct {
+	Cluster string   `json:"cluster"`
+	Exists  bool     `json:"exists"`
+	Changes []Change `json:"changes"`
+}
+
+// Change is a change start would make to a network or container
+type Change struct {
+	// Action is create, recreate or start
+	Action string `json:"action"`
+	// Resource is network or container
+	Resource string `json:"resource"`
+	Name     string `json:"name"`
+	// Reason a container is recreated or started, eg. config_mismatch
+	Reason string `json:"reason"`
+	// Fields of the config a config_mismatch container differs in
+	Fields []string `json:"fields"`
+	// Image a created or recreated container runs
+	Image string `json:"image"`
+}
+
+// NewPlan builds the output of a reconcile plan for a cluster
+func NewPlan(name string, exists bool, plan *cluster.ReconcilePlan) Plan {
+	p := Plan{
+		Cluster: name,
+		Exists:  exists,
+		Changes: []Change{},
+	}
+
+	if plan.NetworkToCreate != nil {
+		p.Changes = append(p.Changes, Change{
+			Action:   "create",
+			Resource: "network",
+			Name:     plan.NetworkToCreate.Name,
+			Fields:   []string{},
+		})
+	}
+	for _, action := range plan.ContainersToRecreate {
+		p.Changes = append(p.Changes, Change{
+			Action:   "recreate",
+			Resource: "container",
+			Name:     action.ExistingName,
+			Reason:   action.Reason,
+			Fields:   append([]string{}, action.Changes...),
+			Image:    action.NewConfig.Image.Ref(),
+		})
+	}
+	for _, node := range plan.ContainersToCreate {
+		p.Changes = append(p.Changes, Change{
+			Action:   "create",
+			Resource: "container",
+			Name:     node.Name,
+			Fields:   []string{},
+			Image:    node.Image.Ref(),
+		})
+	}
+	for _, name := range plan.ContainersToStart {
+		p.Changes = append(p.Changes, Change{
+			Action:   "start",
+			Resource: "container",
+			Name:     name,
+			Reason:   "stopped",
+			Fields:   []string{},
+		})
+	}
+
+	return p
+}
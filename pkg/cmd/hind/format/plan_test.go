@@ -0,0 +1,45 @@
+package format
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stenh0use/hind/pkg/cluster"
+	"github.com/stenh0use/hind/pkg/config"
+)
+
+func TestNewPlan(t *testing.T) {
+	image := config.Image{Name: "hind.consul.server", Tag: "0.4.0"}
+	plan := &cluster.ReconcilePlan{
+		NetworkToCreate:    &config.Network{Name: "hind.dev"},
+		ContainersToCreate: []config.Node{{Name: "hind.dev.client.01", Image: image}},
+		ContainersToStart:  []string{"hind.dev.nomad.01"},
+		ContainersToRecreate: []cluster.RecreateAction{{
+			ExistingName: "hind.dev.consul.01",
+			NewConfig:    config.Node{Image: image},
+			Reason:       cluster.ReasonConfigMismatch,
+			Changes:      []string{"image"},
+		}},
+	}
+
+	got := NewPlan("dev", true, plan)
+	want := []Change{
+		{Action: "create", Resource: "network", Name: "hind.dev", Fields: []string{}},
+		{Action: "recreate", Resource: "container", Name: "hind.dev.consul.01", Reason: cluster.ReasonConfigMismatch, Fields: []string{"image"}, Image: image.Ref()},
+		{Action: "create", Resource: "container", Name: "hind.dev.client.01", Fields: []string{}, Image: image.Ref()},
+		{Action: "start", Resource: "container", Name: "hind.dev.nomad.01", Reason: "stopped", Fields: []string{}},
+	}
+	if got.Cluster != "dev" || !got.Exists {
+		t.Errorf("NewPlan() = %s exists %t, want dev exists", got.Cluster, got.Exists)
+	}
+	if !slices.EqualFunc(got.Changes, want, func(a, b Change) bool {
+		return a.Action == b.Action && a.Resource == b.Resource && a.Name == b.Name &&
+			a.Reason == b.Reason && a.Image == b.Image && slices.Equal(a.Fields, b.Fields) && a.Fields != nil
+	}) {
+		t.Errorf("NewPlan() changes = %+v, want %+v", got.Changes, want)
+	}
+
+	if empty := NewPlan("dev", false, &cluster.ReconcilePlan{}); empty.Changes == nil {
+		t.Error("NewPlan() changes = nil, want an empty list")
+	}
+}
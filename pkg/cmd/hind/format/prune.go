@@ -0,0 +1,30 @@
+package format
+
+import "github.com/stenh0use/hind/pkg/cluster"
+
+// Prune is the output schema of `hind prune`, the resources removed, or
+// that would be removed in a dry run. Resources is an empty list when there
+// is nothing to prune.
+type Prune struct {
+	DryRun    bool             `json:"dryRun"`
+	Resources []PrunedResource `json:"resources"`
+}
+
+// PrunedResource is a container, network or volume removed by prune
+type PrunedResource struct {
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Cluster string `json:"cluster"`
+}
+
+// NewPrune builds the output of the resources a prune removed
+func NewPrune(dryRun bool, pruned []cluster.PrunedResource) Prune {
+	p := Prune{
+		DryRun:    dryRun,
+		Resources: []PrunedResource{},
+	}
+	for _, r := range pruned {
+		p.Resources = append(p.Resources, PrunedResource{Kind: r.Kind, Name: r.Name, Cluster: r.Cluster})
+	}
+	return p
+}
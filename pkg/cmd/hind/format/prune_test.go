@@ -0,0 +1,34 @@
+package format
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/stenh0use/hind/pkg/cluster"
+)
+
+func TestNewPrune(t *testing.T) {
+	pruned := []cluster.PrunedResource{
+		{Kind: cluster.ContainerResource, Name: "hind.lost.consul.01", Cluster: "lost"},
+		{Kind: cluster.NetworkResource, Name: "hind.lost", Cluster: "lost"},
+	}
+
+	got := NewPrune(true, pruned)
+	want := []PrunedResource{
+		{Kind: "container", Name: "hind.lost.consul.01", Cluster: "lost"},
+		{Kind: "network", Name: "hind.lost", Cluster: "lost"},
+	}
+	if !got.DryRun || !slices.Equal(got.Resources, want) {
+		t.Errorf("NewPrune() = %+v, want dry run of %+v", got, want)
+	}
+
+	// Nothing to prune is an empty list, not null
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, NewPrune(false, nil)); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	if want := "{\n  \"dryRun\": false,\n  \"resources\": []\n}\n"; buf.String() != want {
+		t.Errorf("WriteJSON() = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,114 @@
+package format
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+// ClusterStatus holds aggregated cluster status information
+type ClusterStatus struct {
+	Status       string    // running, partial, stopped, degraded, not-found, orphaned
+	RunningNodes int       // Number of running containers
+	TotalNodes   int       // Total expected containers
+	Created      time.Time // Creation time of oldest container
+}
+
+// AggregateClusterStatus computes cluster-level status from container statuses
+func AggregateClusterStatus(info *provider.ClusterInfo, cfg *config.Cluster) *ClusterStatus {
+	status := &ClusterStatus{
+		TotalNodes: len(cfg.Nodes),
+	}
+
+	if len(info.Containers) == 0 {
+		status.Status = NotFound
+		return status
+	}
+
+	var (
+		runningCount = 0
+		stoppedCount = 0
+		errorCount   = 0
+		oldestTime   = time.Now()
+	)
+
+	for _, container := range info.Containers {
+		// Count status types
+		switch container.Status {
+		case provider.Running.String():
+			runningCount++
+		case provider.Stopped.String():
+			stoppedCount++
+		case provider.Error.String():
+			errorCount++
+		}
+
+		// Track oldest creation time
+		if created, err := ParseCreatedTime(container.Created); err == nil {
+			if created.Before(oldestTime) {
+				oldestTime = created
+			}
+		}
+	}
+
+	status.RunningNodes = runningCount
+	status.Created = oldestTime
+
+	// Determine overall status
+	if errorCount > 0 {
+		status.Status = "degraded"
+	} else if runningCount == len(info.Containers) && runningCount == status.TotalNodes {
+		status.Status = "running"
+	} else if stoppedCount == len(info.Containers) {
+		status.Status = "stopped"
+	} else {
+		status.Status = "partial"
+	}
+
+	return status
+}
+
+// ParseCreatedTime parses Docker's created time format
+func ParseCreatedTime(created string) (time.Time, error) {
+	// Docker returns times in various formats, handle common ones
+	layouts := []string{
+		time.RFC3339,
+		time.RFC3339Nano,
+		"2006-01-02 15:04:05 -0700 MST",
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, created); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unable to parse time: %s", created)
+}
+
+// FormatCreatedTime formats a timestamp as relative time
+func FormatCreatedTime(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+
+	duration := time.Since(t)
+
+	switch {
+	case duration < time.Minute:
+		return "just now"
+	case duration < time.Hour:
+		minutes := int(duration.Minutes())
+		return fmt.Sprintf("%dm ago", minutes)
+	case duration < 24*time.Hour:
+		hours := int(duration.Hours())
+		return fmt.Sprintf("%dh ago", hours)
+	case duration < 7*24*time.Hour:
+		days := int(duration.Hours() / 24)
+		return fmt.Sprintf("%dd ago", days)
+	default:
+		return t.Format("2006-01-02")
+	}
+}
@@ -2,14 +2,18 @@ package get
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"text/tabwriter"
 	"time"
 
 	"github.com/apex/log"
 	"github.com/spf13/cobra"
 	"github.com/stenh0use/hind/pkg/cluster"
+	"github.com/stenh0use/hind/pkg/cmd/hind/format"
 )
 
 // DefaultGetTimeout is the default timeout for getting a cluster
@@ -25,7 +29,7 @@ func NewCommand(logger *log.Logger) *cobra.Command {
 		Long:  "Get the details of a hind cluster and all it's resources",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runE(cmd.Context(), logger, timeout, args)
+			return runE(cmd.Context(), logger, timeout, format.Output(cmd), args)
 		},
 	}
 
@@ -34,7 +38,7 @@ func NewCommand(logger *log.Logger) *cobra.Command {
 	return cmd
 }
 
-func runE(ctx context.Context, logger *log.Logger, timeout time.Duration, args []string) error {
+func runE(ctx context.Context, logger *log.Logger, timeout time.Duration, output string, args []string) error {
 	clusterName := args[0]
 
 	// Create context with timeout
@@ -42,35 +46,130 @@ func runE(ctx context.Context, logger *log.Logger, timeout time.Duration, args [
 	defer cancel()
 
 	// Create cluster configuration
-	cluster, err := cluster.New(logger, clusterName)
+	clusterMgr, err := cluster.New(logger, clusterName)
 	if err != nil {
 		return fmt.Errorf("failed to create cluster manager: %w", err)
 	}
 
-	state, err := cluster.Get(getCtx)
+	// Use the persisted config, it holds the ports the cluster was created with
+	existed, err := clusterMgr.Load()
+	if err != nil {
+		return err
+	}
+	if !existed {
+		return fmt.Errorf("cluster '%s' does not exist", clusterName)
+	}
+
+	state, err := clusterMgr.Get(getCtx)
 	if err != nil {
 		return fmt.Errorf("failed to get cluster: %w", err)
 	}
 
-	// Print cluster information
-	fmt.Printf("---\nCluster: %s\n", cluster.Config().Name)
-	fmt.Printf("Status: created\n")
-	fmt.Printf("Network: %s\n", state.Network.Name)
+	// The tokens are missing until start bootstrapped the ACLs
+	tokens, err := clusterMgr.ACLTokens()
+	if err != nil && !errors.Is(err, cluster.ErrNoACLTokens) {
+		logger.Warnf("Failed to read acl tokens: %v", err)
+	}
+
+	endpoints, err := clusterMgr.Endpoints(getCtx)
+	if err != nil {
+		return fmt.Errorf("failed to resolve the cluster endpoints: %w", err)
+	}
+
+	out := format.NewCluster(clusterMgr.Config(), state, endpoints, tokens)
+	if out.TLS.Enabled {
+		out.TLS.CACert = clusterMgr.CACertFile()
+	}
+	if format.Structured(output) {
+		return format.Write(os.Stdout, output, out)
+	}
+	writeTable(os.Stdout, out, output == format.Wide)
+	return nil
+}
+
+// writeTable writes the cluster details followed by one row per node
+func writeTable(w io.Writer, c format.Cluster, wide bool) {
+	fmt.Fprintf(w, "---\nCluster: %s\n", c.Name)
+	fmt.Fprintf(w, "Status: %s\n", c.Status)
+	if wide {
+		fmt.Fprintf(w, "Version: %s\n", orDash(c.Version))
+		fmt.Fprintf(w, "Provider: %s\n", c.Provider)
+		fmt.Fprintf(w, "Datacenter: %s\n", c.Datacenter)
+		fmt.Fprintf(w, "Region: %s\n", c.Region)
+	}
+	fmt.Fprintf(w, "Network: %s\n", c.Network.Name)
+	if c.Network.Subnet != "" {
+		fmt.Fprintf(w, "Subnet: %s\n", c.Network.Subnet)
+		fmt.Fprintf(w, "Gateway: %s\n", orDash(c.Network.Gateway))
+	}
+	if c.ACL.Enabled {
+		fmt.Fprintln(w, "ACL: enabled")
+		if wide {
+			fmt.Fprintf(w, "Consul Token: %s\n", orDash(c.ACL.ConsulToken))
+			fmt.Fprintf(w, "Nomad Token: %s\n", orDash(c.ACL.NomadToken))
+		}
+	}
+	if c.TLS.Enabled {
+		fmt.Fprintln(w, "TLS: enabled")
+		if wide {
+			fmt.Fprintf(w, "CA Cert: %s\n", orDash(c.TLS.CACert))
+		}
+	}
+	if len(c.Federation) > 0 {
+		fmt.Fprintf(w, "Federation: %s\n", strings.Join(c.Federation, ", "))
+	}
+	for _, e := range c.Endpoints {
+		fmt.Fprintf(w, "%s: %s\n", e.Name, e.URL)
+	}
+
+	if len(c.Nodes) == 0 {
+		return
+	}
 
-	if len(state.Containers) > 0 {
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-		fmt.Fprintln(w, "\nNODE\tTYPE\tSTATE\tPORTS")
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	header := "\nNODE\tKIND\tROLE\tSTATE\tADDRESS\tPORTS"
+	if wide {
+		header += "\tIMAGE\tID"
+	}
+	fmt.Fprintln(tw, header)
 
-		for _, node := range state.Containers {
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
-				node.HostName,
-				node.Image,
-				node.Status,
-				node.Ports,
-			)
+	for _, node := range c.Nodes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s",
+			node.Name,
+			node.Kind,
+			node.Role,
+			node.Status,
+			orDash(node.Address),
+			formatPorts(node.Ports),
+		)
+		if wide {
+			fmt.Fprintf(tw, "\t%s\t%s", node.Image, orDash(shortID(node.ID)))
 		}
-		w.Flush()
+		fmt.Fprintln(tw)
 	}
+	tw.Flush()
+}
 
-	return nil
+// shortID returns the first 12 characters of a container ID
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// formatPorts formats the published ports of a node for display
+func formatPorts(ports []string) string {
+	if len(ports) == 0 {
+		return "-"
+	}
+	return strings.Join(ports, ", ")
+}
+
+// orDash returns s, or a dash when it is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
 }
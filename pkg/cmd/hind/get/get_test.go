@@ -1,11 +1,15 @@
 package get
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/apex/log"
 	"github.com/apex/log/handlers/discard"
+
+	"github.com/stenh0use/hind/pkg/cmd/hind/format"
 )
 
 func TestNewCommand(t *testing.T) {
@@ -95,3 +99,139 @@ func TestCommandArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteTable(t *testing.T) {
+	c := format.Cluster{
+		Name:       "dev",
+		Status:     "partial",
+		Version:    "0.4.0",
+		Provider:   "dockercli",
+		Datacenter: "dc2",
+		Region:     "eu",
+		Network:    format.Network{Name: "hind.dev", Subnet: "172.18.0.0/16", Gateway: "172.18.0.1"},
+		Nodes: []format.Node{
+			{Name: "hind.dev.consul.01", Kind: "consul", Role: "server", Image: "hind.consul.server:0.4.0",
+				Status: "running", ID: "0123456789abcdef", Address: "172.18.0.2", Ports: []string{"8500:8500/tcp"}},
+			{Name: "hind.dev.client.01", Kind: "nomad", Role: "client", Image: "hind.nomad.client:0.4.0",
+				Status: format.NotFound, Ports: []string{}},
+		},
+	}
+
+	tests := []struct {
+		name string
+		wide bool
+		want [][]string
+	}{
+		{
+			name: "table",
+			want: [][]string{
+				{"NODE", "KIND", "ROLE", "STATE", "ADDRESS", "PORTS"},
+				{"hind.dev.consul.01", "consul", "server", "running", "172.18.0.2", "8500:8500/tcp"},
+				{"hind.dev.client.01", "nomad", "client", "not-found", "-", "-"},
+			},
+		},
+		{
+			name: "wide",
+			wide: true,
+			want: [][]string{
+				{"NODE", "KIND", "ROLE", "STATE", "ADDRESS", "PORTS", "IMAGE", "ID"},
+				{"hind.dev.consul.01", "consul", "server", "running", "172.18.0.2", "8500:8500/tcp", "hind.consul.server:0.4.0", "0123456789ab"},
+				{"hind.dev.client.01", "nomad", "client", "not-found", "-", "-", "hind.nomad.client:0.4.0", "-"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeTable(&buf, c, tt.wide)
+
+			if !strings.Contains(buf.String(), "Status: partial\n") {
+				t.Errorf("writeTable() = %q, want the cluster status", buf.String())
+			}
+			if got := strings.Contains(buf.String(), "Datacenter: dc2\nRegion: eu\n"); got != tt.wide {
+				t.Errorf("writeTable() = %q, want the location only in wide output", buf.String())
+			}
+
+			_, table, _ := strings.Cut(buf.String(), "\n\n")
+			lines := strings.Split(table, "\n")
+			for i, fields := range tt.want {
+				if got := strings.Fields(lines[i]); strings.Join(got, " ") != strings.Join(fields, " ") {
+					t.Errorf("writeTable() line %d = %q, want %q", i, got, fields)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteTable_Federation(t *testing.T) {
+	c := format.Cluster{Name: "dev", Network: format.Network{Name: "hind.dev"}}
+
+	var buf bytes.Buffer
+	writeTable(&buf, c, false)
+	if strings.Contains(buf.String(), "Federation:") {
+		t.Errorf("writeTable() = %q, want no federation", buf.String())
+	}
+
+	buf.Reset()
+	c.Federation = []string{"prod", "staging"}
+	writeTable(&buf, c, false)
+	if !strings.Contains(buf.String(), "Federation: prod, staging\n") {
+		t.Errorf("writeTable() = %q, want the federated clusters", buf.String())
+	}
+}
+
+func TestWriteTable_ACL(t *testing.T) {
+	c := format.Cluster{
+		Name:    "dev",
+		Network: format.Network{Name: "hind.dev"},
+		ACL:     format.ACL{Enabled: true, ConsulToken: "consul-secret"},
+	}
+
+	tests := []struct {
+		name    string
+		wide    bool
+		want    []string
+		notWant []string
+	}{
+		{"table", false, []string{"ACL: enabled\n"}, []string{"consul-secret"}},
+		{"wide", true, []string{"ACL: enabled\n", "Consul Token: consul-secret\n", "Nomad Token: -\n"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeTable(&buf, c, tt.wide)
+			for _, s := range tt.want {
+				if !strings.Contains(buf.String(), s) {
+					t.Errorf("writeTable() = %q, want %q", buf.String(), s)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(buf.String(), s) {
+					t.Errorf("writeTable() = %q, don't want %q", buf.String(), s)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteTable_TLS(t *testing.T) {
+	c := format.Cluster{
+		Name:    "dev",
+		Network: format.Network{Name: "hind.dev"},
+		TLS:     format.TLS{Enabled: true, CACert: "/home/dev/.config/hind/cluster/dev/tls/ca.pem"},
+	}
+
+	var buf bytes.Buffer
+	writeTable(&buf, c, false)
+	if got := buf.String(); !strings.Contains(got, "TLS: enabled\n") || strings.Contains(got, "CA Cert") {
+		t.Errorf("writeTable() = %q, want TLS enabled without the CA", got)
+	}
+
+	buf.Reset()
+	writeTable(&buf, c, true)
+	if want := "CA Cert: " + c.TLS.CACert + "\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("writeTable() wide = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,70 @@
+// Package keyring implements the `keyring` command
+package keyring
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+	"github.com/stenh0use/hind/pkg/cluster"
+)
+
+// NewCommand creates the keyring command with subcommands
+func NewCommand(logger *log.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keyring",
+		Short: "Manage the gossip keys of a hind cluster",
+		Long:  "Manage the keys encrypting the gossip of the Consul agents and Nomad servers of a hind cluster",
+	}
+
+	cmd.AddCommand(newRotateCommand(logger))
+
+	return cmd
+}
+
+// newRotateCommand creates the 'keyring rotate' subcommand
+func newRotateCommand(logger *log.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate [cluster-name]",
+		Short: "Rotate the Consul and Nomad gossip keys",
+		Long: "Rotate the Consul and Nomad gossip keys of a running hind cluster. A new " +
+			"key is installed in every agent, made the primary key, and the old key " +
+			"is removed. The new keys are stored in the cluster's config directory " +
+			"and used by the nodes created afterwards.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var clusterName string
+			if len(args) > 0 {
+				clusterName = args[0]
+			}
+			return runRotate(cmd.Context(), logger, clusterName)
+		},
+	}
+
+	return cmd
+}
+
+func runRotate(ctx context.Context, logger *log.Logger, clusterName string) error {
+	// If no cluster name provided, use the active cluster or fall back to "default"
+	clusterName = cluster.ResolveName(clusterName)
+
+	clusterMgr, err := cluster.New(logger, clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to create cluster manager: %w", err)
+	}
+
+	existed, err := clusterMgr.Load()
+	if err != nil {
+		return err
+	}
+	if !existed {
+		return fmt.Errorf("cluster '%s' not found", clusterName)
+	}
+
+	if err := clusterMgr.RotateGossipKeys(ctx); err != nil {
+		return fmt.Errorf("failed to rotate the gossip keys of cluster '%s': %w", clusterName, err)
+	}
+	logger.Infof("Rotated the gossip keys of cluster '%s'", clusterName)
+	return nil
+}
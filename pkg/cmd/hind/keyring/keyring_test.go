@@ -0,0 +1,26 @@
+package keyring
+
+import (
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+)
+
+func TestNewCommand(t *testing.T) {
+	logger := &log.Logger{
+		Handler: discard.New(),
+		Level:   log.ErrorLevel,
+	}
+
+	cmd := NewCommand(logger)
+
+	if cmd.Use != "keyring" {
+		t.Errorf("Expected Use to be 'keyring', got '%s'", cmd.Use)
+	}
+
+	rotate, _, err := cmd.Find([]string{"rotate"})
+	if err != nil || rotate.Name() != "rotate" {
+		t.Errorf("Expected 'rotate' subcommand to exist, got %v", err)
+	}
+}
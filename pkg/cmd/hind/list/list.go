@@ -3,6 +3,7 @@ package list
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"text/tabwriter"
 	"time"
@@ -10,6 +11,8 @@ import (
 	"github.com/apex/log"
 	"github.com/spf13/cobra"
 	"github.com/stenh0use/hind/pkg/cluster"
+	"github.com/stenh0use/hind/pkg/cmd/hind/flags"
+	"github.com/stenh0use/hind/pkg/cmd/hind/format"
 	"github.com/stenh0use/hind/pkg/config"
 	"github.com/stenh0use/hind/pkg/provider"
 )
@@ -17,14 +20,6 @@ import (
 // DefaultListTimeout is the default timeout for listing clusters
 const DefaultListTimeout = 30 * time.Second
 
-// clusterStatus holds aggregated cluster status information
-type clusterStatus struct {
-	Status       string    // running, partial, stopped, degraded, not-found
-	RunningNodes int       // Number of running containers
-	TotalNodes   int       // Total expected containers
-	Created      time.Time // Creation time of oldest container
-}
-
 // NewCommand creates the cluster list command
 func NewCommand(logger *log.Logger) *cobra.Command {
 	var timeout time.Duration
@@ -35,7 +30,7 @@ func NewCommand(logger *log.Logger) *cobra.Command {
 		Long:  "List all hind clusters and their status",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runE(cmd.Context(), logger, timeout)
+			return runE(cmd.Context(), logger, timeout, format.Output(cmd), flags.Provider(cmd))
 		},
 	}
 
@@ -44,16 +39,19 @@ func NewCommand(logger *log.Logger) *cobra.Command {
 	return cmd
 }
 
-func runE(ctx context.Context, logger *log.Logger, timeout time.Duration) error {
+func runE(ctx context.Context, logger *log.Logger, timeout time.Duration, output, providerName string) error {
 	logger.WithField("timeout", timeout).Debug("Listing clusters with timeout")
 
-	// Get list of cluster names
-	clusters, err := cluster.List()
+	// Discover the clusters with a saved config and the ones found by label
+	discoverCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	clusters, err := cluster.Discover(discoverCtx, logger, providerName)
 	if err != nil {
 		return fmt.Errorf("failed getting cluster list: %w", err)
 	}
 
-	if len(clusters) == 0 {
+	if len(clusters) == 0 && !format.Structured(output) {
 		fmt.Println("No clusters found")
 		return nil
 	}
@@ -65,164 +63,132 @@ func runE(ctx context.Context, logger *log.Logger, timeout time.Duration) error
 	}
 
 	// Retrieve status for each cluster
-	clusterStatuses := make(map[string]*clusterStatus)
-	for _, clusterName := range clusters {
-		status, err := getClusterStatus(ctx, logger, clusterName, timeout)
+	summaries := []format.ClusterSummary{}
+	for _, d := range clusters {
+		if d.Orphaned() {
+			summaries = append(summaries, format.NewClusterSummary(d.Name, d.Provider,
+				orphanedVersion(d), d.Name == activeCluster, orphanedClusterStatus(d)))
+			continue
+		}
+
+		var version string
+		status, cfg, err := getClusterStatus(ctx, logger, d.Name, providerName, timeout)
 		if err != nil {
-			logger.Warnf("Failed to get status for cluster %s: %v", clusterName, err)
+			logger.Warnf("Failed to get status for cluster %s: %v", d.Name, err)
 			// Use error status as fallback
-			status = &clusterStatus{
+			status = &format.ClusterStatus{
 				Status:     "error",
 				TotalNodes: 0,
 			}
+		} else {
+			version = cfg.Version
 		}
-		clusterStatuses[clusterName] = status
+		summaries = append(summaries, format.NewClusterSummary(d.Name, d.Provider,
+			version, d.Name == activeCluster, status))
+	}
+
+	if format.Structured(output) {
+		return format.Write(os.Stdout, output, summaries)
 	}
+	writeTable(os.Stdout, summaries, output == format.Wide)
+	return nil
+}
 
-	// Print clusters in table format
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-	fmt.Fprintln(w, "NAME\tACTIVE\tSTATUS\tNODES\tCREATED")
+// writeTable writes one row per cluster, followed by hints on recovering
+// orphaned and not-found clusters
+func writeTable(w io.Writer, summaries []format.ClusterSummary, wide bool) {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	header := "NAME\tACTIVE\tSTATUS\tNODES\tCREATED"
+	if wide {
+		header += "\tPROVIDER\tVERSION"
+	}
+	fmt.Fprintln(tw, header)
 
-	for _, clusterName := range clusters {
-		status := clusterStatuses[clusterName]
+	var orphaned, missing bool
+	for _, s := range summaries {
+		orphaned = orphaned || s.Status == "orphaned"
+		missing = missing || s.Status == format.NotFound
 
 		activeIndicator := ""
-		if clusterName == activeCluster {
+		if s.Active {
 			activeIndicator = "*"
 		}
 
-		nodesDisplay := fmt.Sprintf("%d/%d", status.RunningNodes, status.TotalNodes)
-		if status.Status == "error" || status.Status == "not-found" {
+		nodesDisplay := fmt.Sprintf("%d/%d", s.RunningNodes, s.TotalNodes)
+		if s.Status == "error" || s.Status == format.NotFound {
 			nodesDisplay = "-"
 		}
 
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
-			clusterName,
+		// Created is empty or RFC 3339, the zero time displays as unknown
+		created, _ := time.Parse(time.RFC3339, s.Created)
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s",
+			s.Name,
 			activeIndicator,
-			status.Status,
+			s.Status,
 			nodesDisplay,
-			formatCreatedTime(status.Created),
+			format.FormatCreatedTime(created),
 		)
+		if wide {
+			version := s.Version
+			if version == "" {
+				version = "-"
+			}
+			fmt.Fprintf(tw, "\t%s\t%s", s.Provider, version)
+		}
+		fmt.Fprintln(tw)
 	}
 
-	w.Flush()
-	return nil
+	tw.Flush()
+
+	if orphaned {
+		fmt.Fprintln(w, "\nOrphaned clusters have containers but no config, recover them with 'hind adopt <name>'")
+	}
+	if missing {
+		fmt.Fprintln(w, "\nNot-found clusters have a config but no containers, recreate them with 'hind start <name>' or remove them with 'hind rm <name>'")
+	}
 }
 
-// getClusterStatus retrieves the status of a cluster with timeout
-func getClusterStatus(ctx context.Context, logger *log.Logger, clusterName string, timeout time.Duration) (*clusterStatus, error) {
+// getClusterStatus retrieves the status and config of a cluster with timeout
+func getClusterStatus(ctx context.Context, logger *log.Logger, clusterName, providerName string, timeout time.Duration) (*format.ClusterStatus, *config.Cluster, error) {
 	statusCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	// Create cluster manager
-	manager, err := cluster.New(logger, clusterName)
+	manager, err := cluster.New(logger, clusterName, cluster.WithProviderName(providerName))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create cluster manager: %w", err)
+		return nil, nil, fmt.Errorf("failed to create cluster manager: %w", err)
+	}
+	if _, err := manager.Load(); err != nil {
+		return nil, nil, err
 	}
 
 	// Get cluster info from manager
 	info, err := manager.Get(statusCtx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get cluster info: %w", err)
+		return nil, nil, fmt.Errorf("failed to get cluster info: %w", err)
 	}
 
 	// Aggregate status
-	return aggregateClusterStatus(info, manager.Config()), nil
+	return format.AggregateClusterStatus(info, manager.Config()), manager.Config(), nil
 }
 
-// aggregateClusterStatus computes cluster-level status from container statuses
-func aggregateClusterStatus(info *provider.ClusterInfo, cfg *config.Cluster) *clusterStatus {
-	status := &clusterStatus{
-		TotalNodes: len(cfg.Nodes),
-	}
-
-	if len(info.Containers) == 0 {
-		status.Status = "not-found"
-		return status
-	}
-
-	var (
-		runningCount = 0
-		stoppedCount = 0
-		errorCount   = 0
-		oldestTime   = time.Now()
-	)
-
-	for _, container := range info.Containers {
-		// Count status types
-		switch container.Status {
-		case provider.Running.String():
-			runningCount++
-		case provider.Stopped.String():
-			stoppedCount++
-		case provider.Error.String():
-			errorCount++
-		}
-
-		// Track oldest creation time
-		if created, err := parseCreatedTime(container.Created); err == nil {
-			if created.Before(oldestTime) {
-				oldestTime = created
-			}
-		}
-	}
-
-	status.RunningNodes = runningCount
-	status.Created = oldestTime
-
-	// Determine overall status
-	if errorCount > 0 {
-		status.Status = "degraded"
-	} else if runningCount == len(info.Containers) && runningCount == status.TotalNodes {
-		status.Status = "running"
-	} else if stoppedCount == len(info.Containers) {
-		status.Status = "stopped"
-	} else {
-		status.Status = "partial"
-	}
-
+// orphanedClusterStatus computes the status of a cluster without a config
+// from the containers found by its label
+func orphanedClusterStatus(d cluster.DiscoveredCluster) *format.ClusterStatus {
+	status := format.AggregateClusterStatus(&provider.ClusterInfo{Containers: d.Containers}, &config.Cluster{})
+	status.Status = "orphaned"
+	status.TotalNodes = len(d.Containers)
 	return status
 }
 
-// parseCreatedTime parses Docker's created time format
-func parseCreatedTime(created string) (time.Time, error) {
-	// Docker returns times in various formats, handle common ones
-	layouts := []string{
-		time.RFC3339,
-		time.RFC3339Nano,
-		"2006-01-02 15:04:05 -0700 MST",
-	}
-
-	for _, layout := range layouts {
-		if t, err := time.Parse(layout, created); err == nil {
-			return t, nil
+// orphanedVersion returns the hind version the containers of a cluster
+// without a config are labeled with
+func orphanedVersion(d cluster.DiscoveredCluster) string {
+	for _, c := range d.Containers {
+		if v := c.Labels[cluster.VersionLabel]; v != "" {
+			return v
 		}
 	}
-
-	return time.Time{}, fmt.Errorf("unable to parse time: %s", created)
-}
-
-// formatCreatedTime formats a timestamp as relative time
-func formatCreatedTime(t time.Time) string {
-	if t.IsZero() {
-		return "unknown"
-	}
-
-	duration := time.Since(t)
-
-	switch {
-	case duration < time.Minute:
-		return "just now"
-	case duration < time.Hour:
-		minutes := int(duration.Minutes())
-		return fmt.Sprintf("%dm ago", minutes)
-	case duration < 24*time.Hour:
-		hours := int(duration.Hours())
-		return fmt.Sprintf("%dh ago", hours)
-	case duration < 7*24*time.Hour:
-		days := int(duration.Hours() / 24)
-		return fmt.Sprintf("%dd ago", days)
-	default:
-		return t.Format("2006-01-02")
-	}
+	return ""
 }
@@ -0,0 +1,64 @@
+// Package logs implements the `logs` command
+package logs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+	"github.com/stenh0use/hind/pkg/cluster"
+)
+
+// NewCommand creates the cluster logs command
+func NewCommand(logger *log.Logger) *cobra.Command {
+	var opts cluster.LogsOptions
+
+	cmd := &cobra.Command{
+		Use:   "logs [cluster-name] <node|kind|role>",
+		Short: "Show the service logs of hind cluster nodes",
+		Long: strings.Join([]string{
+			"Show the journal of a service on the nodes of a hind cluster. The",
+			"target is a node name such as client.02, a kind (consul, nomad,",
+			"vault), a role (server, client) or all. The lines of each node are",
+			"prefixed with its name when more than one node matches. Each node",
+			"shows the agent of its kind unless --service is set.",
+		}, " "),
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var clusterName string
+			if len(args) > 1 {
+				clusterName = args[0]
+			}
+			return runE(cmd.Context(), logger, clusterName, args[len(args)-1], opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Service, "service", "s", "", "Systemd service to show, eg. nomad, consul, vault, docker or cilium")
+	cmd.Flags().BoolVarP(&opts.Follow, "follow", "f", false, "Follow the logs until interrupted")
+	cmd.Flags().StringVar(&opts.Since, "since", "", "Only show entries newer than this, eg. '10 min ago' or '2025-01-02 15:04'")
+
+	return cmd
+}
+
+func runE(ctx context.Context, logger *log.Logger, clusterName, target string, opts cluster.LogsOptions) error {
+	// If no cluster name provided, use the active cluster or fall back to "default"
+	clusterName = cluster.ResolveName(clusterName)
+
+	clusterMgr, err := cluster.New(logger, clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to create cluster manager: %w", err)
+	}
+
+	existed, err := clusterMgr.Load()
+	if err != nil {
+		return err
+	}
+	if !existed {
+		return fmt.Errorf("cluster '%s' not found", clusterName)
+	}
+
+	return clusterMgr.Logs(ctx, target, opts, os.Stdout, os.Stderr)
+}
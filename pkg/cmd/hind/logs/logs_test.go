@@ -0,0 +1,58 @@
+package logs
+
+import (
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+)
+
+func TestNewCommand(t *testing.T) {
+	logger := &log.Logger{
+		Handler: discard.New(),
+		Level:   log.ErrorLevel,
+	}
+
+	cmd := NewCommand(logger)
+
+	if cmd.Use != "logs [cluster-name] <node|kind|role>" {
+		t.Errorf("Expected Use to be 'logs [cluster-name] <node|kind|role>', got '%s'", cmd.Use)
+	}
+
+	for _, name := range []string{"service", "follow", "since"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Errorf("Expected '%s' flag to exist", name)
+		}
+	}
+	if cmd.Flags().ShorthandLookup("f") == nil {
+		t.Error("Expected '-f' shorthand for follow")
+	}
+}
+
+func TestCommandArgs(t *testing.T) {
+	logger := &log.Logger{
+		Handler: discard.New(),
+		Level:   log.ErrorLevel,
+	}
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantError bool
+	}{
+		{name: "no args", args: []string{}, wantError: true},
+		{name: "target", args: []string{"client.01"}, wantError: false},
+		{name: "cluster and target", args: []string{"dev", "nomad"}, wantError: false},
+		{name: "too many args", args: []string{"dev", "nomad", "consul"}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewCommand(logger)
+			err := cmd.Args(cmd, tt.args)
+			if (err != nil) != tt.wantError {
+				t.Errorf("Args validation error = %v, wantError %v", err, tt.wantError)
+			}
+		})
+	}
+}
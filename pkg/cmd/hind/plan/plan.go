@@ -0,0 +1,178 @@
+// Package plan implements the `plan` command
+package plan
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+	"github.com/stenh0use/hind/pkg/cluster"
+	"github.com/stenh0use/hind/pkg/cmd/hind/flags"
+	"github.com/stenh0use/hind/pkg/cmd/hind/format"
+	"github.com/stenh0use/hind/pkg/cmd/hind/start"
+)
+
+// DefaultPlanTimeout is the default timeout for planning a cluster
+const DefaultPlanTimeout = 2 * time.Minute
+
+// NewCommand creates the cluster plan command
+func NewCommand(logger *log.Logger) *cobra.Command {
+	var (
+		configFile string
+		timeout    time.Duration
+		create     start.CreateOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "plan [cluster-name]",
+		Short: "Show the changes start would make to a hind cluster",
+		Long: strings.Join([]string{
+			"Compare the desired configuration of a hind cluster with the running",
+			"containers and show the changes 'hind start' would make, without",
+			"making them. Plan takes the same flags as start, a new cluster is",
+			"previewed with the version, topology, ACL, TLS and location they set.",
+		}, " "),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var clusterName string
+			if len(args) > 0 {
+				clusterName = args[0]
+			}
+			return runE(cmd, cmd.Context(), logger, planConfig{
+				clusterName: clusterName,
+				configFile:  configFile,
+				timeout:     timeout,
+				create:      create,
+				output:      format.Output(cmd),
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&configFile, "config", "", "Path to a cluster definition file (YAML, JSON or HCL)")
+	cmd.Flags().DurationVar(&timeout, "timeout", DefaultPlanTimeout, "Timeout for planning the cluster")
+	start.AddCreateFlags(cmd, &create)
+
+	return cmd
+}
+
+type planConfig struct {
+	clusterName string
+	configFile  string
+	timeout     time.Duration
+	create      start.CreateOptions
+	output      string
+}
+
+func runE(cmd *cobra.Command, ctx context.Context, logger *log.Logger, cfg planConfig) error {
+	if err := cfg.create.Validate(cmd, cfg.configFile); err != nil {
+		return err
+	}
+
+	mgr, existed, err := desiredState(logger, cmd, cfg)
+	if err != nil {
+		return err
+	}
+
+	planCtx, cancel := context.WithTimeout(ctx, cfg.timeout)
+	defer cancel()
+
+	plan, err := mgr.Plan(planCtx)
+	if err != nil {
+		return fmt.Errorf("failed to plan cluster '%s': %w", mgr.Config().Name, err)
+	}
+
+	out := format.NewPlan(mgr.Config().Name, existed, plan)
+	if format.Structured(cfg.output) {
+		return format.Write(os.Stdout, cfg.output, out)
+	}
+
+	status := "exists"
+	if !existed {
+		status = "will be created"
+	}
+	fmt.Printf("Cluster: %s (%s)\n", out.Cluster, status)
+	writeTable(os.Stdout, out)
+	return nil
+}
+
+// desiredState creates a cluster manager holding the configuration start
+// would reconcile to: the definition file, or the persisted config of an
+// existing cluster, or a new one created from the start flags.
+func desiredState(logger *log.Logger, cmd *cobra.Command, cfg planConfig) (*cluster.Manager, bool, error) {
+	clusterName := cfg.clusterName
+
+	if cfg.configFile != "" {
+		def, err := cluster.LoadDefinition(cfg.configFile, clusterName, flags.Provider(cmd))
+		if err != nil {
+			return nil, false, err
+		}
+
+		mgr, err := cluster.New(logger, def.Name, cluster.WithProviderName(def.Provider))
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to create cluster manager: %w", err)
+		}
+		if err := mgr.SetConfig(def); err != nil {
+			return nil, false, err
+		}
+		return mgr, mgr.ConfigFileExists(), nil
+	}
+
+	// If no cluster name provided, use the active cluster or fall back to "default"
+	clusterName = cluster.ResolveName(clusterName)
+
+	mgr, err := cluster.New(logger, clusterName, cfg.create.ManagerOptions(cmd)...)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create cluster manager: %w", err)
+	}
+
+	existed, err := mgr.Load()
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Scaling only applies to existing clusters, new clusters use the topology
+	if existed && cmd.Flags().Changed("clients") {
+		if err := mgr.ResizeClients(cfg.create.Topology.NomadClients); err != nil {
+			return nil, false, err
+		}
+	}
+
+	// New clusters publish their ports clear of other clusters, like start
+	if !existed {
+		if err := mgr.AllocateHostPorts(); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return mgr, existed, nil
+}
+
+// writeTable writes one row per change in the plan
+func writeTable(w io.Writer, plan format.Plan) {
+	if len(plan.Changes) == 0 {
+		fmt.Fprintln(w, "No changes, cluster state matches desired configuration")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "ACTION\tRESOURCE\tNAME\tREASON")
+
+	for _, c := range plan.Changes {
+		reason := c.Reason
+		if reason == "" {
+			reason = "-"
+		}
+		if len(c.Fields) > 0 {
+			reason = fmt.Sprintf("%s (%s)", reason, strings.Join(c.Fields, ", "))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c.Action, c.Resource, c.Name, reason)
+	}
+
+	tw.Flush()
+}
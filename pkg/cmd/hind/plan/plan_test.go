@@ -0,0 +1,198 @@
+package plan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+	"github.com/spf13/cobra"
+
+	"github.com/stenh0use/hind/pkg/cluster"
+	"github.com/stenh0use/hind/pkg/cmd/hind/flags"
+	"github.com/stenh0use/hind/pkg/cmd/hind/format"
+	"github.com/stenh0use/hind/pkg/cmd/hind/start"
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+	"github.com/stenh0use/hind/pkg/provider/fake"
+)
+
+func TestNewCommand(t *testing.T) {
+	logger := &log.Logger{
+		Handler: discard.New(),
+		Level:   log.ErrorLevel,
+	}
+
+	cmd := NewCommand(logger)
+
+	if cmd.Use != "plan [cluster-name]" {
+		t.Errorf("Expected Use to be 'plan [cluster-name]', got '%s'", cmd.Use)
+	}
+
+	for _, name := range []string{"config", "timeout", "clients", "version", "consul-servers", "acl", "tls", "datacenter", "region"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Errorf("Expected '%s' flag to exist", name)
+		}
+	}
+}
+
+func testPlan() *cluster.ReconcilePlan {
+	return &cluster.ReconcilePlan{
+		NetworkToCreate:    &config.Network{Name: "hind.dev"},
+		ContainersToCreate: []config.Node{{Name: "hind.dev.client.02"}},
+		ContainersToStart:  []string{"hind.dev.nomad.01"},
+		ContainersToRecreate: []cluster.RecreateAction{
+			{
+				ExistingName: "hind.dev.consul.01",
+				Reason:       cluster.ReasonConfigMismatch,
+				Changes:      []string{"image", "env"},
+			},
+		},
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	var buf bytes.Buffer
+	writeTable(&buf, format.NewPlan("dev", true, testPlan()))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("writeTable() wrote %d lines, want 5:\n%s", len(lines), buf.String())
+	}
+
+	want := [][]string{
+		{"ACTION", "RESOURCE", "NAME", "REASON"},
+		{"create", "network", "hind.dev", "-"},
+		{"recreate", "container", "hind.dev.consul.01", "config_mismatch", "(image,", "env)"},
+		{"create", "container", "hind.dev.client.02", "-"},
+		{"start", "container", "hind.dev.nomad.01", "stopped"},
+	}
+	for i, fields := range want {
+		if got := strings.Fields(lines[i]); strings.Join(got, " ") != strings.Join(fields, " ") {
+			t.Errorf("writeTable() line %d = %q, want %q", i, got, fields)
+		}
+	}
+}
+
+func TestWriteTable_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	writeTable(&buf, format.NewPlan("dev", true, &cluster.ReconcilePlan{}))
+
+	if !strings.Contains(buf.String(), "No changes") {
+		t.Errorf("writeTable() = %q, want no changes message", buf.String())
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := format.WriteJSON(&buf, format.NewPlan("dev", false, testPlan())); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+
+	// Every key of the schema is camelCase, nested config types included
+	var got struct {
+		Cluster string           `json:"cluster"`
+		Exists  bool             `json:"exists"`
+		Changes []map[string]any `json:"changes"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("WriteJSON() wrote invalid JSON: %v", err)
+	}
+	if got.Cluster != "dev" || got.Exists || len(got.Changes) != 4 {
+		t.Fatalf("WriteJSON() = %s, want 4 changes to the new cluster dev", buf.String())
+	}
+	for _, c := range got.Changes {
+		keys := slices.Sorted(maps.Keys(c))
+		if want := []string{"action", "fields", "image", "name", "reason", "resource"}; !slices.Equal(keys, want) {
+			t.Errorf("change keys = %v, want %v", keys, want)
+		}
+	}
+	if got.Changes[1]["action"] != "recreate" || got.Changes[1]["reason"] != cluster.ReasonConfigMismatch {
+		t.Errorf("changes[1] = %v, want a config_mismatch recreate", got.Changes[1])
+	}
+}
+
+func TestDesiredState_NewClusterPorts(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	logger := &log.Logger{Handler: discard.New(), Level: log.ErrorLevel}
+
+	// Vault of the first cluster is initialised, start unseals it
+	p := fake.New(fake.WithExec(func(_ context.Context, _ string, opts provider.ExecOptions) error {
+		io.WriteString(opts.Stdout, "VAULT_ROOT_TOKEN=hvs.root\nVAULT_UNSEAL_KEY=key\n")
+		return nil
+	}))
+	first, err := cluster.New(logger, "first", cluster.WithProvider(p), cluster.WithReadinessChecks(false))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := first.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// A new cluster is planned with the ports start would allocate
+	cmd, create := createFlags(t)
+	mgr, existed, err := desiredState(logger, cmd, planConfig{clusterName: "second", create: *create})
+	if err != nil {
+		t.Fatalf("desiredState() error = %v", err)
+	}
+	if existed {
+		t.Fatal("desiredState() existed = true, want false")
+	}
+	taken := map[int32]bool{}
+	for _, n := range first.Config().Nodes {
+		for _, p := range n.Ports {
+			taken[p.HostPort] = true
+		}
+	}
+	for _, n := range mgr.Config().Nodes {
+		for _, p := range n.Ports {
+			if p.HostPort != 0 && taken[p.HostPort] {
+				t.Errorf("planned host port %d of %s is published by cluster first", p.HostPort, n.Name)
+			}
+		}
+	}
+}
+
+// createFlags parses the start flags describing a new cluster
+func createFlags(t *testing.T, args ...string) (*cobra.Command, *start.CreateOptions) {
+	t.Helper()
+	cmd := &cobra.Command{}
+	create := &start.CreateOptions{}
+	start.AddCreateFlags(cmd, create)
+	flags.AddProviderFlag(cmd)
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatalf("ParseFlags() error = %v", err)
+	}
+	return cmd, create
+}
+
+func TestDesiredState_NewClusterFlags(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	logger := &log.Logger{Handler: discard.New(), Level: log.ErrorLevel}
+
+	cmd, create := createFlags(t, "--consul-servers", "3", "--clients", "2", "--acl", "--tls", "--datacenter", "dc2", "--region", "eu", "--provider", "podman")
+	mgr, _, err := desiredState(logger, cmd, planConfig{clusterName: "dev", create: *create})
+	if err != nil {
+		t.Fatalf("desiredState() error = %v", err)
+	}
+
+	cfg := mgr.Config()
+	if !cfg.ACL || !cfg.TLS || cluster.Datacenter(cfg) != "dc2" || cluster.Region(cfg) != "eu" {
+		t.Errorf("desiredState() acl %t, tls %t, location %s/%s, want the flags", cfg.ACL, cfg.TLS, cluster.Datacenter(cfg), cluster.Region(cfg))
+	}
+	if cfg.Provider != cluster.PodmanProvider {
+		t.Errorf("desiredState() provider = %q, want %q", cfg.Provider, cluster.PodmanProvider)
+	}
+	if got := mgr.CountServerNodes(config.ConsulNode); got != 3 {
+		t.Errorf("desiredState() consul servers = %d, want 3", got)
+	}
+	if got := mgr.CountClientNodes(); got != 2 {
+		t.Errorf("desiredState() clients = %d, want 2", got)
+	}
+}
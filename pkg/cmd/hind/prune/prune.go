@@ -0,0 +1,90 @@
+package prune
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+	"github.com/stenh0use/hind/pkg/cluster"
+	"github.com/stenh0use/hind/pkg/cmd/hind/flags"
+	"github.com/stenh0use/hind/pkg/cmd/hind/format"
+)
+
+// DefaultPruneTimeout is the default timeout for pruning resources
+const DefaultPruneTimeout = 2 * time.Minute
+
+// NewCommand creates the prune command
+func NewCommand(logger *log.Logger) *cobra.Command {
+	var (
+		timeout time.Duration
+		opts    cluster.PruneOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove leftover hind resources",
+		Long: `Remove the containers, networks and anonymous volumes labeled by hind
+that belong to no saved cluster, eg. after an interrupted start or rm.
+Named volumes hold cluster data and are only removed with --all.
+Only the provider selected by --provider is searched, resources created
+with another provider are pruned by running prune again with it, eg.
+hind prune --provider podman.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Provider = flags.Provider(cmd)
+			return runE(cmd.Context(), logger, timeout, format.Output(cmd), opts)
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", DefaultPruneTimeout, "Timeout for pruning resources")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Show the resources that would be removed without removing them")
+	cmd.Flags().BoolVar(&opts.All, "all", false, "Also remove named volumes")
+	cmd.Flags().StringVar(&opts.Cluster, "cluster", "", "Only remove the resources of this cluster")
+	cmd.Flags().StringVar(&opts.Version, "version", "", "Only remove the resources of this hind release")
+
+	return cmd
+}
+
+func runE(ctx context.Context, logger *log.Logger, timeout time.Duration, output string, opts cluster.PruneOptions) error {
+	pruneCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// The resources removed before a failure are reported with the error
+	pruned, err := cluster.Prune(pruneCtx, logger, opts)
+
+	out := format.NewPrune(opts.DryRun, pruned)
+	if format.Structured(output) {
+		if werr := format.Write(os.Stdout, output, out); werr != nil {
+			return werr
+		}
+	} else {
+		writeTable(os.Stdout, out)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to prune resources: %w", err)
+	}
+	return nil
+}
+
+// writeTable writes one row per resource removed, or that would be removed
+func writeTable(w io.Writer, p format.Prune) {
+	if len(p.Resources) == 0 {
+		fmt.Fprintln(w, "Nothing to prune")
+		return
+	}
+	if p.DryRun {
+		fmt.Fprintln(w, "Would remove:")
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tNAME\tCLUSTER")
+	for _, r := range p.Resources {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Kind, r.Name, r.Cluster)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,71 @@
+package prune
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+	"github.com/stenh0use/hind/pkg/cluster"
+	"github.com/stenh0use/hind/pkg/cmd/hind/format"
+)
+
+func TestNewCommand(t *testing.T) {
+	logger := &log.Logger{
+		Handler: discard.New(),
+		Level:   log.ErrorLevel,
+	}
+
+	cmd := NewCommand(logger)
+
+	if cmd.Use != "prune" {
+		t.Errorf("Expected Use to be 'prune', got '%s'", cmd.Use)
+	}
+
+	for _, name := range []string{"timeout", "dry-run", "all", "cluster", "version"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Errorf("Expected '%s' flag to exist", name)
+		}
+	}
+
+	if err := cmd.Args(cmd, []string{"dev"}); err == nil {
+		t.Error("Expected an error with arguments")
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	tests := []struct {
+		name  string
+		prune format.Prune
+		want  []string
+	}{
+		{
+			name:  "nothing",
+			prune: format.NewPrune(false, nil),
+			want:  []string{"Nothing to prune"},
+		},
+		{
+			name: "dry run",
+			prune: format.NewPrune(true, []cluster.PrunedResource{
+				{Kind: cluster.NetworkResource, Name: "hind.lost", Cluster: "lost"},
+			}),
+			want: []string{"Would remove:", "KIND NAME CLUSTER", "network hind.lost lost"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeTable(&buf, tt.prune)
+
+			var got []string
+			for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+				got = append(got, strings.Join(strings.Fields(line), " "))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("writeTable() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
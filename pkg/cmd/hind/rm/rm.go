@@ -8,6 +8,7 @@ import (
 	"github.com/apex/log"
 	"github.com/spf13/cobra"
 	"github.com/stenh0use/hind/pkg/cluster"
+	"github.com/stenh0use/hind/pkg/cmd/hind/flags"
 )
 
 // DefaultDeleteTimeout is the default timeout for destroying a cluster
@@ -15,7 +16,10 @@ const DefaultDeleteTimeout = 2 * time.Minute
 
 // NewCommand creates the cluster delete command
 func NewCommand(logger *log.Logger) *cobra.Command {
-	var timeout time.Duration
+	var (
+		timeout     time.Duration
+		keepVolumes bool
+	)
 
 	cmd := &cobra.Command{
 		Use:   "rm [cluster-name]",
@@ -27,16 +31,17 @@ func NewCommand(logger *log.Logger) *cobra.Command {
 			if len(args) > 0 {
 				clusterName = args[0]
 			}
-			return runE(cmd.Context(), logger, timeout, clusterName)
+			return runE(cmd.Context(), logger, timeout, clusterName, flags.Provider(cmd), keepVolumes)
 		},
 	}
 
 	cmd.Flags().DurationVar(&timeout, "timeout", DefaultDeleteTimeout, "Timeout for destroying the cluster")
+	cmd.Flags().BoolVar(&keepVolumes, "keep-volumes", false, "Keep the volumes created for the cluster")
 
 	return cmd
 }
 
-func runE(ctx context.Context, logger *log.Logger, timeout time.Duration, clusterName string) error {
+func runE(ctx context.Context, logger *log.Logger, timeout time.Duration, clusterName, providerName string, keepVolumes bool) error {
 	// Check if this is the active cluster (before any changes)
 	activeCluster, err := cluster.GetActiveCluster()
 	if err != nil {
@@ -46,7 +51,7 @@ func runE(ctx context.Context, logger *log.Logger, timeout time.Duration, cluste
 	// If no cluster name provided, use active cluster or fall back to "default"
 	if clusterName == "" {
 		if activeCluster == "" {
-			clusterName = "default"
+			clusterName = cluster.DefaultClusterName
 		} else {
 			clusterName = activeCluster
 			logger.Debugf("Using active cluster: %s", clusterName)
@@ -58,12 +63,17 @@ func runE(ctx context.Context, logger *log.Logger, timeout time.Duration, cluste
 	defer cancel()
 
 	// Create cluster configuration
-	clusterMgr, err := cluster.New(logger, clusterName)
+	clusterMgr, err := cluster.New(logger, clusterName, cluster.WithProviderName(providerName))
 	if err != nil {
 		return fmt.Errorf("failed to create cluster manager: %w", err)
 	}
 
-	if err := clusterMgr.Delete(deleteCtx); err != nil {
+	// Delete the nodes the cluster was created with, not the defaults
+	if _, err := clusterMgr.Load(); err != nil {
+		return err
+	}
+
+	if err := clusterMgr.Delete(deleteCtx, cluster.DeleteOptions{KeepVolumes: keepVolumes}); err != nil {
 		return fmt.Errorf("failed to delete cluster: %w", err)
 	}
 
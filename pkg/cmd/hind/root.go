@@ -6,13 +6,26 @@ import (
 	"github.com/apex/log"
 	"github.com/spf13/cobra"
 
+	"github.com/stenh0use/hind/pkg/cluster"
+	"github.com/stenh0use/hind/pkg/cmd/hind/adopt"
 	"github.com/stenh0use/hind/pkg/cmd/hind/build"
+	"github.com/stenh0use/hind/pkg/cmd/hind/env"
+	"github.com/stenh0use/hind/pkg/cmd/hind/exec"
+	"github.com/stenh0use/hind/pkg/cmd/hind/federate"
+	"github.com/stenh0use/hind/pkg/cmd/hind/flags"
+	"github.com/stenh0use/hind/pkg/cmd/hind/format"
 	"github.com/stenh0use/hind/pkg/cmd/hind/get"
+	"github.com/stenh0use/hind/pkg/cmd/hind/keyring"
 	"github.com/stenh0use/hind/pkg/cmd/hind/list"
+	"github.com/stenh0use/hind/pkg/cmd/hind/logs"
+	"github.com/stenh0use/hind/pkg/cmd/hind/plan"
+	"github.com/stenh0use/hind/pkg/cmd/hind/prune"
 	"github.com/stenh0use/hind/pkg/cmd/hind/rm"
 	"github.com/stenh0use/hind/pkg/cmd/hind/set"
+	"github.com/stenh0use/hind/pkg/cmd/hind/shell"
 	"github.com/stenh0use/hind/pkg/cmd/hind/start"
 	"github.com/stenh0use/hind/pkg/cmd/hind/stop"
+	"github.com/stenh0use/hind/pkg/cmd/hind/vault"
 	"github.com/stenh0use/hind/pkg/cmd/hind/version"
 )
 
@@ -29,15 +42,34 @@ func NewCommand(logger *log.Logger) *cobra.Command {
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		Version:       version.DisplayVersion(),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := format.Validate(format.Output(cmd)); err != nil {
+				return err
+			}
+			return cluster.ValidateProvider(flags.Provider(cmd))
+		},
 	}
+	format.AddOutputFlag(cmd)
+	flags.AddProviderFlag(cmd)
+
 	// Add subcommands
+	cmd.AddCommand(adopt.NewCommand(logger))
 	cmd.AddCommand(build.NewCommand(logger))
+	cmd.AddCommand(env.NewCommand(logger))
+	cmd.AddCommand(exec.NewCommand(logger))
+	cmd.AddCommand(federate.NewCommand(logger))
 	cmd.AddCommand(get.NewCommand(logger))
+	cmd.AddCommand(keyring.NewCommand(logger))
 	cmd.AddCommand(list.NewCommand(logger))
+	cmd.AddCommand(logs.NewCommand(logger))
+	cmd.AddCommand(plan.NewCommand(logger))
+	cmd.AddCommand(prune.NewCommand(logger))
 	cmd.AddCommand(rm.NewCommand(logger))
 	cmd.AddCommand(set.NewCommand(logger))
+	cmd.AddCommand(shell.NewCommand(logger))
 	cmd.AddCommand(start.NewCommand(logger))
 	cmd.AddCommand(stop.NewCommand(logger))
+	cmd.AddCommand(vault.NewCommand(logger))
 	cmd.AddCommand(version.NewCommand(logger))
 	return cmd
 }
@@ -0,0 +1,40 @@
+// Package shell implements the `shell` command
+package shell
+
+import (
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+	"github.com/stenh0use/hind/pkg/cmd/hind/exec"
+)
+
+// DefaultShell is the shell started in the node
+const DefaultShell = "bash"
+
+// NewCommand creates the node shell command
+func NewCommand(logger *log.Logger) *cobra.Command {
+	var shell string
+
+	cmd := &cobra.Command{
+		Use:   "shell [cluster-name] <node>",
+		Short: "Open a shell in a hind cluster node",
+		Long: strings.Join([]string{
+			"Open an interactive shell in a node of a hind cluster, with the",
+			"environment of the agent CLIs set, see 'hind exec'. The node is a",
+			"name such as client.02, or a kind or role to use its first node.",
+		}, " "),
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var clusterName string
+			if len(args) > 1 {
+				clusterName = args[0]
+			}
+			return exec.Run(cmd.Context(), logger, clusterName, args[len(args)-1], []string{shell}, true)
+		},
+	}
+
+	cmd.Flags().StringVar(&shell, "shell", DefaultShell, "Shell to run in the node")
+
+	return cmd
+}
@@ -0,0 +1,25 @@
+package shell
+
+import (
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+)
+
+func TestNewCommand(t *testing.T) {
+	logger := &log.Logger{
+		Handler: discard.New(),
+		Level:   log.ErrorLevel,
+	}
+
+	cmd := NewCommand(logger)
+
+	if cmd.Use != "shell [cluster-name] <node>" {
+		t.Errorf("Expected Use to be 'shell [cluster-name] <node>', got '%s'", cmd.Use)
+	}
+
+	if f := cmd.Flags().Lookup("shell"); f == nil || f.DefValue != DefaultShell {
+		t.Errorf("Expected 'shell' flag to default to '%s'", DefaultShell)
+	}
+}
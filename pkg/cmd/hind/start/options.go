@@ -0,0 +1,96 @@
+package start
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/stenh0use/hind/pkg/build/release"
+	"github.com/stenh0use/hind/pkg/cluster"
+	"github.com/stenh0use/hind/pkg/cmd/hind/flags"
+)
+
+// CreateOptions are the start flags describing the cluster to create. The
+// version, server counts, ACLs, TLS and location only apply when a cluster
+// is created, the client count also scales existing clusters. The plan
+// command registers the same flags to preview what start would do.
+type CreateOptions struct {
+	Version    string
+	Topology   cluster.Topology
+	ACL        bool
+	TLS        bool
+	Datacenter string
+	Region     string
+}
+
+// topologyFlags are the flags that set the number of nodes in a cluster
+var topologyFlags = []string{"clients", "consul-servers", "nomad-servers", "vault-servers"}
+
+// locationFlags are the flags that set the datacenter and region of a cluster
+var locationFlags = []string{"datacenter", "region"}
+
+// AddCreateFlags registers the flags describing the cluster to create
+func AddCreateFlags(cmd *cobra.Command, o *CreateOptions) {
+	cmd.Flags().StringVar(&o.Version, "version", release.LatestAlias, "Hind release to create the cluster from")
+	cmd.Flags().IntVar(&o.Topology.NomadClients, "clients", cluster.DefaultNomadClients, "Number of client nodes to create")
+	cmd.Flags().IntVar(&o.Topology.ConsulServers, "consul-servers", cluster.DefaultConsulServers, "Number of consul servers to create")
+	cmd.Flags().IntVar(&o.Topology.NomadServers, "nomad-servers", cluster.DefaultNomadServers, "Number of nomad servers to create")
+	cmd.Flags().IntVar(&o.Topology.VaultServers, "vault-servers", cluster.DefaultVaultServers, "Number of vault servers to create")
+	cmd.Flags().BoolVar(&o.ACL, "acl", false, "Enable and bootstrap the Consul and Nomad ACLs")
+	cmd.Flags().BoolVar(&o.TLS, "tls", false, "Enable TLS with certificates from a CA generated for the cluster")
+	cmd.Flags().StringVar(&o.Datacenter, "datacenter", cluster.DefaultDatacenter, "Datacenter of the Consul and Nomad agents")
+	cmd.Flags().StringVar(&o.Region, "region", cluster.DefaultRegion, "Region of the Nomad agents")
+}
+
+// Validate checks the topology, and that none of the flags are combined
+// with a cluster definition file, which describes the cluster itself
+func (o *CreateOptions) Validate(cmd *cobra.Command, configFile string) error {
+	if err := o.Topology.Validate(); err != nil {
+		return err
+	}
+	if configFile == "" {
+		return nil
+	}
+
+	for _, flag := range topologyFlags {
+		if cmd.Flags().Changed(flag) {
+			return fmt.Errorf("--%s cannot be used with --config, set the nodes in the cluster definition", flag)
+		}
+	}
+	if cmd.Flags().Changed("version") {
+		return fmt.Errorf("--version cannot be used with --config, set the version in the cluster definition")
+	}
+	if cmd.Flags().Changed("acl") {
+		return fmt.Errorf("--acl cannot be used with --config, set acl in the cluster definition")
+	}
+	if cmd.Flags().Changed("tls") {
+		return fmt.Errorf("--tls cannot be used with --config, set tls in the cluster definition")
+	}
+	for _, flag := range locationFlags {
+		if cmd.Flags().Changed(flag) {
+			return fmt.Errorf("--%s cannot be used with --config, set %s in the cluster definition", flag, flag)
+		}
+	}
+	return nil
+}
+
+// ManagerOptions returns the cluster manager options creating the cluster
+// the flags describe
+func (o *CreateOptions) ManagerOptions(cmd *cobra.Command) []cluster.Option {
+	return []cluster.Option{
+		cluster.WithProviderName(flags.Provider(cmd)),
+		cluster.WithTopology(o.Topology),
+		cluster.WithVersion(o.Version),
+		cluster.WithACL(o.ACL),
+		cluster.WithTLS(o.TLS),
+		cluster.WithLocation(locationFlag(cmd, "datacenter", o.Datacenter), locationFlag(cmd, "region", o.Region)),
+	}
+}
+
+// locationFlag returns the value of a location flag, empty when it wasn't
+// set so the cluster config keeps the default implicit
+func locationFlag(cmd *cobra.Command, flag, value string) string {
+	if !cmd.Flags().Changed(flag) {
+		return ""
+	}
+	return value
+}
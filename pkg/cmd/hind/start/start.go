@@ -7,7 +7,10 @@ import (
 
 	"github.com/apex/log"
 	"github.com/spf13/cobra"
+	"github.com/stenh0use/hind/pkg/build/release"
 	"github.com/stenh0use/hind/pkg/cluster"
+	"github.com/stenh0use/hind/pkg/cmd/hind/flags"
+	"github.com/stenh0use/hind/pkg/config"
 )
 
 // DefaultStartTimeout is the default timeout for starting a cluster
@@ -16,10 +19,12 @@ const DefaultStartTimeout = 5 * time.Minute
 // NewCommand creates the cluster start command
 func NewCommand(logger *log.Logger) *cobra.Command {
 	var (
-		hindVersion string
+		configFile  string
 		timeout     time.Duration
-		clients     int
+		create      CreateOptions
 		verbose     bool
+		concurrency int
+		wait        bool
 	)
 
 	cmd := &cobra.Command{
@@ -34,17 +39,21 @@ func NewCommand(logger *log.Logger) *cobra.Command {
 			}
 			return runE(cmd, cmd.Context(), logger, startConfig{
 				clusterName: clusterName,
-				hindVersion: hindVersion,
+				configFile:  configFile,
 				timeout:     timeout,
-				clients:     clients,
+				create:      create,
 				verbose:     verbose,
+				concurrency: concurrency,
+				wait:        wait,
 			})
 		},
 	}
 
-	cmd.Flags().StringVar(&hindVersion, "version", "latest", "Hind image version to use")
+	cmd.Flags().StringVar(&configFile, "config", "", "Path to a cluster definition file (YAML, JSON or HCL)")
 	cmd.Flags().DurationVar(&timeout, "timeout", DefaultStartTimeout, "Timeout for starting the cluster")
-	cmd.Flags().IntVar(&clients, "clients", 1, "Number of client nodes to create")
+	AddCreateFlags(cmd, &create)
+	cmd.Flags().IntVar(&concurrency, "concurrency", cluster.DefaultConcurrency, "Number of nodes to create or start in parallel")
+	cmd.Flags().BoolVar(&wait, "wait", true, "Wait for Consul, Nomad and Vault to be ready")
 	cmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose output")
 
 	return cmd
@@ -52,27 +61,36 @@ func NewCommand(logger *log.Logger) *cobra.Command {
 
 type startConfig struct {
 	clusterName string
-	hindVersion string
+	configFile  string
 	timeout     time.Duration
-	clients     int
+	create      CreateOptions
 	verbose     bool
+	concurrency int
+	wait        bool
 }
 
 func runE(cmd *cobra.Command, ctx context.Context, logger *log.Logger, cfg startConfig) error {
 	clusterName := cfg.clusterName
 
-	// If no cluster name provided, try to get active cluster, fall back to "default"
-	if clusterName == "" {
-		activeCluster, err := cluster.GetActiveCluster()
-		if err != nil || activeCluster == "" {
-			logger.Debugf("Failed to get active cluster")
-			clusterName = "default"
-		} else {
-			clusterName = activeCluster
-			logger.Debugf("Using active cluster: %s", clusterName)
+	if err := cfg.create.Validate(cmd, cfg.configFile); err != nil {
+		return err
+	}
+
+	// A cluster definition file names the cluster unless a name is given
+	var definition *config.Cluster
+	if cfg.configFile != "" {
+		def, err := cluster.LoadDefinition(cfg.configFile, clusterName, flags.Provider(cmd))
+		if err != nil {
+			return err
 		}
+		definition = def
+		clusterName = def.Name
+		logger.Debugf("Loaded cluster definition '%s' from %s", clusterName, cfg.configFile)
 	}
 
+	// If no cluster name provided, use the active cluster or fall back to "default"
+	clusterName = cluster.ResolveName(clusterName)
+
 	// Set log level based on verbose flag
 	if cfg.verbose {
 		logger.Level = log.DebugLevel
@@ -84,43 +102,67 @@ func runE(cmd *cobra.Command, ctx context.Context, logger *log.Logger, cfg start
 	startCtx, cancel := context.WithTimeout(ctx, cfg.timeout)
 	defer cancel()
 
-	// Check if Docker daemon is accessible first
-	logger.Debug("Checking Docker daemon accessibility")
-	if err := checkDockerDaemon(startCtx, logger); err != nil {
-		return fmt.Errorf("Docker daemon is not accessible: %w", err)
-	}
-
-	// Create cluster manager
-	mgr, err := cluster.New(logger, clusterName)
+	// Create cluster manager, the topology, version, location, ACLs and TLS
+	// only apply to new clusters
+	mgr, err := cluster.New(logger, clusterName, append(cfg.create.ManagerOptions(cmd),
+		cluster.WithConcurrency(cfg.concurrency),
+		cluster.WithReadinessChecks(cfg.wait))...)
 	if err != nil {
 		return fmt.Errorf("failed to create cluster manager: %w", err)
 	}
 
-	// Start the cluster first (this loads config if exists, or uses defaults for new clusters)
-	// For new clusters, we need to set client count before starting
-	if !mgr.ConfigFileExists() && cfg.clients != 1 {
-		if err := mgr.SetClientCount(startCtx, cfg.clients); err != nil {
-			return fmt.Errorf("failed to set client count: %w", err)
-		}
+	// The definition file is the desired state, whether or not the cluster
+	// exists, otherwise an existing cluster keeps the provider it was
+	// created with
+	if definition != nil {
+		err = mgr.SetConfig(definition)
+	} else {
+		_, err = mgr.Load()
 	}
-
-	// Start the cluster (handles create, resume, and idempotent cases)
-	result, err := mgr.Start(startCtx)
 	if err != nil {
 		return err
 	}
 
+	// Check the provider of the cluster is accessible first
+	logger.Debugf("Checking provider '%s' accessibility", mgr.Config().Provider)
+	if err := checkProvider(startCtx, mgr); err != nil {
+		return fmt.Errorf("provider '%s' is not accessible: %w", mgr.Config().Provider, err)
+	}
+
+	var result cluster.StartResult
+	if definition != nil {
+		result, err = mgr.Apply(startCtx)
+		if err != nil {
+			return err
+		}
+	} else {
+		// Start the cluster (handles create, resume, and idempotent cases)
+		result, err = mgr.Start(startCtx)
+		if err != nil {
+			return err
+		}
+	}
+
 	// If --clients flag was explicitly set for existing cluster, scale it
 	if result == cluster.StartResultResumed && cmd.Flags().Changed("clients") {
 		currentClientCount := mgr.CountClientNodes()
-		if cfg.clients != currentClientCount {
-			logger.Debugf("Client count change requested: %d -> %d", currentClientCount, cfg.clients)
-			if err := mgr.Scale(startCtx, cfg.clients); err != nil {
+		if cfg.create.Topology.NomadClients != currentClientCount {
+			logger.Debugf("Client count change requested: %d -> %d", currentClientCount, cfg.create.Topology.NomadClients)
+			if err := mgr.Scale(startCtx, cfg.create.Topology.NomadClients); err != nil {
 				return fmt.Errorf("failed to scale cluster: %w", err)
 			}
 		}
 	}
 
+	// Server counts are fixed when the cluster is created
+	if result == cluster.StartResultResumed {
+		warnServerCountChange(cmd, logger, mgr, cfg.create.Topology)
+		warnVersionChange(cmd, logger, mgr, cfg.create.Version)
+		warnACLChange(cmd, logger, mgr, cfg.create.ACL)
+		warnTLSChange(cmd, logger, mgr, cfg.create.TLS)
+		warnLocationChange(cmd, logger, mgr, cfg.create.Datacenter, cfg.create.Region)
+	}
+
 	// Set this cluster as the active cluster
 	if err := cluster.SetActiveCluster(clusterName); err != nil {
 		logger.Warnf("Failed to set active cluster: %v", err)
@@ -129,29 +171,116 @@ func runE(cmd *cobra.Command, ctx context.Context, logger *log.Logger, cfg start
 
 	// Display connection information only for newly created or resumed clusters
 	if result != cluster.StartResultAlreadyRunning {
-		displayConnectionInfo(logger, clusterName)
+		endpoints, err := mgr.Endpoints(startCtx)
+		if err != nil {
+			logger.Warnf("Failed to resolve the cluster endpoints: %v", err)
+		}
+		displayConnectionInfo(logger, clusterName, endpoints)
 	}
 	return nil
 }
 
-// checkDockerDaemon verifies the Docker daemon is accessible
-func checkDockerDaemon(ctx context.Context, logger *log.Logger) error {
-	// Create a temporary manager to test Docker connectivity
-	// This is a lightweight check before we do any real work
-	tempMgr, err := cluster.New(logger, "temp-check")
+// warnServerCountChange warns when server counts were requested for an
+// existing cluster that differ from the servers it was created with.
+func warnServerCountChange(cmd *cobra.Command, logger *log.Logger, mgr *cluster.Manager, topology cluster.Topology) {
+	requested := []struct {
+		flag  string
+		kind  config.Kind
+		count int
+	}{
+		{"consul-servers", config.ConsulNode, topology.ConsulServers},
+		{"nomad-servers", config.NomadNode, topology.NomadServers},
+		{"vault-servers", config.VaultNode, topology.VaultServers},
+	}
+
+	for _, r := range requested {
+		if !cmd.Flags().Changed(r.flag) {
+			continue
+		}
+		if current := mgr.CountServerNodes(r.kind); current != r.count {
+			logger.Warnf("Cluster has %d %s server(s), --%s only applies when a cluster is created", current, r.kind, r.flag)
+		}
+	}
+}
+
+// warnVersionChange warns when a release was requested for an existing
+// cluster that differs from the release it is pinned to.
+func warnVersionChange(cmd *cobra.Command, logger *log.Logger, mgr *cluster.Manager, version string) {
+	if !cmd.Flags().Changed("version") {
+		return
+	}
+	rel, err := release.Resolve(version)
 	if err != nil {
-		return err
+		return
+	}
+	if current := mgr.Config().Version; current != rel.Hind {
+		logger.Warnf("Cluster is pinned to hind release %s, --version only applies when a cluster is created", current)
+	}
+}
+
+// warnACLChange warns when ACLs were requested for an existing cluster
+// that was created with a different ACL setting.
+func warnACLChange(cmd *cobra.Command, logger *log.Logger, mgr *cluster.Manager, acl bool) {
+	if !cmd.Flags().Changed("acl") {
+		return
+	}
+	if mgr.Config().ACL == acl {
+		return
+	}
+	state := "disabled"
+	if mgr.Config().ACL {
+		state = "enabled"
 	}
+	logger.Warnf("Cluster was created with ACLs %s, --acl only applies when a cluster is created", state)
+}
 
-	// Try to list containers to verify Docker daemon is accessible
-	_, err = tempMgr.Provider().ListContainers(ctx, []string{})
+// warnTLSChange warns when TLS was requested for an existing cluster that
+// was created with a different TLS setting.
+func warnTLSChange(cmd *cobra.Command, logger *log.Logger, mgr *cluster.Manager, tls bool) {
+	if !cmd.Flags().Changed("tls") {
+		return
+	}
+	if mgr.Config().TLS == tls {
+		return
+	}
+	state := "disabled"
+	if mgr.Config().TLS {
+		state = "enabled"
+	}
+	logger.Warnf("Cluster was created with TLS %s, --tls only applies when a cluster is created", state)
+}
+
+// warnLocationChange warns when a datacenter or region was requested for an
+// existing cluster that differs from the one it was created with.
+func warnLocationChange(cmd *cobra.Command, logger *log.Logger, mgr *cluster.Manager, datacenter, region string) {
+	if cmd.Flags().Changed("datacenter") {
+		if current := cluster.Datacenter(mgr.Config()); current != datacenter {
+			logger.Warnf("Cluster is in datacenter %s, --datacenter only applies when a cluster is created", current)
+		}
+	}
+	if cmd.Flags().Changed("region") {
+		if current := cluster.Region(mgr.Config()); current != region {
+			logger.Warnf("Cluster is in region %s, --region only applies when a cluster is created", current)
+		}
+	}
+}
+
+// checkProvider verifies the provider of the cluster, eg. the Docker
+// daemon, is accessible. This is a lightweight check before we do any real
+// work.
+func checkProvider(ctx context.Context, mgr *cluster.Manager) error {
+	_, err := mgr.Provider().ListContainers(ctx, []string{})
 	return err
 }
 
 // displayConnectionInfo shows the user how to connect to the cluster services
-func displayConnectionInfo(logger *log.Logger, clusterName string) {
+func displayConnectionInfo(logger *log.Logger, clusterName string, endpoints []cluster.Endpoint) {
+	if len(endpoints) == 0 {
+		return
+	}
 	logger.Info("Connection information:")
-	logger.Info("  Nomad:  http://localhost:4646")
-	logger.Info("  Consul: http://localhost:8500")
-	logger.Info("  Vault:  http://localhost:8200")
+	for _, e := range endpoints {
+		logger.Infof("  %-7s %s", e.Name+":", e.URL)
+	}
+	logger.Infof("Configure the CLIs with: eval $(hind env %s)", clusterName)
 }
@@ -47,7 +47,7 @@ func runE(ctx context.Context, logger *log.Logger, timeout time.Duration, cluste
 	// If no cluster name provided, use active cluster or fall back to "default"
 	if clusterName == "" {
 		if activeCluster == "" {
-			clusterName = "default"
+			clusterName = cluster.DefaultClusterName
 		} else {
 			clusterName = activeCluster
 			logger.Debugf("Using active cluster: %s", clusterName)
@@ -0,0 +1,88 @@
+// Package vault implements the `vault` command
+package vault
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+	"github.com/stenh0use/hind/pkg/cluster"
+	"github.com/stenh0use/hind/pkg/cmd/hind/format"
+)
+
+// NewCommand creates the vault command with subcommands
+func NewCommand(logger *log.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vault",
+		Short: "Manage the Vault of a hind cluster",
+		Long:  "Manage the Vault servers of a hind cluster and their credentials",
+	}
+
+	cmd.AddCommand(newCredsCommand(logger))
+
+	return cmd
+}
+
+// newCredsCommand creates the 'vault creds' subcommand
+func newCredsCommand(logger *log.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "creds [cluster-name]",
+		Short: "Print the Vault root token and unseal key",
+		Long: "Print the Vault root token and unseal key of a hind cluster. They are " +
+			"collected from Vault once it is initialised, when 'hind start' waits for " +
+			"the cluster to be ready, and stored in the cluster's config directory.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var clusterName string
+			if len(args) > 0 {
+				clusterName = args[0]
+			}
+			return runCreds(cmd.OutOrStdout(), logger, clusterName, format.Output(cmd))
+		},
+	}
+
+	return cmd
+}
+
+func runCreds(w io.Writer, logger *log.Logger, clusterName, output string) error {
+	// If no cluster name provided, use the active cluster or fall back to "default"
+	clusterName = cluster.ResolveName(clusterName)
+
+	clusterMgr, err := cluster.New(logger, clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to create cluster manager: %w", err)
+	}
+
+	existed, err := clusterMgr.Load()
+	if err != nil {
+		return err
+	}
+	if !existed {
+		return fmt.Errorf("cluster '%s' not found", clusterName)
+	}
+
+	creds, err := clusterMgr.VaultCredentials()
+	if errors.Is(err, cluster.ErrNoVaultCredentials) {
+		return fmt.Errorf("no vault credentials for cluster '%s' yet, run 'hind start %s' to wait for vault", clusterName, clusterName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read vault credentials: %w", err)
+	}
+
+	if format.Structured(output) {
+		return format.Write(w, output, creds)
+	}
+	writeCreds(w, creds)
+	return nil
+}
+
+// writeCreds writes the credentials as aligned key value pairs
+func writeCreds(w io.Writer, creds *cluster.VaultCredentials) {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(tw, "Root Token\t%s\n", creds.RootToken)
+	fmt.Fprintf(tw, "Unseal Key\t%s\n", creds.UnsealKey)
+	tw.Flush()
+}
@@ -0,0 +1,38 @@
+package vault
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+	"github.com/stenh0use/hind/pkg/cluster"
+)
+
+func TestNewCommand(t *testing.T) {
+	logger := &log.Logger{
+		Handler: discard.New(),
+		Level:   log.ErrorLevel,
+	}
+
+	cmd := NewCommand(logger)
+
+	if cmd.Use != "vault" {
+		t.Errorf("Expected Use to be 'vault', got '%s'", cmd.Use)
+	}
+
+	creds, _, err := cmd.Find([]string{"creds"})
+	if err != nil || creds.Name() != "creds" {
+		t.Errorf("Expected 'creds' subcommand to exist, got %v", err)
+	}
+}
+
+func TestWriteCreds(t *testing.T) {
+	var buf bytes.Buffer
+	writeCreds(&buf, &cluster.VaultCredentials{RootToken: "hvs.root", UnsealKey: "key"})
+
+	want := "Root Token   hvs.root\nUnseal Key   key\n"
+	if got := buf.String(); got != want {
+		t.Errorf("writeCreds() = %q, want %q", got, want)
+	}
+}
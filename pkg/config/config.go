@@ -4,26 +4,41 @@ type Labels map[string]string
 
 type Cluster struct {
 	// Name of the hind cluster
-	Name string
+	Name string `json:"name,omitempty" yaml:"name,omitempty" hcl:"name,optional"`
 	// List of Nodes in the cluster
-	Nodes []Node
+	Nodes []Node `json:"nodes,omitempty" yaml:"nodes,omitempty"`
 	// Network configuration for the cluster
-	Network Network
+	Network Network `json:"network,omitempty" yaml:"network,omitempty"`
 	// Hind version
-	Version string
+	Version string `json:"version,omitempty" yaml:"version,omitempty" hcl:"version,optional"`
+	// Container provider running the cluster eg. dockercli, podman
+	Provider string `json:"provider,omitempty" yaml:"provider,omitempty" hcl:"provider,optional"`
+	// ACL enables the ACL systems of Consul and Nomad, with a deny by
+	// default policy
+	ACL bool `json:"acl,omitempty" yaml:"acl,omitempty" hcl:"acl,optional"`
+	// TLS enables TLS with certificates from a CA generated for the cluster
+	// on the HTTP APIs and RPC of Consul, Nomad and Vault
+	TLS bool `json:"tls,omitempty" yaml:"tls,omitempty" hcl:"tls,optional"`
+	// Datacenter of the Consul and Nomad agents, local when empty
+	Datacenter string `json:"datacenter,omitempty" yaml:"datacenter,omitempty" hcl:"datacenter,optional"`
+	// Region of the Nomad agents, global when empty
+	Region string `json:"region,omitempty" yaml:"region,omitempty" hcl:"region,optional"`
+	// Federation lists the clusters whose Consul servers are joined with the
+	// ones of this cluster over the WAN
+	Federation []string `json:"federation,omitempty" yaml:"federation,omitempty" hcl:"federation,optional"`
 }
 
 type Network struct {
 	// Name of the network
-	Name string
+	Name string `json:"name,omitempty" yaml:"name,omitempty" hcl:"name,optional"`
 	// Network driver eg. 'bridge'
-	Driver string
+	Driver string `json:"driver,omitempty" yaml:"driver,omitempty" hcl:"driver,optional"`
 	// Subnet in CIDR format that represents a network segment
-	Subnet string
+	Subnet string `json:"subnet,omitempty" yaml:"subnet,omitempty" hcl:"subnet,optional"`
 	// IPv4 or IPv6 Gateway for the subnet
-	Gateway string
+	Gateway string `json:"gateway,omitempty" yaml:"gateway,omitempty" hcl:"gateway,optional"`
 	// Labels map of key/value labels to apply
-	Labels Labels
+	Labels Labels `json:"labels,omitempty" yaml:"labels,omitempty" hcl:"labels,optional"`
 }
 
 // Type of Node
@@ -52,54 +67,92 @@ func (r Role) String() string {
 
 type Node struct {
 	// Name given to the node
-	Name string
+	Name string `json:"name,omitempty" yaml:"name,omitempty" hcl:"name,optional"`
 	// Kind of Node, eg, consul, nomad, vault
-	Kind Kind
+	Kind Kind `json:"kind,omitempty" yaml:"kind,omitempty" hcl:"kind,optional"`
 	// Role the node functions as eg. server or client
-	Role Role
+	Role Role `json:"role,omitempty" yaml:"role,omitempty" hcl:"role,optional"`
 	// Image associated with the node
-	Image Image
+	Image Image `json:"image,omitempty" yaml:"image,omitempty"`
 	// Network name to attach the node to
-	Network string
+	Network string `json:"network,omitempty" yaml:"network,omitempty" hcl:"network,optional"`
 	// Environment variables to pass to the node
-	Environment map[string]string
+	Environment map[string]string `json:"env,omitempty" yaml:"env,omitempty" hcl:"env,optional"`
 	// List of ports to publish
-	Ports []PortMapping
+	Ports []PortMapping `json:"ports,omitempty" yaml:"ports,omitempty" hcl:"port,block"`
 	// List of volumes to attach to the container
-	Volumes []Volume
+	Volumes []Volume `json:"volumes,omitempty" yaml:"volumes,omitempty" hcl:"volume,block"`
 	// List of devices to expose to the container
-	Devices []string
+	Devices []string `json:"devices,omitempty" yaml:"devices,omitempty" hcl:"devices,optional"`
 	// Labels map of key/value labels to apply
-	Labels Labels
+	Labels Labels `json:"labels,omitempty" yaml:"labels,omitempty" hcl:"labels,optional"`
 }
 
 type Image struct {
 	// OCI Image repository eg. docker.io/stenh0use/hind.consul
-	Name string
+	Name string `json:"name,omitempty" yaml:"name,omitempty" hcl:"name,optional"`
 	// Image tag eg. 0.3.0
-	Tag string
+	Tag string `json:"tag,omitempty" yaml:"tag,omitempty" hcl:"tag,optional"`
 	// Sha256 digest of the container image
-	Digest string
+	Digest string `json:"digest,omitempty" yaml:"digest,omitempty" hcl:"digest,optional"`
+}
+
+// Ref returns the image reference to run, preferring the digest over the tag
+//
+// eg. docker.io/stenh0use/hind.consul:0.4.0
+func (i Image) Ref() string {
+	switch {
+	case i.Digest != "":
+		return i.Name + "@" + i.Digest
+	case i.Tag != "":
+		return i.Name + ":" + i.Tag
+	default:
+		return i.Name
+	}
 }
 
 type PortMapping struct {
 	// Address to listen to on the host machine
-	ListenAddress string
+	ListenAddress string `json:"listenAddress,omitempty" yaml:"listenAddress,omitempty" hcl:"listenAddress,optional"`
 	// Port to map to on the host machine
-	HostPort int32
+	HostPort int32 `json:"hostPort,omitempty" yaml:"hostPort,omitempty" hcl:"hostPort,optional"`
 	// Port to map to inside the container
-	ContainerPort int32
+	ContainerPort int32 `json:"containerPort,omitempty" yaml:"containerPort,omitempty" hcl:"containerPort,optional"`
 	// L4 Protocol TCP/UDP/SCTP
-	Protocol string
+	Protocol string `json:"protocol,omitempty" yaml:"protocol,omitempty" hcl:"protocol,optional"`
 }
 
+// Types of Volume mounts
+const (
+	VolumeMount = "volume"
+	BindMount   = "bind"
+	TmpfsMount  = "tmpfs"
+)
+
 type Volume struct {
+	// Type of mount eg. volume, bind or tmpfs
+	Type string `json:"type,omitempty" yaml:"type,omitempty" hcl:"type,optional"`
 	// Name of the docker volume
-	Name string
+	Name string `json:"name,omitempty" yaml:"name,omitempty" hcl:"name,optional"`
 	// Destination path to mount to in the container
-	Destination string
+	Destination string `json:"destination,omitempty" yaml:"destination,omitempty" hcl:"destination,optional"`
 	// Source of the volume, eg path on host or volume identifier
-	Source string
+	Source string `json:"source,omitempty" yaml:"source,omitempty" hcl:"source,optional"`
+	// Mount the volume read only
+	ReadOnly bool `json:"readOnly,omitempty" yaml:"readOnly,omitempty" hcl:"readOnly,optional"`
 	// Labels map of key/value labels to apply
-	Labels Labels
+	Labels Labels `json:"labels,omitempty" yaml:"labels,omitempty" hcl:"labels,optional"`
+}
+
+// MountType returns the type of mount, defaulting to a bind mount when a
+// source is set and a volume otherwise
+func (v Volume) MountType() string {
+	switch {
+	case v.Type != "":
+		return v.Type
+	case v.Source != "":
+		return BindMount
+	default:
+		return VolumeMount
+	}
 }
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"gopkg.in/yaml.v3"
+)
+
+// ReadFile reads a cluster definition from a YAML, JSON or HCL file.
+// The format is selected by the file extension (.yaml, .yml, .json or
+// .hcl). All formats use the same keys, in HCL nodes, ports and volumes
+// are repeated node, port and volume blocks.
+func ReadFile(path string) (*Cluster, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster file %s: %w", path, err)
+	}
+
+	cfg, err := Parse(data, filepath.Ext(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cluster file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse decodes a cluster definition in the format given by ext.
+// Unknown fields are rejected so typos surface as errors rather than
+// silently producing a different cluster.
+func Parse(data []byte, ext string) (*Cluster, error) {
+	var cfg Cluster
+
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&cfg); err != nil {
+			return nil, err
+		}
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&cfg); err != nil {
+			return nil, err
+		}
+	case ".hcl":
+		if err := decodeHCL(data, &cfg); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported cluster file format '%s', expected .yaml, .yml, .json or .hcl", ext)
+	}
+
+	return &cfg, nil
+}
+
+// hclCluster decodes the blocks of an HCL definition that are single
+// structs in Cluster and Node, the remaining arguments decode onto the
+// config types directly.
+type hclCluster struct {
+	Network *Network  `hcl:"network,block"`
+	Nodes   []hclNode `hcl:"node,block"`
+	Cluster Cluster   `hcl:",remain"`
+}
+
+type hclNode struct {
+	Image *Image `hcl:"image,block"`
+	Node  Node   `hcl:",remain"`
+}
+
+func decodeHCL(data []byte, cfg *Cluster) error {
+	file, diags := hclsyntax.ParseConfig(data, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return hclError(diags)
+	}
+
+	var def hclCluster
+	if diags := gohcl.DecodeBody(file.Body, nil, &def); diags.HasErrors() {
+		return hclError(diags)
+	}
+
+	*cfg = def.Cluster
+	if def.Network != nil {
+		cfg.Network = *def.Network
+	}
+	for _, n := range def.Nodes {
+		node := n.Node
+		if n.Image != nil {
+			node.Image = *n.Image
+		}
+		cfg.Nodes = append(cfg.Nodes, node)
+	}
+	return nil
+}
+
+// hclError returns the first error of diags with the line it is on, the
+// caller names the file like it does for YAML and JSON errors.
+func hclError(diags hcl.Diagnostics) error {
+	for _, d := range diags {
+		if d.Severity != hcl.DiagError {
+			continue
+		}
+		if d.Subject != nil {
+			return fmt.Errorf("line %d: %s; %s", d.Subject.Start.Line, d.Summary, d.Detail)
+		}
+		return fmt.Errorf("%s; %s", d.Summary, d.Detail)
+	}
+	return diags
+}
+
+// UnmarshalSaved decodes a cluster config saved by hind. Configs saved
+// before the JSON keys matched the YAML keys stored the node environment
+// under Environment rather than env, it is still read from there.
+func UnmarshalSaved(data []byte, cfg *Cluster) error {
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return err
+	}
+
+	var legacy struct {
+		Nodes []struct {
+			Environment map[string]string `json:"Environment"`
+		} `json:"nodes"`
+	}
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+	for i, n := range legacy.Nodes {
+		if i < len(cfg.Nodes) && cfg.Nodes[i].Environment == nil {
+			cfg.Nodes[i].Environment = n.Environment
+		}
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParse_YAML(t *testing.T) {
+	data := []byte(`
+name: dev
+version: 0.4.0
+network:
+  name: hind.dev
+  subnet: 172.30.0.0/16
+nodes:
+  - kind: consul
+    role: server
+    ports:
+      - hostPort: 8500
+        containerPort: 8500
+  - kind: nomad
+    role: client
+    env:
+      NOMAD_LOG_LEVEL: debug
+    volumes:
+      - source: /tmp/data
+        destination: /data
+`)
+
+	cfg, err := Parse(data, ".yaml")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if cfg.Name != "dev" {
+		t.Errorf("Name = %q, want %q", cfg.Name, "dev")
+	}
+	if cfg.Network.Subnet != "172.30.0.0/16" {
+		t.Errorf("Network.Subnet = %q, want %q", cfg.Network.Subnet, "172.30.0.0/16")
+	}
+	if len(cfg.Nodes) != 2 {
+		t.Fatalf("len(Nodes) = %d, want 2", len(cfg.Nodes))
+	}
+	if cfg.Nodes[0].Kind != ConsulNode || cfg.Nodes[0].Role != Server {
+		t.Errorf("Nodes[0] = %s/%s, want consul/server", cfg.Nodes[0].Kind, cfg.Nodes[0].Role)
+	}
+	if cfg.Nodes[0].Ports[0].HostPort != 8500 {
+		t.Errorf("Nodes[0].Ports[0].HostPort = %d, want 8500", cfg.Nodes[0].Ports[0].HostPort)
+	}
+	if cfg.Nodes[1].Environment["NOMAD_LOG_LEVEL"] != "debug" {
+		t.Errorf("Nodes[1].Environment = %v, want NOMAD_LOG_LEVEL=debug", cfg.Nodes[1].Environment)
+	}
+	if cfg.Nodes[1].Volumes[0].Destination != "/data" {
+		t.Errorf("Nodes[1].Volumes[0].Destination = %q, want %q", cfg.Nodes[1].Volumes[0].Destination, "/data")
+	}
+}
+
+func TestParse_JSON(t *testing.T) {
+	data := []byte(`{"name": "dev", "nodes": [{"name": "hind.dev.consul.01", "kind": "consul", "role": "server", "env": {"CONSUL_LOG_LEVEL": "debug"}}]}`)
+
+	cfg, err := Parse(data, ".json")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if cfg.Name != "dev" || len(cfg.Nodes) != 1 {
+		t.Fatalf("Parse() = %+v, want cluster 'dev' with 1 node", cfg)
+	}
+	if cfg.Nodes[0].Environment["CONSUL_LOG_LEVEL"] != "debug" {
+		t.Errorf("Nodes[0].Environment = %v, want CONSUL_LOG_LEVEL=debug", cfg.Nodes[0].Environment)
+	}
+}
+
+func TestParse_HCL(t *testing.T) {
+	data := []byte(`
+name    = "dev"
+version = "0.4.0"
+acl     = true
+
+network {
+  name   = "hind.dev"
+  subnet = "172.30.0.0/16"
+}
+
+node {
+  kind = "consul"
+  role = "server"
+  image {
+    name = "docker.io/stenh0use/hind.consul"
+    tag  = "0.4.0"
+  }
+  port {
+    hostPort      = 8500
+    containerPort = 8500
+  }
+}
+
+node {
+  kind = "nomad"
+  role = "client"
+  env = {
+    NOMAD_LOG_LEVEL = "debug"
+  }
+  volume {
+    source      = "/tmp/data"
+    destination = "/data"
+  }
+}
+`)
+
+	cfg, err := Parse(data, ".hcl")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if cfg.Name != "dev" || !cfg.ACL {
+		t.Errorf("Parse() = %+v, want cluster 'dev' with ACL", cfg)
+	}
+	if cfg.Network.Subnet != "172.30.0.0/16" {
+		t.Errorf("Network.Subnet = %q, want %q", cfg.Network.Subnet, "172.30.0.0/16")
+	}
+	if len(cfg.Nodes) != 2 {
+		t.Fatalf("len(Nodes) = %d, want 2", len(cfg.Nodes))
+	}
+	if cfg.Nodes[0].Kind != ConsulNode || cfg.Nodes[0].Role != Server {
+		t.Errorf("Nodes[0] = %s/%s, want consul/server", cfg.Nodes[0].Kind, cfg.Nodes[0].Role)
+	}
+	if cfg.Nodes[0].Image.Ref() != "docker.io/stenh0use/hind.consul:0.4.0" {
+		t.Errorf("Nodes[0].Image = %q, want %q", cfg.Nodes[0].Image.Ref(), "docker.io/stenh0use/hind.consul:0.4.0")
+	}
+	if cfg.Nodes[0].Ports[0].HostPort != 8500 {
+		t.Errorf("Nodes[0].Ports[0].HostPort = %d, want 8500", cfg.Nodes[0].Ports[0].HostPort)
+	}
+	if cfg.Nodes[1].Environment["NOMAD_LOG_LEVEL"] != "debug" {
+		t.Errorf("Nodes[1].Environment = %v, want NOMAD_LOG_LEVEL=debug", cfg.Nodes[1].Environment)
+	}
+	if cfg.Nodes[1].Volumes[0].Destination != "/data" {
+		t.Errorf("Nodes[1].Volumes[0].Destination = %q, want %q", cfg.Nodes[1].Volumes[0].Destination, "/data")
+	}
+}
+
+func TestParse_SameKeys(t *testing.T) {
+	// A definition converted from YAML to JSON or HCL parses to the same
+	// cluster
+	yamlData := []byte(`
+name: dev
+nodes:
+  - name: hind.dev.nomad.01
+    env:
+      A: "1"
+    ports:
+      - hostPort: 4646
+        containerPort: 4646
+    volumes:
+      - source: /tmp/data
+        destination: /data
+        readOnly: true
+`)
+	jsonData := []byte(`{"name": "dev", "nodes": [{"name": "hind.dev.nomad.01", "env": {"A": "1"},
+		"ports": [{"hostPort": 4646, "containerPort": 4646}],
+		"volumes": [{"source": "/tmp/data", "destination": "/data", "readOnly": true}]}]}`)
+	hclData := []byte(`
+name = "dev"
+node {
+  name = "hind.dev.nomad.01"
+  env  = { A = "1" }
+  port {
+    hostPort      = 4646
+    containerPort = 4646
+  }
+  volume {
+    source      = "/tmp/data"
+    destination = "/data"
+    readOnly    = true
+  }
+}
+`)
+
+	fromYAML, err := Parse(yamlData, ".yaml")
+	if err != nil {
+		t.Fatalf("Parse(yaml) error = %v", err)
+	}
+	fromJSON, err := Parse(jsonData, ".json")
+	if err != nil {
+		t.Fatalf("Parse(json) error = %v", err)
+	}
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Errorf("Parse(json) = %+v, want %+v", fromJSON, fromYAML)
+	}
+	fromHCL, err := Parse(hclData, ".hcl")
+	if err != nil {
+		t.Fatalf("Parse(hcl) error = %v", err)
+	}
+	if !reflect.DeepEqual(fromYAML, fromHCL) {
+		t.Errorf("Parse(hcl) = %+v, want %+v", fromHCL, fromYAML)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		ext  string
+	}{
+		{
+			name: "unknown yaml field",
+			data: "name: dev\nclients: 3\n",
+			ext:  ".yaml",
+		},
+		{
+			name: "unknown json field",
+			data: `{"Name": "dev", "Clients": 3}`,
+			ext:  ".json",
+		},
+		{
+			name: "json field name",
+			data: `{"name": "dev", "nodes": [{"Environment": {"A": "1"}}]}`,
+			ext:  ".json",
+		},
+		{
+			name: "unknown hcl field",
+			data: "name = \"dev\"\nclients = 3\n",
+			ext:  ".hcl",
+		},
+		{
+			name: "hcl nodes attribute",
+			data: "name = \"dev\"\nnodes = [{ kind = \"consul\" }]\n",
+			ext:  ".hcl",
+		},
+		{
+			name: "invalid hcl",
+			data: "name = \"dev",
+			ext:  ".hcl",
+		},
+		{
+			name: "unsupported extension",
+			data: "name = \"dev\"",
+			ext:  ".toml",
+		},
+		{
+			name: "invalid yaml",
+			data: "name: [dev",
+			ext:  ".yml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.data), tt.ext); err == nil {
+				t.Error("Parse() want error, got nil")
+			}
+		})
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hind.yaml")
+	if err := os.WriteFile(path, []byte("name: dev\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	cfg, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if cfg.Name != "dev" {
+		t.Errorf("Name = %q, want %q", cfg.Name, "dev")
+	}
+
+	if _, err := ReadFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("ReadFile() of missing file want error, got nil")
+	}
+}
+
+func TestUnmarshalSaved(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"current", `{"name": "dev", "nodes": [{"name": "n", "env": {"A": "1"}}]}`},
+		{"field names", `{"Name": "dev", "Nodes": [{"Name": "n", "Environment": {"A": "1"}}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Cluster
+			if err := UnmarshalSaved([]byte(tt.data), &cfg); err != nil {
+				t.Fatalf("UnmarshalSaved() error = %v", err)
+			}
+			if cfg.Name != "dev" || len(cfg.Nodes) != 1 || cfg.Nodes[0].Environment["A"] != "1" {
+				t.Errorf("UnmarshalSaved() = %+v, want cluster dev with env A=1", cfg)
+			}
+		})
+	}
+}
@@ -112,6 +112,13 @@ func (f *Manager) ListDir(path string) ([]os.DirEntry, error) {
 
 // WriteFile writes data to a file, creating parent directories if necessary
 func (f *Manager) WriteFile(path string, data []byte) error {
+	return f.WriteFileMode(path, data, filePermissions)
+}
+
+// WriteFileMode writes data to a file with the given permissions, creating
+// parent directories if necessary. The permissions of an existing file are
+// replaced.
+func (f *Manager) WriteFileMode(path string, data []byte, perm os.FileMode) error {
 	if err := validatePath(path); err != nil {
 		return fmt.Errorf("invalid path for WriteFile: %w", err)
 	}
@@ -128,7 +135,12 @@ func (f *Manager) WriteFile(path string, data []byte) error {
 		return fmt.Errorf("failed to create parent directory for file %s: %w", fullPath, err)
 	}
 
-	if err := os.WriteFile(fullPath, data, filePermissions); err != nil {
+	// Restrict an existing file before writing, WriteFile only sets the
+	// permissions of new files
+	if err := os.Chmod(fullPath, perm); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to set permissions on %s: %w", fullPath, err)
+	}
+	if err := os.WriteFile(fullPath, data, perm); err != nil {
 		return fmt.Errorf("failed to write file %s: %w", fullPath, err)
 	}
 	return nil
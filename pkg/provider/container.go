@@ -1,5 +1,7 @@
 package provider
 
+import "github.com/stenh0use/hind/pkg/config"
+
 // Common container options across providers
 type containerOptions struct {
 	Name   string
@@ -15,10 +17,18 @@ type ContainerInfo struct {
 	HostName string
 	Status   string
 	Image    string
-	Ports    []string
-	Labels   map[string]string
-	Network  string
-	Address  string
+	// ImageID is the ID of the image the container was created from
+	ImageID string
+	Ports   []string
+	Labels  map[string]string
+	Network string
+	Address string
+	// Addresses of the container on every network it is attached to, keyed
+	// by network name
+	Addresses map[string]string
+	// Spec is the node configuration the container is running with, as
+	// reported by the provider. Nil when the provider cannot report it.
+	Spec *config.Node
 }
 
 type ContainerSummary struct{}
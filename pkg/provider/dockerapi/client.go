@@ -0,0 +1,220 @@
+// Package dockerapi implements a provider that talks to the Docker Engine
+// API over its unix socket, without depending on the docker binary.
+package dockerapi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/moby/moby/api/types/common"
+
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+// DefaultHost is the Docker Engine socket used when DOCKER_HOST is not set
+const DefaultHost = "unix:///var/run/docker.sock"
+
+// Client provides an interface to the Docker Engine API for cluster operations
+type Client struct {
+	logger *log.Logger
+	http   *http.Client
+	socket string
+}
+
+// Error is an error response from the Docker Engine API
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("docker api error (status %d): %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is a not found response from the API
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// New creates a new Docker Engine API client for DOCKER_HOST, or the
+// default socket if it is not set
+func New(logger *log.Logger) (provider.Client, error) {
+	host := os.Getenv("DOCKER_HOST")
+	if host == "" {
+		host = DefaultHost
+	}
+	return NewWithHost(logger, host)
+}
+
+// NewWithHost creates a new Docker Engine API client for a unix:// host
+func NewWithHost(logger *log.Logger, host string) (*Client, error) {
+	socket, ok := strings.CutPrefix(host, "unix://")
+	if !ok {
+		return nil, fmt.Errorf("unsupported docker host '%s', only unix sockets are supported", host)
+	}
+
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}
+
+	return &Client{
+		logger: logger,
+		http:   &http.Client{Transport: transport},
+		socket: socket,
+	}, nil
+}
+
+// do sends a request to the API and decodes the JSON response into out,
+// if it is not nil. Error responses are returned as an *Error.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body any, out any) error {
+	resp, err := c.send(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil || resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
+// send sends a request to the API and returns the response for the caller
+// to read and close, eg. to stream it. Error responses are returned as an
+// *Error.
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body any) (*http.Response, error) {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	u := url.URL{Scheme: "http", Host: "docker", Path: path, RawQuery: query.Encode()}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	c.logger.WithFields(log.Fields{"method": method, "url": u.String()}).Debug("Sending docker api request")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to docker: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		return nil, apiError(resp)
+	}
+	return resp, nil
+}
+
+// hijack sends a POST request upgraded to a raw stream, as the exec start
+// endpoint expects to attach stdin, and returns the connection and the
+// reader of the output stream. The caller closes the connection.
+func (c *Client) hijack(ctx context.Context, path string, body any) (net.Conn, io.Reader, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", "http://docker"+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "tcp")
+
+	c.logger.WithFields(log.Fields{"method": req.Method, "url": req.URL.String()}).Debug("Sending docker api upgrade request")
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", c.socket)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to docker: %w", err)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusSwitchingProtocols:
+		return conn, br, nil
+	case resp.StatusCode >= http.StatusBadRequest:
+		defer conn.Close()
+		return nil, nil, apiError(resp)
+	default:
+		// Without the upgrade the output is the response body
+		return conn, resp.Body, nil
+	}
+}
+
+// apiError reads the error message of an error response
+func apiError(resp *http.Response) error {
+	var errResp common.ErrorResponse
+	data, _ := io.ReadAll(resp.Body)
+	if err := json.Unmarshal(data, &errResp); err != nil || errResp.Message == "" {
+		errResp.Message = strings.TrimSpace(string(data))
+	}
+	return &Error{StatusCode: resp.StatusCode, Message: errResp.Message}
+}
+
+// filtersQuery converts key=value filters, as used by the docker CLI,
+// into the filters query parameter, eg. label=hind.cluster=dev
+func filtersQuery(filters []string) (url.Values, error) {
+	query := url.Values{}
+	if len(filters) == 0 {
+		return query, nil
+	}
+
+	args := map[string]map[string]bool{}
+	for _, f := range filters {
+		k, v, ok := strings.Cut(f, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid filter '%s', expected key=value", f)
+		}
+		if args[k] == nil {
+			args[k] = map[string]bool{}
+		}
+		args[k][v] = true
+	}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal filters: %w", err)
+	}
+	query.Set("filters", string(data))
+	return query, nil
+}
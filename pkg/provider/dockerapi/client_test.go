@@ -0,0 +1,407 @@
+package dockerapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/discard"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/mount"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+// newTestClient starts a stub Engine API serving handler on a unix socket
+// and returns a client connected to it
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("failed to listen on %s: %v", socket, err)
+	}
+
+	srv := httptest.NewUnstartedServer(handler)
+	srv.Listener = l
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	c, err := NewWithHost(&log.Logger{Handler: discard.Default}, "unix://"+socket)
+	if err != nil {
+		t.Fatalf("NewWithHost() error = %v", err)
+	}
+	return c
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestNewWithHost(t *testing.T) {
+	if _, err := NewWithHost(nil, "tcp://127.0.0.1:2375"); err == nil {
+		t.Error("NewWithHost() with tcp host error = nil, want error")
+	}
+	if _, err := NewWithHost(nil, "unix:///var/run/docker.sock"); err != nil {
+		t.Errorf("NewWithHost() error = %v, want nil", err)
+	}
+}
+
+func TestClient_Error(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusConflict, map[string]string{"message": "container name already in use"})
+	}))
+
+	err := c.StartContainer(context.Background(), "dev-consul-01")
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("StartContainer() error = %v, want *Error", err)
+	}
+	if apiErr.StatusCode != http.StatusConflict || apiErr.Message != "container name already in use" {
+		t.Errorf("StartContainer() error = %+v, want status 409 with message", apiErr)
+	}
+	if IsNotFound(err) {
+		t.Error("IsNotFound() = true, want false")
+	}
+}
+
+func TestInspectContainer_NotFound(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "No such container: missing"})
+	}))
+
+	info, err := c.InspectContainer(context.Background(), "missing")
+	if err != nil {
+		t.Fatalf("InspectContainer() error = %v, want nil", err)
+	}
+	if info != nil {
+		t.Errorf("InspectContainer() = %+v, want nil", info)
+	}
+}
+
+func TestCreateContainer(t *testing.T) {
+	var (
+		created container.CreateRequest
+		name    string
+		started string
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /containers/create", func(w http.ResponseWriter, r *http.Request) {
+		name = r.URL.Query().Get("name")
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &created); err != nil {
+			t.Errorf("failed to decode create request: %v", err)
+		}
+		writeJSON(w, http.StatusCreated, container.CreateResponse{ID: "abc123"})
+	})
+	mux.HandleFunc("POST /containers/{id}/start", func(w http.ResponseWriter, r *http.Request) {
+		started = r.PathValue("id")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	c := newTestClient(t, mux)
+
+	node := config.Node{
+		Name:        "dev-consul-01",
+		Network:     "hind.dev",
+		Image:       config.Image{Name: "hind.consul", Tag: "0.2.0"},
+		Environment: map[string]string{"CONSUL_BIND": "eth0"},
+		Labels:      config.Labels{"hind.cluster": "dev"},
+		Ports:       []config.PortMapping{{ContainerPort: 8500, HostPort: 8500, ListenAddress: "127.0.0.1"}},
+		Devices:     []string{"/dev/fuse"},
+		Volumes:     []config.Volume{{Name: "hind.dev.consul-data", Destination: "/consul/data"}},
+	}
+
+	id, err := c.CreateContainer(context.Background(), node)
+	if err != nil {
+		t.Fatalf("CreateContainer() error = %v", err)
+	}
+	if id != "abc123" || started != "abc123" {
+		t.Errorf("CreateContainer() = %q, started %q, want abc123", id, started)
+	}
+	if name != node.Name {
+		t.Errorf("create name = %q, want %q", name, node.Name)
+	}
+
+	if created.Config == nil || created.HostConfig == nil {
+		t.Fatalf("create request missing config: %+v", created)
+	}
+	if created.Image != "hind.consul:0.2.0" {
+		t.Errorf("Image = %q, want hind.consul:0.2.0", created.Image)
+	}
+	if created.Hostname != node.Name {
+		t.Errorf("Hostname = %q, want %q", created.Hostname, node.Name)
+	}
+	if len(created.Env) != 1 || created.Env[0] != "CONSUL_BIND=eth0" {
+		t.Errorf("Env = %v, want [CONSUL_BIND=eth0]", created.Env)
+	}
+
+	hc := created.HostConfig
+	if !hc.Privileged || hc.CgroupnsMode != container.CgroupnsModePrivate || string(hc.NetworkMode) != "hind.dev" {
+		t.Errorf("HostConfig = privileged %v, cgroupns %q, network %q", hc.Privileged, hc.CgroupnsMode, hc.NetworkMode)
+	}
+	if len(hc.Devices) != 1 || hc.Devices[0].PathInContainer != "/dev/fuse" || hc.Devices[0].CgroupPermissions != "rwm" {
+		t.Errorf("Devices = %+v, want /dev/fuse rwm", hc.Devices)
+	}
+
+	var bindings []string
+	for port, pb := range hc.PortBindings {
+		for _, b := range pb {
+			bindings = append(bindings, b.HostIP.String()+":"+b.HostPort+"->"+port.String())
+		}
+	}
+	if len(bindings) != 1 || bindings[0] != "127.0.0.1:8500->8500/tcp" {
+		t.Errorf("PortBindings = %v, want [127.0.0.1:8500->8500/tcp]", bindings)
+	}
+
+	if len(hc.Mounts) != 2 {
+		t.Fatalf("Mounts = %+v, want data volume and anonymous /var", hc.Mounts)
+	}
+	for _, m := range hc.Mounts {
+		if m.Type != mount.TypeVolume {
+			t.Errorf("mount %s type = %q, want volume", m.Target, m.Type)
+		}
+		if m.VolumeOptions == nil || m.VolumeOptions.Labels["hind.cluster"] != "dev" {
+			t.Errorf("mount %s volume labels = %+v, want hind.cluster=dev", m.Target, m.VolumeOptions)
+		}
+	}
+	if hc.Mounts[0].Source != "hind.dev.consul-data" || hc.Mounts[1].Target != "/var" {
+		t.Errorf("Mounts = %+v, want consul data then /var", hc.Mounts)
+	}
+}
+
+func TestListContainers(t *testing.T) {
+	var filters, all string
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filters = r.URL.Query().Get("filters")
+		all = r.URL.Query().Get("all")
+		io.WriteString(w, `[{
+			"Id": "abc123",
+			"Names": ["/dev-consul-01"],
+			"Image": "hind.consul:0.2.0",
+			"State": "running",
+			"Labels": {"hind.cluster": "dev"},
+			"Ports": [{"IP": "0.0.0.0", "PrivatePort": 8500, "PublicPort": 8500, "Type": "tcp"}],
+			"HostConfig": {"NetworkMode": "hind.dev"},
+			"NetworkSettings": {"Networks": {"hind.dev": {"IPAddress": "172.18.0.2"}}}
+		}]`)
+	}))
+
+	got, err := c.ListContainers(context.Background(), []string{"label=hind.cluster=dev"})
+	if err != nil {
+		t.Fatalf("ListContainers() error = %v", err)
+	}
+	if want := `{"label":{"hind.cluster=dev":true}}`; filters != want {
+		t.Errorf("filters = %s, want %s", filters, want)
+	}
+	if all != "1" {
+		t.Errorf("all = %q, want 1 to list stopped containers", all)
+	}
+	if len(got) != 1 || got[0].Name != "dev-consul-01" || got[0].Status != "running" {
+		t.Fatalf("ListContainers() = %+v, want dev-consul-01 running", got)
+	}
+	if got[0].Network != "hind.dev" || got[0].Address != "172.18.0.2" {
+		t.Errorf("ListContainers() network = %q, %q, want hind.dev, 172.18.0.2", got[0].Network, got[0].Address)
+	}
+	if len(got[0].Ports) != 1 || got[0].Ports[0] != "0.0.0.0:8500->8500/tcp" {
+		t.Errorf("ListContainers() ports = %v, want [0.0.0.0:8500->8500/tcp]", got[0].Ports)
+	}
+	if got[0].Labels["hind.cluster"] != "dev" {
+		t.Errorf("ListContainers() labels = %v, want hind.cluster=dev", got[0].Labels)
+	}
+}
+
+func TestInspectNetwork(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{
+			"Id": "def456",
+			"Name": "hind.dev",
+			"Driver": "bridge",
+			"IPAM": {"Config": [{"Subnet": "172.18.0.0/16", "Gateway": "172.18.0.1"}]}
+		}`)
+	}))
+
+	got, err := c.InspectNetwork(context.Background(), "hind.dev")
+	if err != nil {
+		t.Fatalf("InspectNetwork() error = %v", err)
+	}
+	if got == nil || got.Subnet != "172.18.0.0/16" || got.Gateway != "172.18.0.1" {
+		t.Errorf("InspectNetwork() = %+v, want subnet 172.18.0.0/16 and gateway 172.18.0.1", got)
+	}
+}
+
+func TestConnectNetwork(t *testing.T) {
+	var path string
+	var body map[string]any
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	if err := c.ConnectNetwork(context.Background(), "hind.prod", "hind.dev.consul.01"); err != nil {
+		t.Fatalf("ConnectNetwork() error = %v", err)
+	}
+	if !strings.HasSuffix(path, "/networks/hind.prod/connect") || body["Container"] != "hind.dev.consul.01" {
+		t.Errorf("ConnectNetwork() sent %s %v, want the container connected to hind.prod", path, body)
+	}
+}
+
+// frame encodes a chunk of multiplexed exec output for a stream
+func frame(stream byte, data string) []byte {
+	header := []byte{stream, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(header[4:], uint32(len(data)))
+	return append(header, data...)
+}
+
+func TestExec(t *testing.T) {
+	tests := []struct {
+		name     string
+		exitCode int
+		wantCode int
+	}{
+		{name: "success", exitCode: 0},
+		{name: "failure", exitCode: 3, wantCode: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created container.ExecCreateRequest
+			c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/containers/dev-consul-01/exec":
+					json.NewDecoder(r.Body).Decode(&created)
+					writeJSON(w, http.StatusCreated, map[string]string{"Id": "exec1"})
+				case "/exec/exec1/start":
+					w.Write(frame(1, "out\n"))
+					w.Write(frame(2, "err\n"))
+				case "/exec/exec1/json":
+					writeJSON(w, http.StatusOK, map[string]any{"ID": "exec1", "ExitCode": tt.exitCode})
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+
+			var stdout, stderr bytes.Buffer
+			err := c.Exec(context.Background(), "dev-consul-01", provider.ExecOptions{
+				Cmd:    []string{"journalctl", "--unit", "consul"},
+				Stdout: &stdout,
+				Stderr: &stderr,
+			})
+
+			var exitErr *provider.ExitError
+			switch {
+			case tt.wantCode == 0 && err != nil:
+				t.Fatalf("Exec() error = %v", err)
+			case tt.wantCode != 0 && (!errors.As(err, &exitErr) || exitErr.Code != tt.wantCode):
+				t.Fatalf("Exec() error = %v, want exit code %d", err, tt.wantCode)
+			}
+			if strings.Join(created.Cmd, " ") != "journalctl --unit consul" || !created.AttachStdout || !created.AttachStderr {
+				t.Errorf("exec create request = %+v", created)
+			}
+			if stdout.String() != "out\n" || stderr.String() != "err\n" {
+				t.Errorf("Exec() stdout = %q, stderr = %q, want out and err", stdout.String(), stderr.String())
+			}
+		})
+	}
+}
+
+func TestExec_TTY(t *testing.T) {
+	var created container.ExecCreateRequest
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/containers/dev-consul-01/exec":
+			json.NewDecoder(r.Body).Decode(&created)
+			writeJSON(w, http.StatusCreated, map[string]string{"Id": "exec1"})
+		case "/exec/exec1/start":
+			if r.Header.Get("Upgrade") != "tcp" {
+				t.Errorf("exec start Upgrade header = %q, want tcp", r.Header.Get("Upgrade"))
+			}
+			io.ReadAll(r.Body)
+			conn, buf, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Errorf("Hijack() error = %v", err)
+				return
+			}
+			defer conn.Close()
+			io.WriteString(conn, "HTTP/1.1 101 UPGRADED\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n")
+			// Echo the input until the client closes its side
+			io.Copy(conn, buf)
+		case "/exec/exec1/json":
+			writeJSON(w, http.StatusOK, map[string]any{"ID": "exec1", "ExitCode": 0})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	var stdout bytes.Buffer
+	err := c.Exec(context.Background(), "dev-consul-01", provider.ExecOptions{
+		Cmd:    []string{"cat"},
+		Env:    map[string]string{"NOMAD_ADDR": "http://127.0.0.1:4646"},
+		Stdin:  strings.NewReader("hello\n"),
+		TTY:    true,
+		Stdout: &stdout,
+	})
+	if err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if !created.Tty || !created.AttachStdin || strings.Join(created.Env, " ") != "NOMAD_ADDR=http://127.0.0.1:4646" {
+		t.Errorf("exec create request = %+v, want tty, stdin and env", created)
+	}
+	if stdout.String() != "hello\n" {
+		t.Errorf("Exec() stdout = %q, want the echoed input", stdout.String())
+	}
+}
+
+func TestImageExists(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/images/hind.consul:0.2.0/json" {
+			writeJSON(w, http.StatusOK, map[string]string{"Id": "sha256:abc"})
+			return
+		}
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "No such image"})
+	}))
+
+	tests := []struct {
+		ref  string
+		want bool
+	}{
+		{"hind.consul:0.2.0", true},
+		{"hind.consul:0.1.0", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := c.ImageExists(context.Background(), tt.ref)
+			if err != nil {
+				t.Fatalf("ImageExists() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ImageExists() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFiltersQuery(t *testing.T) {
+	if _, err := filtersQuery([]string{"dangling"}); err == nil {
+		t.Error("filtersQuery() error = nil, want error for filter without value")
+	}
+	q, err := filtersQuery(nil)
+	if err != nil || len(q) != 0 {
+		t.Errorf("filtersQuery(nil) = %v, %v, want empty", q, err)
+	}
+}
@@ -0,0 +1,396 @@
+package dockerapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/moby/moby/api/pkg/stdcopy"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/mount"
+	"github.com/moby/moby/api/types/network"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+	"github.com/stenh0use/hind/pkg/provider/internal/inspect"
+)
+
+// Create and start a container
+func (c *Client) CreateContainer(ctx context.Context, cfg config.Node) (string, error) {
+	if cfg.Name == "" {
+		return "", fmt.Errorf("name is required to create a container")
+	}
+
+	req, err := createRequest(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	var resp container.CreateResponse
+	query := url.Values{"name": {cfg.Name}}
+	if err := c.do(ctx, "POST", "/containers/create", query, req, &resp); err != nil {
+		return "", fmt.Errorf("failed to create container: %w", err)
+	}
+	for _, w := range resp.Warnings {
+		c.logger.WithField("container", cfg.Name).Warn(w)
+	}
+
+	if err := c.StartContainer(ctx, resp.ID); err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// createRequest converts a node into a container create request, with the
+// same settings the dockercli provider passes to docker run.
+func createRequest(cfg config.Node) (*container.CreateRequest, error) {
+	if cfg.Image.Name == "" {
+		return nil, fmt.Errorf("image name is required")
+	}
+
+	hostConfig := &container.HostConfig{
+		CgroupnsMode:  container.CgroupnsModePrivate,
+		Privileged:    true,
+		RestartPolicy: container.RestartPolicy{Name: container.RestartPolicyOnFailure, MaximumRetryCount: 1},
+		Tmpfs:         map[string]string{"/run": "", "/tmp": ""},
+		SecurityOpt:   []string{"seccomp=unconfined", "apparmor=unconfined"},
+		Binds:         []string{inspect.ModulesMount + ":" + inspect.ModulesMount + ":ro"},
+		Init:          new(bool),
+	}
+	if cfg.Network != "" {
+		hostConfig.NetworkMode = container.NetworkMode(cfg.Network)
+	}
+
+	// The node gets an anonymous /var volume unless one is configured
+	volumes := cfg.Volumes
+	if !slices.ContainsFunc(volumes, func(v config.Volume) bool { return v.Destination == inspect.VarMount }) {
+		volumes = append(volumes, config.Volume{Type: config.VolumeMount, Destination: inspect.VarMount})
+	}
+	for _, v := range volumes {
+		m, err := mountSpec(v, cfg.Labels)
+		if err != nil {
+			return nil, err
+		}
+		hostConfig.Mounts = append(hostConfig.Mounts, m)
+	}
+
+	exposed := network.PortSet{}
+	for _, p := range cfg.Ports {
+		port, binding, err := portBinding(p)
+		if err != nil {
+			return nil, err
+		}
+		exposed[port] = struct{}{}
+		if hostConfig.PortBindings == nil {
+			hostConfig.PortBindings = network.PortMap{}
+		}
+		hostConfig.PortBindings[port] = append(hostConfig.PortBindings[port], binding)
+	}
+
+	for _, d := range cfg.Devices {
+		hostConfig.Devices = append(hostConfig.Devices, deviceMapping(d))
+	}
+
+	env := make([]string, 0, len(cfg.Environment))
+	for _, k := range slices.Sorted(maps.Keys(cfg.Environment)) {
+		env = append(env, fmt.Sprintf("%s=%s", k, cfg.Environment[k]))
+	}
+
+	return &container.CreateRequest{
+		Config: &container.Config{
+			Hostname:     cfg.Name,
+			Image:        cfg.Image.Ref(),
+			Tty:          true,
+			Env:          env,
+			Labels:       cfg.Labels,
+			ExposedPorts: exposed,
+		},
+		HostConfig: hostConfig,
+	}, nil
+}
+
+// mountSpec converts a volume into a mount. Volumes docker creates for the
+// mount are given the node labels and their own.
+func mountSpec(v config.Volume, nodeLabels config.Labels) (mount.Mount, error) {
+	if v.Destination == "" {
+		return mount.Mount{}, fmt.Errorf("volume destination is required")
+	}
+
+	m := mount.Mount{
+		Type:     mount.Type(v.MountType()),
+		Target:   v.Destination,
+		ReadOnly: v.ReadOnly,
+	}
+
+	switch v.MountType() {
+	case config.VolumeMount:
+		m.Source = v.Name
+		labels := maps.Clone(nodeLabels)
+		if labels == nil {
+			labels = config.Labels{}
+		}
+		maps.Copy(labels, v.Labels)
+		m.VolumeOptions = &mount.VolumeOptions{Labels: labels}
+	case config.BindMount:
+		if v.Source == "" {
+			return mount.Mount{}, fmt.Errorf("bind mount '%s' requires a source", v.Destination)
+		}
+		m.Source = v.Source
+	case config.TmpfsMount:
+		if v.Source != "" || v.Name != "" {
+			return mount.Mount{}, fmt.Errorf("tmpfs mount '%s' cannot have a source", v.Destination)
+		}
+	default:
+		return mount.Mount{}, fmt.Errorf("unknown mount type '%s' for '%s'", v.Type, v.Destination)
+	}
+
+	return m, nil
+}
+
+// portBinding converts a port mapping into the container port and its binding
+func portBinding(p config.PortMapping) (network.Port, network.PortBinding, error) {
+	if p.ContainerPort == 0 {
+		return network.Port{}, network.PortBinding{}, fmt.Errorf("container port is required")
+	}
+
+	spec := strconv.Itoa(int(p.ContainerPort))
+	if p.Protocol != "" {
+		spec += "/" + p.Protocol
+	}
+	port, err := network.ParsePort(spec)
+	if err != nil {
+		return network.Port{}, network.PortBinding{}, fmt.Errorf("invalid port '%s': %w", spec, err)
+	}
+
+	var binding network.PortBinding
+	if p.ListenAddress != "" {
+		addr, err := netip.ParseAddr(p.ListenAddress)
+		if err != nil {
+			return network.Port{}, network.PortBinding{}, fmt.Errorf("invalid listen address '%s': %w", p.ListenAddress, err)
+		}
+		binding.HostIP = addr
+	}
+	if p.HostPort != 0 {
+		binding.HostPort = strconv.Itoa(int(p.HostPort))
+	}
+
+	return port, binding, nil
+}
+
+// deviceMapping converts a device in the docker --device form, eg.
+// /dev/fuse or /dev/fuse:/dev/fuse:rwm, into a device mapping
+func deviceMapping(d string) container.DeviceMapping {
+	parts := strings.Split(d, ":")
+	m := container.DeviceMapping{PathOnHost: parts[0], PathInContainer: parts[0], CgroupPermissions: "rwm"}
+	if len(parts) > 1 {
+		m.PathInContainer = parts[1]
+	}
+	if len(parts) > 2 {
+		m.CgroupPermissions = parts[2]
+	}
+	return m
+}
+
+// Start a container if it is stopped
+func (c *Client) StartContainer(ctx context.Context, name string) error {
+	if name == "" {
+		return fmt.Errorf("name is required to start a container")
+	}
+
+	c.logger.WithField("container", name).Debug("starting container")
+
+	if err := c.do(ctx, "POST", "/containers/"+name+"/start", nil, nil, nil); err != nil {
+		return fmt.Errorf("failed to start container: %w", err)
+	}
+	return nil
+}
+
+// Stop a container if it is running
+func (c *Client) StopContainer(ctx context.Context, name string) error {
+	if name == "" {
+		return fmt.Errorf("name or id is required to stop a container")
+	}
+
+	c.logger.WithField("container", name).Debug("stopping container")
+
+	if err := c.do(ctx, "POST", "/containers/"+name+"/stop", nil, nil, nil); err != nil {
+		return fmt.Errorf("failed to stop container: %w", err)
+	}
+	return nil
+}
+
+// Delete a container and its anonymous volumes
+func (c *Client) DeleteContainer(ctx context.Context, name string) error {
+	if name == "" {
+		return fmt.Errorf("name or id is required to delete a container")
+	}
+
+	if err := c.do(ctx, "DELETE", "/containers/"+name, url.Values{"v": {"1"}}, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete container: %w", err)
+	}
+	return nil
+}
+
+// Inspect container state
+func (c *Client) InspectContainer(ctx context.Context, name string) (*provider.ContainerInfo, error) {
+	if name == "" {
+		return nil, fmt.Errorf("name is required to inspect a container")
+	}
+
+	res := &container.InspectResponse{}
+	if err := c.do(ctx, "GET", "/containers/"+name+"/json", nil, nil, res); err != nil {
+		if IsNotFound(err) {
+			c.logger.WithField("name", name).Debug("container not found")
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to inspect container: %w", err)
+	}
+
+	response := &provider.ContainerInfo{
+		ID:      res.ID,
+		Name:    strings.TrimPrefix(res.Name, "/"),
+		Created: res.Created,
+		ImageID: res.Image,
+		Spec:    inspect.NodeSpec(res),
+	}
+	if res.Config != nil {
+		response.HostName = res.Config.Hostname
+		response.Image = res.Config.Image
+		response.Labels = res.Config.Labels
+	}
+	if res.State != nil {
+		response.Status = string(res.State.Status)
+	}
+	if res.NetworkSettings != nil {
+		response.Ports = inspect.PublishedPorts(res.NetworkSettings.Ports)
+		response.Network, response.Address = inspect.EndpointAddress(
+			response.Spec.Network, res.NetworkSettings.Networks)
+		response.Addresses = inspect.NetworkAddresses(res.NetworkSettings.Networks)
+	}
+
+	return response, nil
+}
+
+// List containers
+func (c *Client) ListContainers(ctx context.Context, filters []string) ([]provider.ContainerInfo, error) {
+	var response []provider.ContainerInfo
+
+	query, err := filtersQuery(filters)
+	if err != nil {
+		return response, err
+	}
+	query.Set("all", "1")
+
+	var summaries []container.Summary
+	if err := c.do(ctx, "GET", "/containers/json", query, nil, &summaries); err != nil {
+		return response, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	for _, s := range summaries {
+		var name string
+		if len(s.Names) > 0 {
+			name = strings.TrimPrefix(s.Names[0], "/")
+		}
+		info := provider.ContainerInfo{
+			ID:      s.ID,
+			Name:    name,
+			Created: time.Unix(s.Created, 0).UTC().Format(time.RFC3339Nano),
+			Status:  string(s.State),
+			Image:   s.Image,
+			ImageID: s.ImageID,
+			Ports:   inspect.SummaryPorts(s.Ports),
+			Labels:  s.Labels,
+			Network: s.HostConfig.NetworkMode,
+		}
+		if s.NetworkSettings != nil {
+			info.Network, info.Address = inspect.EndpointAddress(
+				s.HostConfig.NetworkMode, s.NetworkSettings.Networks)
+			info.Addresses = inspect.NetworkAddresses(s.NetworkSettings.Networks)
+		}
+		response = append(response, info)
+	}
+
+	return response, nil
+}
+
+// Run a command in a running container and wait for it to exit
+func (c *Client) Exec(ctx context.Context, name string, opts provider.ExecOptions) error {
+	if name == "" {
+		return fmt.Errorf("name is required to exec in a container")
+	}
+	if len(opts.Cmd) == 0 {
+		return fmt.Errorf("command is required to exec in a container")
+	}
+
+	c.logger.WithFields(log.Fields{"container": name, "cmd": opts.Cmd}).Debug("exec in container")
+
+	req := container.ExecCreateRequest{
+		AttachStdin:  opts.Stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+		Tty:          opts.TTY,
+		ConsoleSize:  opts.ConsoleSize,
+		Env:          opts.EnvList(),
+		Cmd:          opts.Cmd,
+	}
+	var created container.ExecCreateResponse
+	if err := c.do(ctx, "POST", "/containers/"+name+"/exec", nil, req, &created); err != nil {
+		return fmt.Errorf("failed to create exec: %w", err)
+	}
+
+	start := container.ExecStartRequest{Tty: opts.TTY, ConsoleSize: opts.ConsoleSize}
+	conn, stream, err := c.hijack(ctx, "/exec/"+created.ID+"/start", start)
+	if err != nil {
+		return fmt.Errorf("failed to start exec: %w", err)
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if opts.Stdin != nil {
+		go func() {
+			io.Copy(conn, opts.Stdin)
+			// Closing the write side ends the input of the command
+			if cw, ok := conn.(interface{ CloseWrite() error }); ok {
+				cw.CloseWrite()
+			}
+		}()
+	}
+
+	stdout, stderr := opts.Stdout, opts.Stderr
+	if stdout == nil {
+		stdout = io.Discard
+	}
+	if stderr == nil {
+		stderr = io.Discard
+	}
+	// Without a TTY the output is multiplexed, stdcopy splits the streams
+	if opts.TTY {
+		_, err = io.Copy(stdout, stream)
+	} else {
+		_, err = stdcopy.StdCopy(stdout, stderr, stream)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("failed to read exec output: %w", err)
+	}
+
+	var inspected container.ExecInspectResponse
+	if err := c.do(ctx, "GET", "/exec/"+created.ID+"/json", nil, nil, &inspected); err != nil {
+		return fmt.Errorf("failed to inspect exec: %w", err)
+	}
+	if inspected.ExitCode != nil && *inspected.ExitCode != 0 {
+		return &provider.ExitError{Code: *inspected.ExitCode}
+	}
+	return nil
+}
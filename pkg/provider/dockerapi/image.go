@@ -0,0 +1,31 @@
+package dockerapi
+
+import (
+	"context"
+	"fmt"
+)
+
+// Check if an image exists locally
+func (c *Client) ImageExists(ctx context.Context, ref string) (bool, error) {
+	id, err := c.ImageID(ctx, ref)
+	return id != "", err
+}
+
+// Get the ID of a local image, empty when it does not exist
+func (c *Client) ImageID(ctx context.Context, ref string) (string, error) {
+	if ref == "" {
+		return "", fmt.Errorf("image reference is required to inspect an image")
+	}
+
+	var res struct {
+		ID string `json:"Id"`
+	}
+	if err := c.do(ctx, "GET", "/images/"+ref+"/json", nil, nil, &res); err != nil {
+		if IsNotFound(err) {
+			c.logger.WithField("image", ref).Debug("image not found")
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to inspect image: %w", err)
+	}
+	return res.ID, nil
+}
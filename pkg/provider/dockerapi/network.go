@@ -0,0 +1,138 @@
+package dockerapi
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/moby/moby/api/types/network"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+	"github.com/stenh0use/hind/pkg/provider/internal/inspect"
+)
+
+// Create a new docker network
+func (c *Client) CreateNetwork(ctx context.Context, cfg config.Network) (string, error) {
+	if cfg.Name == "" {
+		return "", fmt.Errorf("name is required to create a network")
+	}
+
+	req := network.CreateRequest{
+		Name:   cfg.Name,
+		Driver: cfg.Driver,
+		Labels: cfg.Labels,
+	}
+	if cfg.Subnet != "" || cfg.Gateway != "" {
+		var ipam network.IPAMConfig
+		if cfg.Subnet != "" {
+			subnet, err := netip.ParsePrefix(cfg.Subnet)
+			if err != nil {
+				return "", fmt.Errorf("invalid subnet '%s': %w", cfg.Subnet, err)
+			}
+			ipam.Subnet = subnet
+		}
+		if cfg.Gateway != "" {
+			gateway, err := netip.ParseAddr(cfg.Gateway)
+			if err != nil {
+				return "", fmt.Errorf("invalid gateway '%s': %w", cfg.Gateway, err)
+			}
+			ipam.Gateway = gateway
+		}
+		req.IPAM = &network.IPAM{Config: []network.IPAMConfig{ipam}}
+	}
+
+	var resp network.CreateResponse
+	if err := c.do(ctx, "POST", "/networks/create", nil, req, &resp); err != nil {
+		return "", fmt.Errorf("failed to create network: %w", err)
+	}
+	return resp.ID, nil
+}
+
+// Delete a network
+func (c *Client) DeleteNetwork(ctx context.Context, name string) error {
+	if name == "" {
+		return fmt.Errorf("name is required to delete a network")
+	}
+
+	if err := c.do(ctx, "DELETE", "/networks/"+name, nil, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete network: %w", err)
+	}
+	return nil
+}
+
+// Inspect network state
+func (c *Client) InspectNetwork(ctx context.Context, name string) (*provider.NetworkInfo, error) {
+	if name == "" {
+		return nil, fmt.Errorf("name is required to inspect a network")
+	}
+
+	res := &network.Inspect{}
+	if err := c.do(ctx, "GET", "/networks/"+name, nil, nil, res); err != nil {
+		if IsNotFound(err) {
+			c.logger.WithField("name", name).Debug("network not found")
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to inspect network: %w", err)
+	}
+
+	return networkInfo(res.Network), nil
+}
+
+// Attach a container to another network
+func (c *Client) ConnectNetwork(ctx context.Context, name, container string) error {
+	if name == "" || container == "" {
+		return fmt.Errorf("network and container are required to connect a network")
+	}
+
+	req := network.ConnectRequest{Container: container}
+	if err := c.do(ctx, "POST", "/networks/"+name+"/connect", nil, req, nil); err != nil {
+		return fmt.Errorf("failed to connect network: %w", err)
+	}
+	return nil
+}
+
+// Detach a container from a network
+func (c *Client) DisconnectNetwork(ctx context.Context, name, container string) error {
+	if name == "" || container == "" {
+		return fmt.Errorf("network and container are required to disconnect a network")
+	}
+
+	req := network.DisconnectRequest{Container: container}
+	if err := c.do(ctx, "POST", "/networks/"+name+"/disconnect", nil, req, nil); err != nil {
+		return fmt.Errorf("failed to disconnect network: %w", err)
+	}
+	return nil
+}
+
+// List networks
+func (c *Client) ListNetworks(ctx context.Context, filters []string) ([]provider.NetworkInfo, error) {
+	var response []provider.NetworkInfo
+
+	query, err := filtersQuery(filters)
+	if err != nil {
+		return response, err
+	}
+
+	var summaries []network.Summary
+	if err := c.do(ctx, "GET", "/networks", query, nil, &summaries); err != nil {
+		return response, fmt.Errorf("failed to list networks: %w", err)
+	}
+
+	for _, s := range summaries {
+		response = append(response, *networkInfo(s.Network))
+	}
+	return response, nil
+}
+
+func networkInfo(n network.Network) *provider.NetworkInfo {
+	info := &provider.NetworkInfo{
+		ID:      n.ID,
+		Name:    n.Name,
+		Created: n.Created,
+		Driver:  n.Driver,
+		Labels:  n.Labels,
+	}
+	info.Subnet, info.Gateway = inspect.SubnetGateway(n.IPAM)
+	return info
+}
@@ -0,0 +1,50 @@
+package dockerapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/moby/moby/api/types/volume"
+
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+// List volumes
+func (c *Client) ListVolumes(ctx context.Context, filters []string) ([]provider.VolumeInfo, error) {
+	var response []provider.VolumeInfo
+
+	query, err := filtersQuery(filters)
+	if err != nil {
+		return response, err
+	}
+
+	var res volume.ListResponse
+	if err := c.do(ctx, "GET", "/volumes", query, nil, &res); err != nil {
+		return response, fmt.Errorf("failed to list volumes: %w", err)
+	}
+
+	for _, v := range res.Volumes {
+		if v == nil {
+			continue
+		}
+		response = append(response, provider.VolumeInfo{
+			Name:       v.Name,
+			Driver:     v.Driver,
+			Mountpoint: v.Mountpoint,
+			Labels:     v.Labels,
+		})
+	}
+	return response, nil
+}
+
+// Delete a volume
+func (c *Client) DeleteVolume(ctx context.Context, name string) error {
+	if name == "" {
+		return fmt.Errorf("name is required to delete a volume")
+	}
+
+	if err := c.do(ctx, "DELETE", "/volumes/"+name, nil, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete volume: %w", err)
+	}
+	return nil
+}
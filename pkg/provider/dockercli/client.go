@@ -2,7 +2,11 @@ package dockercli
 
 import (
 	"context"
+	"maps"
+	"os"
 	"os/exec"
+	"slices"
+	"strings"
 
 	"github.com/apex/log"
 	"github.com/stenh0use/hind/pkg/provider"
@@ -29,3 +33,37 @@ func baseClientCmd(ctx context.Context, arg ...string) *exec.Cmd {
 		arg...,
 	)
 }
+
+// setEnv sets env in the environment of the client process. Commands pass
+// the variables on with --env KEY flags that carry only the names, so the
+// values, eg. ACL tokens, stay out of the process list and the debug logs.
+func setEnv(cmd *exec.Cmd, env map[string]string) {
+	if len(env) == 0 {
+		return
+	}
+	cmd.Env = os.Environ()
+	for _, k := range slices.Sorted(maps.Keys(env)) {
+		cmd.Env = append(cmd.Env, k+"="+env[k])
+	}
+}
+
+// parseLabels parses the comma separated key=value labels of list output
+func (c *Client) parseLabels(s string) map[string]string {
+	labels := map[string]string{}
+	if s == "" {
+		return labels
+	}
+	for _, label := range strings.Split(s, ",") {
+		label = strings.TrimSpace(label)
+		if label == "" {
+			continue
+		}
+		kvpair := strings.SplitN(label, "=", 2)
+		if len(kvpair) == 2 {
+			labels[kvpair[0]] = kvpair[1]
+		} else {
+			c.logger.WithField("label", label).Debug("skipping malformed label")
+		}
+	}
+	return labels
+}
@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"os/exec"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -14,6 +17,7 @@ import (
 
 	"github.com/stenh0use/hind/pkg/config"
 	"github.com/stenh0use/hind/pkg/provider"
+	"github.com/stenh0use/hind/pkg/provider/internal/inspect"
 )
 
 const containerCmd = "container"
@@ -44,7 +48,6 @@ func (c *Client) CreateContainer(ctx context.Context, cfg config.Node) (string,
 	cmd.Args = append(cmd.Args, "--security-opt", "seccomp=unconfined")
 	cmd.Args = append(cmd.Args, "--security-opt", "apparmor=unconfined")
 	cmd.Args = append(cmd.Args, "--volume", "/lib/modules:/lib/modules:ro")
-	cmd.Args = append(cmd.Args, "--volume", "/var")
 
 	// Add network if specified
 	if cfg.Network != "" {
@@ -55,20 +58,24 @@ func (c *Client) CreateContainer(ctx context.Context, cfg config.Node) (string,
 		return "", fmt.Errorf("image name is required")
 	}
 
-	var imgRef string
-	if cfg.Image.Digest != "" {
-		imgRef = fmt.Sprintf("%s:%s", cfg.Image.Name, cfg.Image.Digest)
-	} else if cfg.Image.Tag != "" {
-		imgRef = fmt.Sprintf("%s:%s", cfg.Image.Name, cfg.Image.Tag)
-	} else {
-		imgRef = cfg.Name
-	}
+	imgRef := cfg.Image.Ref()
 	// add container name
 	if cfg.Name != "" {
 		cmd.Args = append(cmd.Args, "--name", cfg.Name)
 		cmd.Args = append(cmd.Args, "--hostname", cfg.Name)
 	}
-	// TODO add volumes
+	// The node gets an anonymous /var volume unless one is configured
+	volumes := cfg.Volumes
+	if !slices.ContainsFunc(volumes, func(v config.Volume) bool { return v.Destination == inspect.VarMount }) {
+		volumes = append(volumes, config.Volume{Type: config.VolumeMount, Destination: inspect.VarMount})
+	}
+	for _, v := range volumes {
+		mount, err := mountArg(v, cfg.Labels)
+		if err != nil {
+			return "", err
+		}
+		cmd.Args = append(cmd.Args, "--mount", mount)
+	}
 	if cfg.Ports != nil {
 		for _, p := range cfg.Ports {
 			var publishPorts []string
@@ -96,11 +103,10 @@ func (c *Client) CreateContainer(ctx context.Context, cfg config.Node) (string,
 			cmd.Args = append(cmd.Args, "--publish", publishStr)
 		}
 	}
-	if cfg.Environment != nil {
-		for k, v := range cfg.Environment {
-			cmd.Args = append(cmd.Args, "--env", fmt.Sprintf("%s=%s", k, v))
-		}
+	for _, k := range slices.Sorted(maps.Keys(cfg.Environment)) {
+		cmd.Args = append(cmd.Args, "--env", k)
 	}
+	setEnv(cmd, cfg.Environment)
 	if cfg.Labels != nil {
 		for k, v := range cfg.Labels {
 			cmd.Args = append(cmd.Args, "--label", fmt.Sprintf("%s=%s", k, v))
@@ -159,15 +165,14 @@ func (c *Client) StopContainer(ctx context.Context, name string) error {
 	return nil
 }
 
-// Delete a container
-// TODO: need options such as `-v` to remove anonymous volumes on delete
+// Delete a container and its anonymous volumes
 func (c *Client) DeleteContainer(ctx context.Context, name string) error {
 	if name == "" {
 		return fmt.Errorf("name or id is required to delete a container")
 	}
 
 	cmd := baseContainerCmd(ctx)
-	cmd.Args = append(cmd.Args, "rm", name)
+	cmd.Args = append(cmd.Args, "rm", "--volumes", name)
 
 	_, err := cmd.Output()
 	if err != nil {
@@ -180,63 +185,75 @@ func (c *Client) DeleteContainer(ctx context.Context, name string) error {
 
 // Inspect container state
 func (c *Client) InspectContainer(ctx context.Context, name string) (*provider.ContainerInfo, error) {
-	var response *provider.ContainerInfo
-
 	if name == "" {
-		return response, fmt.Errorf("name is required to inspect a container")
+		return nil, fmt.Errorf("name is required to inspect a container")
+	}
+
+	responses, err := c.inspectContainers(ctx, name)
+	if err != nil {
+		return nil, err
 	}
+	if len(responses) == 0 {
+		c.logger.WithField("name", name).Debug("container not found")
+		return nil, nil
+	}
+
+	return containerInfo(&responses[0]), nil
+}
 
+// inspectContainers inspects the named containers in a single command,
+// skipping the ones that don't exist
+func (c *Client) inspectContainers(ctx context.Context, names ...string) ([]container.InspectResponse, error) {
 	cmd := baseContainerCmd(ctx)
-	cmd.Args = append(cmd.Args, "inspect", "--format", "{{ . | json }}", name)
+	cmd.Args = append(cmd.Args, "inspect")
+	cmd.Args = append(cmd.Args, names...)
 
 	c.logger.WithField("command", cmd.String()).Debug("Running container inspect command")
 
 	out, err := cmd.Output()
 	if err != nil {
-		// Check if container doesn't exist
-		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
-			c.logger.WithField("name", name).Debug("container not found")
-			return nil, nil
+		// docker exits 1 when a container doesn't exist but still prints
+		// the ones it found
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok || exitErr.ExitCode() != 1 {
+			c.logger.WithFields(log.Fields{"output": out, "err": err}).Debug("error")
+			return nil, fmt.Errorf("failed to inspect container: %w", err)
 		}
-		c.logger.WithFields(log.Fields{"output": out, "err": err}).Debug("error")
-		return response, fmt.Errorf("failed to inspect container: %w", err)
 	}
 
-	res := &container.InspectResponse{}
-
-	err = json.Unmarshal(out, res)
-	if err != nil {
-		return response, fmt.Errorf("failed to unmarshal inspect response: %w", err)
+	var responses []container.InspectResponse
+	if len(bytes.TrimSpace(out)) == 0 {
+		return responses, nil
 	}
-
-	response = &provider.ContainerInfo{
-		ID:       res.ID,
-		Name:     res.Name,
-		Created:  res.Created,
-		HostName: res.Config.Hostname,
-		Status:   res.State.Status,
-		Image:    res.Config.Image,
+	if err := json.Unmarshal(out, &responses); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal inspect response: %w", err)
 	}
-
-	return response, nil
+	return responses, nil
 }
 
-// listEntry represents the JSON output from docker container ls
-type listEntry struct {
-	Command      string `json:"Command"`
-	CreatedAt    string `json:"CreatedAt"`
-	ID           string `json:"ID"`
-	Image        string `json:"Image"`
-	Labels       string `json:"Labels"`
-	LocalVolumes string `json:"LocalVolumes"`
-	Mounts       string `json:"Mounts"`
-	Names        string `json:"Names"`
-	Networks     string `json:"Networks"`
-	Ports        string `json:"Ports"`
-	RunningFor   string `json:"RunningFor"`
-	Size         string `json:"Size"`
-	State        string `json:"State"`
-	Status       string `json:"Status"`
+func containerInfo(res *container.InspectResponse) *provider.ContainerInfo {
+	info := &provider.ContainerInfo{
+		ID:      res.ID,
+		Name:    strings.TrimPrefix(res.Name, "/"),
+		Created: res.Created,
+		ImageID: res.Image,
+		Spec:    inspect.NodeSpec(res),
+	}
+	if res.Config != nil {
+		info.HostName = res.Config.Hostname
+		info.Image = res.Config.Image
+		info.Labels = res.Config.Labels
+	}
+	if res.State != nil {
+		info.Status = string(res.State.Status)
+	}
+	if res.NetworkSettings != nil {
+		info.Ports = inspect.PublishedPorts(res.NetworkSettings.Ports)
+		info.Network, info.Address = inspect.EndpointAddress(
+			info.Spec.Network, res.NetworkSettings.Networks)
+		info.Addresses = inspect.NetworkAddresses(res.NetworkSettings.Networks)
+	}
+	return info
 }
 
 // List containers
@@ -244,7 +261,7 @@ func (c *Client) ListContainers(ctx context.Context, filters []string) ([]provid
 	var response []provider.ContainerInfo
 
 	cmd := baseContainerCmd(ctx)
-	cmd.Args = append(cmd.Args, "ls", "--format", "{{ . | json }}")
+	cmd.Args = append(cmd.Args, "ls", "--all", "--quiet", "--no-trunc")
 
 	for _, f := range filters {
 		cmd.Args = append(cmd.Args, "--filter", f)
@@ -257,29 +274,57 @@ func (c *Client) ListContainers(ctx context.Context, filters []string) ([]provid
 		return response, fmt.Errorf("failed to list containers: %w", err)
 	}
 
-	if len(out) == 0 {
+	// The list output has no addresses, inspect the containers for them
+	ids := strings.Fields(string(out))
+	if len(ids) == 0 {
 		return response, nil
 	}
 
-	for _, line := range bytes.Split(out, []byte("\n")) {
-		if len(line) == 0 {
-			continue
-		}
-		var entry listEntry
-
-		err = json.Unmarshal(line, &entry)
-		if err != nil {
-			return response, fmt.Errorf("failed to unmarshal list response: %w", err)
-		}
-
-		response = append(response, provider.ContainerInfo{
-			ID:     entry.ID,
-			Name:   entry.Names,
-			Status: entry.State,
-			Image:  entry.Image,
-		})
+	responses, err := c.inspectContainers(ctx, ids...)
+	if err != nil {
+		return response, err
+	}
+	for i := range responses {
+		response = append(response, *containerInfo(&responses[i]))
 	}
 
 	return response, nil
+}
 
+// Run a command in a running container and wait for it to exit
+func (c *Client) Exec(ctx context.Context, name string, opts provider.ExecOptions) error {
+	if name == "" {
+		return fmt.Errorf("name is required to exec in a container")
+	}
+	if len(opts.Cmd) == 0 {
+		return fmt.Errorf("command is required to exec in a container")
+	}
+
+	cmd := baseClientCmd(ctx, "exec")
+	if opts.Stdin != nil {
+		cmd.Args = append(cmd.Args, "--interactive")
+	}
+	if opts.TTY {
+		cmd.Args = append(cmd.Args, "--tty")
+	}
+	for _, k := range slices.Sorted(maps.Keys(opts.Env)) {
+		cmd.Args = append(cmd.Args, "--env", k)
+	}
+	setEnv(cmd, opts.Env)
+	cmd.Args = append(cmd.Args, name)
+	cmd.Args = append(cmd.Args, opts.Cmd...)
+	cmd.Stdin = opts.Stdin
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+
+	c.logger.WithField("command", cmd.String()).Debug("Running exec command")
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return &provider.ExitError{Code: exitErr.ExitCode()}
+		}
+		return fmt.Errorf("failed to exec in container: %w", err)
+	}
+	return nil
 }
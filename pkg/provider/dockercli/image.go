@@ -1 +1,44 @@
 package dockercli
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+const imageCmd = "image"
+
+func baseImageCmd(ctx context.Context) *exec.Cmd {
+	return baseClientCmd(ctx, imageCmd)
+}
+
+// Check if an image exists locally
+func (c *Client) ImageExists(ctx context.Context, ref string) (bool, error) {
+	id, err := c.ImageID(ctx, ref)
+	return id != "", err
+}
+
+// Get the ID of a local image, empty when it does not exist
+func (c *Client) ImageID(ctx context.Context, ref string) (string, error) {
+	if ref == "" {
+		return "", fmt.Errorf("image reference is required to inspect an image")
+	}
+
+	cmd := baseImageCmd(ctx)
+	cmd.Args = append(cmd.Args, "inspect", "--format", "{{ .ID }}", ref)
+
+	c.logger.WithField("command", cmd.String()).Debug("Running image inspect command")
+
+	out, err := cmd.Output()
+	if err != nil {
+		// Check if image doesn't exist
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			c.logger.WithField("image", ref).Debug("image not found")
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to inspect image: %w", err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,56 @@
+package dockercli
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/stenh0use/hind/pkg/config"
+)
+
+// mountArg converts a volume into the value of a --mount argument. Volumes
+// docker creates for the mount are given the node labels and their own.
+func mountArg(v config.Volume, nodeLabels config.Labels) (string, error) {
+	if v.Destination == "" {
+		return "", fmt.Errorf("volume destination is required")
+	}
+
+	opts := []string{"type=" + v.MountType()}
+
+	switch v.MountType() {
+	case config.VolumeMount:
+		if v.Name != "" {
+			opts = append(opts, "source="+v.Name)
+		}
+	case config.BindMount:
+		if v.Source == "" {
+			return "", fmt.Errorf("bind mount '%s' requires a source", v.Destination)
+		}
+		opts = append(opts, "source="+v.Source)
+	case config.TmpfsMount:
+		if v.Source != "" || v.Name != "" {
+			return "", fmt.Errorf("tmpfs mount '%s' cannot have a source", v.Destination)
+		}
+	default:
+		return "", fmt.Errorf("unknown mount type '%s' for '%s'", v.Type, v.Destination)
+	}
+
+	opts = append(opts, "target="+v.Destination)
+	if v.ReadOnly {
+		opts = append(opts, "readonly")
+	}
+
+	if v.MountType() == config.VolumeMount {
+		labels := maps.Clone(nodeLabels)
+		if labels == nil {
+			labels = config.Labels{}
+		}
+		maps.Copy(labels, v.Labels)
+		for _, k := range slices.Sorted(maps.Keys(labels)) {
+			opts = append(opts, fmt.Sprintf("volume-label=%s=%s", k, labels[k]))
+		}
+	}
+
+	return strings.Join(opts, ","), nil
+}
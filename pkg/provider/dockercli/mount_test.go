@@ -0,0 +1,77 @@
+package dockercli
+
+import (
+	"testing"
+
+	"github.com/stenh0use/hind/pkg/config"
+)
+
+func TestMountArg(t *testing.T) {
+	nodeLabels := config.Labels{"hind.cluster": "test"}
+
+	tests := []struct {
+		name    string
+		volume  config.Volume
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "named volume",
+			volume: config.Volume{Name: "hind.test.consul-data", Destination: "/consul/data"},
+			want:   "type=volume,source=hind.test.consul-data,target=/consul/data,volume-label=hind.cluster=test",
+		},
+		{
+			name:   "anonymous volume with labels",
+			volume: config.Volume{Destination: "/var", Labels: config.Labels{"backup": "false"}},
+			want:   "type=volume,target=/var,volume-label=backup=false,volume-label=hind.cluster=test",
+		},
+		{
+			name:   "read only bind mount",
+			volume: config.Volume{Source: "/srv/jobs", Destination: "/jobs", ReadOnly: true},
+			want:   "type=bind,source=/srv/jobs,target=/jobs,readonly",
+		},
+		{
+			name:   "tmpfs mount",
+			volume: config.Volume{Type: config.TmpfsMount, Destination: "/scratch"},
+			want:   "type=tmpfs,target=/scratch",
+		},
+		{
+			name:    "bind mount without source",
+			volume:  config.Volume{Type: config.BindMount, Destination: "/jobs"},
+			wantErr: true,
+		},
+		{
+			name:    "tmpfs mount with source",
+			volume:  config.Volume{Type: config.TmpfsMount, Source: "/srv", Destination: "/scratch"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			volume:  config.Volume{Type: "nfs", Destination: "/data"},
+			wantErr: true,
+		},
+		{
+			name:    "missing destination",
+			volume:  config.Volume{Name: "data"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mountArg(tt.volume, nodeLabels)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("mountArg() = %q, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("mountArg() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("mountArg() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
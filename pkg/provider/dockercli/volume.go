@@ -0,0 +1,81 @@
+package dockercli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+const volumeCmd = "volume"
+
+func baseVolumeCmd(ctx context.Context) *exec.Cmd {
+	return baseClientCmd(ctx, volumeCmd)
+}
+
+// volumeEntry represents the JSON output from docker volume ls
+type volumeEntry struct {
+	Driver     string `json:"Driver"`
+	Labels     string `json:"Labels"`
+	Mountpoint string `json:"Mountpoint"`
+	Name       string `json:"Name"`
+}
+
+// List volumes
+func (c *Client) ListVolumes(ctx context.Context, filters []string) ([]provider.VolumeInfo, error) {
+	var response []provider.VolumeInfo
+
+	cmd := baseVolumeCmd(ctx)
+	cmd.Args = append(cmd.Args, "ls", "--format", "{{ . | json }}")
+
+	for _, f := range filters {
+		cmd.Args = append(cmd.Args, "--filter", f)
+	}
+
+	c.logger.WithField("command", cmd.String()).Debug("Running volume list command")
+
+	out, err := cmd.Output()
+	if err != nil {
+		return response, fmt.Errorf("failed to list volumes: %w", err)
+	}
+
+	for _, line := range bytes.Split(out, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var entry volumeEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return response, fmt.Errorf("failed to unmarshal list response: %w", err)
+		}
+
+		response = append(response, provider.VolumeInfo{
+			Name:       entry.Name,
+			Driver:     entry.Driver,
+			Mountpoint: entry.Mountpoint,
+			Labels:     c.parseLabels(entry.Labels),
+		})
+	}
+
+	return response, nil
+}
+
+// Delete a volume
+func (c *Client) DeleteVolume(ctx context.Context, name string) error {
+	if name == "" {
+		return fmt.Errorf("name is required to delete a volume")
+	}
+
+	cmd := baseVolumeCmd(ctx)
+	cmd.Args = append(cmd.Args, "rm", name)
+
+	c.logger.WithField("command", cmd.String()).Debug("Running volume delete command")
+
+	if _, err := cmd.Output(); err != nil {
+		return fmt.Errorf("failed to delete volume: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+)
+
+// ExecOptions configures a command run in a container
+type ExecOptions struct {
+	// Cmd is the command to run and its arguments
+	Cmd []string
+	// Env is added to the environment of the container
+	Env map[string]string
+	// Stdin is attached to the command when it is not nil
+	Stdin io.Reader
+	// TTY runs the command in a terminal, the output is then a single
+	// stream written to Stdout
+	TTY bool
+	// ConsoleSize is the initial height and width of the terminal
+	ConsoleSize *[2]uint
+	// Stdout and Stderr receive the output of the command, it is discarded
+	// when they are nil
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// ExitError is returned by Exec when the command exits with a non-zero code
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("command exited with code %d", e.Code)
+}
+
+// EnvList returns the environment as sorted KEY=value pairs
+func (o ExecOptions) EnvList() []string {
+	var env []string
+	for _, k := range slices.Sorted(maps.Keys(o.Env)) {
+		env = append(env, k+"="+o.Env[k])
+	}
+	return env
+}
@@ -0,0 +1,305 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+type container struct {
+	id      string
+	created time.Time
+	status  provider.Status
+	spec    config.Node
+	imageID string
+	address netip.Addr
+	// addresses on the networks the container was connected to afterwards
+	connected map[string]netip.Addr
+	// anonymous volumes removed with the container
+	anonymous []string
+}
+
+// Create and start a container
+func (p *Provider) CreateContainer(ctx context.Context, cfg config.Node) (string, error) {
+	if err := p.begin(ctx, MethodCreateContainer, cfg.Name); err != nil {
+		return "", err
+	}
+	defer p.mu.Unlock()
+
+	if cfg.Name == "" {
+		return "", fmt.Errorf("name is required to create a container")
+	}
+	if cfg.Image.Name == "" {
+		return "", fmt.Errorf("image name is required")
+	}
+	if _, ok := p.containers[cfg.Name]; ok {
+		return "", fmt.Errorf("container name '%s' is already in use", cfg.Name)
+	}
+	var attached *network
+	if cfg.Network != "" {
+		n, ok := p.networks[cfg.Network]
+		if !ok {
+			return "", notFound("network", cfg.Network)
+		}
+		attached = n
+	}
+	if !p.imageExists(cfg.Image.Ref()) {
+		return "", notFound("image", cfg.Image.Ref())
+	}
+
+	c := &container{
+		id:      p.newID(),
+		created: time.Now(),
+		status:  Created,
+		spec:    p.containerSpec(cfg),
+		imageID: p.imageID(cfg.Image.Ref()),
+	}
+	if attached != nil {
+		c.address = attached.nextAddress()
+	}
+
+	// Named volumes are created on first use with the container labels, the
+	// anonymous /var volume every node gets belongs to the container
+	for _, v := range cfg.Volumes {
+		if v.MountType() == config.VolumeMount && v.Name != "" {
+			p.ensureVolume(v, cfg.Labels)
+		}
+	}
+	anon := p.newID()
+	p.volumes[anon] = &provider.VolumeInfo{Name: anon, Driver: "local", Labels: maps.Clone(cfg.Labels)}
+	c.anonymous = append(c.anonymous, anon)
+
+	p.containers[cfg.Name] = c
+	c.status = provider.Running
+	return c.id, nil
+}
+
+// containerSpec returns the node config the container reports, with the
+// defaults docker fills in: tcp ports and random host ports.
+func (p *Provider) containerSpec(cfg config.Node) config.Node {
+	spec := cfg
+	spec.Environment = maps.Clone(cfg.Environment)
+	spec.Labels = maps.Clone(cfg.Labels)
+	spec.Devices = slices.Clone(cfg.Devices)
+
+	spec.Ports = slices.Clone(cfg.Ports)
+	for i := range spec.Ports {
+		if spec.Ports[i].Protocol == "" {
+			spec.Ports[i].Protocol = "tcp"
+		}
+		if spec.Ports[i].HostPort == 0 {
+			spec.Ports[i].HostPort = p.nextPort
+			p.nextPort++
+		}
+	}
+
+	spec.Volumes = slices.Clone(cfg.Volumes)
+	for i := range spec.Volumes {
+		spec.Volumes[i].Type = spec.Volumes[i].MountType()
+		spec.Volumes[i].Labels = nil
+	}
+	return spec
+}
+
+// Start a container if it is stopped
+func (p *Provider) StartContainer(ctx context.Context, name string) error {
+	if err := p.begin(ctx, MethodStartContainer, name); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	c, ok := p.containers[name]
+	if !ok {
+		return notFound("container", name)
+	}
+	c.status = provider.Running
+	return nil
+}
+
+// Stop a container if it is running
+func (p *Provider) StopContainer(ctx context.Context, name string) error {
+	if err := p.begin(ctx, MethodStopContainer, name); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	c, ok := p.containers[name]
+	if !ok {
+		return notFound("container", name)
+	}
+	if c.status == provider.Running {
+		c.status = provider.Stopped
+	}
+	return nil
+}
+
+// Delete a container and its anonymous volumes. Like docker, a running
+// container has to be stopped first.
+func (p *Provider) DeleteContainer(ctx context.Context, name string) error {
+	if err := p.begin(ctx, MethodDeleteContainer, name); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	c, ok := p.containers[name]
+	if !ok {
+		return notFound("container", name)
+	}
+	if c.status == provider.Running {
+		return fmt.Errorf("cannot remove running container '%s', stop the container before removing", name)
+	}
+
+	for _, v := range c.anonymous {
+		delete(p.volumes, v)
+	}
+	delete(p.containers, name)
+	return nil
+}
+
+// Inspect container state, nil if it doesn't exist
+func (p *Provider) InspectContainer(ctx context.Context, name string) (*provider.ContainerInfo, error) {
+	if err := p.begin(ctx, MethodInspectContainer, name); err != nil {
+		return nil, err
+	}
+	defer p.mu.Unlock()
+
+	if name == "" {
+		return nil, fmt.Errorf("name is required to inspect a container")
+	}
+	c, ok := p.containers[name]
+	if !ok {
+		return nil, nil
+	}
+	info := c.info(name)
+	return &info, nil
+}
+
+// List containers in any state matching every filter. Supported filters are
+// label=key, label=key=value, name=substring and status=state.
+func (p *Provider) ListContainers(ctx context.Context, filters []string) ([]provider.ContainerInfo, error) {
+	if err := p.begin(ctx, MethodListContainers, ""); err != nil {
+		return nil, err
+	}
+	defer p.mu.Unlock()
+
+	var response []provider.ContainerInfo
+	for _, name := range slices.Sorted(maps.Keys(p.containers)) {
+		c := p.containers[name]
+
+		match := true
+		for _, f := range filters {
+			key, value, _ := strings.Cut(f, "=")
+			switch key {
+			case "label":
+				match = matchLabel(c.spec.Labels, value)
+			case "name":
+				match = strings.Contains(name, value)
+			case "status":
+				match = string(c.status) == value
+			default:
+				return nil, fmt.Errorf("unsupported filter '%s'", f)
+			}
+			if !match {
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+		response = append(response, c.info(name))
+	}
+	return response, nil
+}
+
+func (c *container) info(name string) provider.ContainerInfo {
+	spec := c.spec
+	spec.Environment = maps.Clone(c.spec.Environment)
+	spec.Labels = maps.Clone(c.spec.Labels)
+	spec.Ports = slices.Clone(c.spec.Ports)
+	spec.Volumes = slices.Clone(c.spec.Volumes)
+	spec.Devices = slices.Clone(c.spec.Devices)
+
+	var ports []string
+	for _, p := range spec.Ports {
+		host := p.ListenAddress
+		if host == "" {
+			host = "0.0.0.0"
+		}
+		ports = append(ports, fmt.Sprintf("%s:%d->%d/%s", host, p.HostPort, p.ContainerPort, p.Protocol))
+	}
+	slices.Sort(ports)
+
+	// Like docker, only running containers report their address
+	var address string
+	addresses := map[string]string{}
+	if spec.Network != "" {
+		addresses[spec.Network] = ""
+	}
+	for n := range c.connected {
+		addresses[n] = ""
+	}
+	if c.status == provider.Running {
+		if c.address.IsValid() {
+			address = c.address.String()
+			addresses[spec.Network] = address
+		}
+		for n, addr := range c.connected {
+			addresses[n] = addr.String()
+		}
+	}
+
+	return provider.ContainerInfo{
+		ID:        c.id,
+		Name:      name,
+		Created:   c.created.Format(time.RFC3339Nano),
+		HostName:  name,
+		Status:    string(c.status),
+		Image:     spec.Image.Ref(),
+		ImageID:   c.imageID,
+		Ports:     ports,
+		Labels:    maps.Clone(spec.Labels),
+		Network:   spec.Network,
+		Address:   address,
+		Addresses: addresses,
+		Spec:      &spec,
+	}
+}
+
+// matchLabel reports whether labels match a label filter, key or key=value
+func matchLabel(labels map[string]string, filter string) bool {
+	key, value, hasValue := strings.Cut(filter, "=")
+	got, ok := labels[key]
+	return ok && (!hasValue || got == value)
+}
+
+// Run a command in a running container with the ExecFunc of the provider
+func (p *Provider) Exec(ctx context.Context, name string, opts provider.ExecOptions) error {
+	if err := p.begin(ctx, MethodExec, name); err != nil {
+		return err
+	}
+
+	c, ok := p.containers[name]
+	running := ok && c.status == provider.Running
+	fn := p.exec
+	p.mu.Unlock()
+
+	switch {
+	case !ok:
+		return notFound("container", name)
+	case !running:
+		return fmt.Errorf("container %s is not running", name)
+	case len(opts.Cmd) == 0:
+		return fmt.Errorf("command is required to exec in a container")
+	case fn == nil:
+		return nil
+	}
+	// The command may block, eg. to follow logs, so it runs unlocked
+	return fn(ctx, name, opts)
+}
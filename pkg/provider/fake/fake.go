@@ -0,0 +1,220 @@
+// Package fake implements an in-memory provider for tests and simulations.
+// Containers, networks and volumes live in memory and move through the
+// created, running, stopped and error states like they would on docker.
+// Failures and latency can be injected and every call is recorded.
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+// Created is the state of a container that exists but was never started
+const Created provider.Status = "created"
+
+// Methods of provider.Client, used to record calls and target failures
+const (
+	MethodCreateContainer   = "CreateContainer"
+	MethodStartContainer    = "StartContainer"
+	MethodStopContainer     = "StopContainer"
+	MethodDeleteContainer   = "DeleteContainer"
+	MethodInspectContainer  = "InspectContainer"
+	MethodListContainers    = "ListContainers"
+	MethodExec              = "Exec"
+	MethodCreateNetwork     = "CreateNetwork"
+	MethodDeleteNetwork     = "DeleteNetwork"
+	MethodListNetworks      = "ListNetworks"
+	MethodInspectNetwork    = "InspectNetwork"
+	MethodConnectNetwork    = "ConnectNetwork"
+	MethodDisconnectNetwork = "DisconnectNetwork"
+	MethodListVolumes       = "ListVolumes"
+	MethodDeleteVolume      = "DeleteVolume"
+	MethodImageExists       = "ImageExists"
+	MethodImageID           = "ImageID"
+)
+
+// ErrInjected is returned by calls failed with a Failure without an error
+var ErrInjected = errors.New("injected failure")
+
+// Call is a call the provider received
+type Call struct {
+	Method string
+	// Name of the container, network, volume or image the call targets,
+	// empty for list calls
+	Name string
+}
+
+// Failure makes calls to a method fail
+type Failure struct {
+	// Method to fail, eg. MethodCreateContainer
+	Method string
+	// Name of the resource the call targets, empty matches any
+	Name string
+	// Err returned by the call, ErrInjected if nil
+	Err error
+	// Times is the number of calls to fail, 0 fails every call
+	Times int
+}
+
+// ExecFunc runs the command of an Exec call in the named container
+type ExecFunc func(ctx context.Context, name string, opts provider.ExecOptions) error
+
+var _ provider.Client = (*Provider)(nil)
+
+// Provider is an in-memory provider.Client. It is safe for concurrent use.
+type Provider struct {
+	mu         sync.Mutex
+	containers map[string]*container
+	networks   map[string]*network
+	volumes    map[string]*provider.VolumeInfo
+	images     map[string]bool
+	imageIDs   map[string]string
+	failures   []*Failure
+	calls      []Call
+	latency    time.Duration
+	exec       ExecFunc
+	nextID     int
+	nextImage  int
+	nextPort   int32
+	nextSubnet int
+}
+
+// Option configures a Provider
+type Option func(*Provider)
+
+// WithImages sets the images that exist locally. By default every image exists.
+func WithImages(refs ...string) Option {
+	return func(p *Provider) {
+		p.images = map[string]bool{}
+		for _, ref := range refs {
+			p.images[ref] = true
+		}
+	}
+}
+
+// WithLatency delays every call by d, or until its context is done
+func WithLatency(d time.Duration) Option {
+	return func(p *Provider) {
+		p.latency = d
+	}
+}
+
+// WithExec sets the function running the commands of Exec calls. By
+// default commands succeed without output.
+func WithExec(fn ExecFunc) Option {
+	return func(p *Provider) {
+		p.exec = fn
+	}
+}
+
+// New creates an empty in-memory provider
+func New(opts ...Option) *Provider {
+	p := &Provider{
+		containers: map[string]*container{},
+		networks:   map[string]*network{},
+		volumes:    map[string]*provider.VolumeInfo{},
+		imageIDs:   map[string]string{},
+		nextPort:   32768,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Fail injects a failure for the calls matching f
+func (p *Provider) Fail(f Failure) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failures = append(p.failures, &f)
+}
+
+// Calls returns the calls received so far, in order
+func (p *Provider) Calls() []Call {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.calls)
+}
+
+// CallsTo returns the calls received so far to a method, in order
+func (p *Provider) CallsTo(method string) []Call {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var calls []Call
+	for _, c := range p.calls {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// SetStatus moves a container to a state, eg. to simulate a crash with
+// provider.Error or a container stopped outside of hind
+func (p *Provider) SetStatus(name string, status provider.Status) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	c, ok := p.containers[name]
+	if !ok {
+		return notFound("container", name)
+	}
+	c.status = status
+	return nil
+}
+
+// begin records a call, waits for the injected latency and returns the
+// injected failure for it, if any. The lock is held when it returns nil.
+func (p *Provider) begin(ctx context.Context, method, name string) error {
+	p.mu.Lock()
+	p.calls = append(p.calls, Call{Method: method, Name: name})
+	latency := p.latency
+	p.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(latency):
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	for i, f := range p.failures {
+		if f.Method != method || (f.Name != "" && f.Name != name) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				p.failures = slices.Delete(p.failures, i, i+1)
+			}
+		}
+		p.mu.Unlock()
+		if f.Err != nil {
+			return f.Err
+		}
+		return ErrInjected
+	}
+	return nil
+}
+
+// newID returns a docker style 64 character ID, unique to the provider
+func (p *Provider) newID() string {
+	p.nextID++
+	return fmt.Sprintf("%064x", p.nextID)
+}
+
+func notFound(kind, name string) error {
+	return fmt.Errorf("no such %s: %s", kind, name)
+}
@@ -0,0 +1,378 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+func testNode(name string) config.Node {
+	return config.Node{
+		Name:    name,
+		Network: "hind.dev",
+		Image:   config.Image{Name: "hind.consul", Tag: "0.2.0"},
+		Labels:  config.Labels{"hind.cluster": "dev"},
+		Ports:   []config.PortMapping{{ContainerPort: 8500}},
+		Volumes: []config.Volume{{Name: "hind.dev.data", Destination: "/data"}},
+	}
+}
+
+func newTestProvider(t *testing.T, opts ...Option) *Provider {
+	t.Helper()
+	p := New(opts...)
+	if _, err := p.CreateNetwork(context.Background(), config.Network{Name: "hind.dev"}); err != nil {
+		t.Fatalf("CreateNetwork() error = %v", err)
+	}
+	return p
+}
+
+func status(t *testing.T, p *Provider, name string) string {
+	t.Helper()
+	info, err := p.InspectContainer(context.Background(), name)
+	if err != nil {
+		t.Fatalf("InspectContainer() error = %v", err)
+	}
+	if info == nil {
+		return ""
+	}
+	return info.Status
+}
+
+func TestProvider_ContainerLifecycle(t *testing.T) {
+	ctx := context.Background()
+	p := newTestProvider(t)
+
+	if _, err := p.CreateContainer(ctx, testNode("dev-consul-01")); err != nil {
+		t.Fatalf("CreateContainer() error = %v", err)
+	}
+	if got := status(t, p, "dev-consul-01"); got != provider.Running.String() {
+		t.Errorf("status after create = %s, want running", got)
+	}
+	if _, err := p.CreateContainer(ctx, testNode("dev-consul-01")); err == nil {
+		t.Error("CreateContainer() with a used name error = nil, want error")
+	}
+
+	if err := p.DeleteContainer(ctx, "dev-consul-01"); err == nil {
+		t.Error("DeleteContainer() of a running container error = nil, want error")
+	}
+	if err := p.DeleteNetwork(ctx, "hind.dev"); err == nil {
+		t.Error("DeleteNetwork() with attached containers error = nil, want error")
+	}
+
+	if err := p.StopContainer(ctx, "dev-consul-01"); err != nil {
+		t.Fatalf("StopContainer() error = %v", err)
+	}
+	if got := status(t, p, "dev-consul-01"); got != provider.Stopped.String() {
+		t.Errorf("status after stop = %s, want stopped", got)
+	}
+	if err := p.StartContainer(ctx, "dev-consul-01"); err != nil {
+		t.Fatalf("StartContainer() error = %v", err)
+	}
+	if err := p.SetStatus("dev-consul-01", provider.Error); err != nil {
+		t.Fatalf("SetStatus() error = %v", err)
+	}
+	if got := status(t, p, "dev-consul-01"); got != provider.Error.String() {
+		t.Errorf("status after SetStatus = %s, want error", got)
+	}
+
+	if err := p.DeleteContainer(ctx, "dev-consul-01"); err != nil {
+		t.Fatalf("DeleteContainer() error = %v", err)
+	}
+	if got := status(t, p, "dev-consul-01"); got != "" {
+		t.Errorf("status after delete = %s, want not found", got)
+	}
+
+	// The named volume outlives the container, the anonymous one doesn't
+	volumes, err := p.ListVolumes(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListVolumes() error = %v", err)
+	}
+	if len(volumes) != 1 || volumes[0].Name != "hind.dev.data" || volumes[0].Labels["hind.cluster"] != "dev" {
+		t.Errorf("ListVolumes() = %+v, want the labeled named volume", volumes)
+	}
+}
+
+func TestProvider_Spec(t *testing.T) {
+	p := newTestProvider(t)
+	if _, err := p.CreateContainer(context.Background(), testNode("dev-consul-01")); err != nil {
+		t.Fatalf("CreateContainer() error = %v", err)
+	}
+
+	info, _ := p.InspectContainer(context.Background(), "dev-consul-01")
+	spec := info.Spec
+	if spec == nil {
+		t.Fatal("InspectContainer() spec = nil")
+	}
+	if got := spec.Ports[0]; got.Protocol != "tcp" || got.HostPort == 0 {
+		t.Errorf("spec port = %+v, want tcp with a host port assigned", got)
+	}
+	if got := spec.Volumes[0].Type; got != config.VolumeMount {
+		t.Errorf("spec volume type = %q, want %q", got, config.VolumeMount)
+	}
+
+	// The spec is a copy
+	spec.Labels["hind.cluster"] = "other"
+	again, _ := p.InspectContainer(context.Background(), "dev-consul-01")
+	if again.Spec.Labels["hind.cluster"] != "dev" {
+		t.Error("modifying the returned spec changed the provider state")
+	}
+}
+
+func TestProvider_Addresses(t *testing.T) {
+	ctx := context.Background()
+	p := newTestProvider(t)
+
+	n, _ := p.InspectNetwork(ctx, "hind.dev")
+	if n.Subnet != "172.18.0.0/16" || n.Gateway != "172.18.0.1" {
+		t.Errorf("InspectNetwork() = %s %s, want 172.18.0.0/16 172.18.0.1", n.Subnet, n.Gateway)
+	}
+	if _, err := p.CreateNetwork(ctx, config.Network{Name: "hind.other"}); err != nil {
+		t.Fatalf("CreateNetwork() error = %v", err)
+	}
+	if other, _ := p.InspectNetwork(ctx, "hind.other"); other.Subnet != "172.19.0.0/16" {
+		t.Errorf("second network subnet = %s, want 172.19.0.0/16", other.Subnet)
+	}
+
+	for _, name := range []string{"dev-consul-01", "dev-nomad-01"} {
+		if _, err := p.CreateContainer(ctx, testNode(name)); err != nil {
+			t.Fatalf("CreateContainer() error = %v", err)
+		}
+	}
+	info, _ := p.InspectContainer(ctx, "dev-nomad-01")
+	if info.Network != "hind.dev" || info.Address != "172.18.0.3" {
+		t.Errorf("InspectContainer() network = %q, %q, want hind.dev, 172.18.0.3", info.Network, info.Address)
+	}
+
+	// Stopped containers have no address
+	if err := p.StopContainer(ctx, "dev-nomad-01"); err != nil {
+		t.Fatalf("StopContainer() error = %v", err)
+	}
+	if info, _ := p.InspectContainer(ctx, "dev-nomad-01"); info.Address != "" {
+		t.Errorf("stopped container address = %q, want none", info.Address)
+	}
+}
+
+func TestProvider_ConnectNetwork(t *testing.T) {
+	ctx := context.Background()
+	p := newTestProvider(t)
+	if _, err := p.CreateNetwork(ctx, config.Network{Name: "hind.other"}); err != nil {
+		t.Fatalf("CreateNetwork() error = %v", err)
+	}
+	if _, err := p.CreateContainer(ctx, testNode("dev-consul-01")); err != nil {
+		t.Fatalf("CreateContainer() error = %v", err)
+	}
+
+	if err := p.ConnectNetwork(ctx, "hind.other", "dev-consul-01"); err != nil {
+		t.Fatalf("ConnectNetwork() error = %v", err)
+	}
+	if err := p.ConnectNetwork(ctx, "hind.other", "dev-consul-01"); err == nil {
+		t.Errorf("ConnectNetwork() again error = nil, want already exists")
+	}
+	info, _ := p.InspectContainer(ctx, "dev-consul-01")
+	want := map[string]string{"hind.dev": "172.18.0.2", "hind.other": "172.19.0.2"}
+	if !maps.Equal(info.Addresses, want) || info.Address != "172.18.0.2" {
+		t.Errorf("InspectContainer() addresses = %v, %q, want %v", info.Addresses, info.Address, want)
+	}
+
+	// Like docker, networks with connected containers can't be deleted
+	if err := p.DeleteNetwork(ctx, "hind.other"); err == nil {
+		t.Errorf("DeleteNetwork() error = nil, want active endpoints")
+	}
+	if err := p.DisconnectNetwork(ctx, "hind.other", "dev-consul-01"); err != nil {
+		t.Fatalf("DisconnectNetwork() error = %v", err)
+	}
+	if err := p.DeleteNetwork(ctx, "hind.other"); err != nil {
+		t.Errorf("DeleteNetwork() error = %v", err)
+	}
+}
+
+func TestProvider_Exec(t *testing.T) {
+	ctx := context.Background()
+	var got []string
+	p := New(WithExec(func(_ context.Context, name string, opts provider.ExecOptions) error {
+		got = append([]string{name}, opts.Cmd...)
+		_, err := io.WriteString(opts.Stdout, "ok")
+		return err
+	}))
+	if _, err := p.CreateNetwork(ctx, config.Network{Name: "hind.dev"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.CreateContainer(ctx, testNode("dev-consul-01")); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	if err := p.Exec(ctx, "dev-consul-01", provider.ExecOptions{Cmd: []string{"hostname"}, Stdout: &out}); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if strings.Join(got, " ") != "dev-consul-01 hostname" || out.String() != "ok" {
+		t.Errorf("Exec() ran %v with output %q, want hostname with output ok", got, out.String())
+	}
+
+	if err := p.StopContainer(ctx, "dev-consul-01"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Exec(ctx, "dev-consul-01", provider.ExecOptions{Cmd: []string{"hostname"}}); err == nil {
+		t.Error("Exec() in a stopped container error = nil, want error")
+	}
+	if err := p.Exec(ctx, "missing", provider.ExecOptions{Cmd: []string{"hostname"}}); err == nil {
+		t.Error("Exec() in a missing container error = nil, want error")
+	}
+	if calls := p.CallsTo(MethodExec); len(calls) != 3 {
+		t.Errorf("CallsTo(Exec) = %v, want 3 calls", calls)
+	}
+}
+
+func TestProvider_ListContainers(t *testing.T) {
+	ctx := context.Background()
+	p := newTestProvider(t)
+	for _, name := range []string{"dev-consul-01", "dev-nomad-01", "other-consul-01"} {
+		node := testNode(name)
+		node.Volumes = nil
+		if name == "other-consul-01" {
+			node.Labels = config.Labels{"hind.cluster": "other"}
+		}
+		if _, err := p.CreateContainer(ctx, node); err != nil {
+			t.Fatalf("CreateContainer() error = %v", err)
+		}
+	}
+	if err := p.SetStatus("dev-nomad-01", provider.Stopped); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		filters []string
+		want    []string
+	}{
+		{"all states", nil, []string{"dev-consul-01", "dev-nomad-01", "other-consul-01"}},
+		{"label value", []string{"label=hind.cluster=dev"}, []string{"dev-consul-01", "dev-nomad-01"}},
+		{"label key", []string{"label=hind.cluster"}, []string{"dev-consul-01", "dev-nomad-01", "other-consul-01"}},
+		{"status", []string{"status=stopped"}, []string{"dev-nomad-01"}},
+		{"name and label", []string{"name=consul", "label=hind.cluster=other"}, []string{"other-consul-01"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.ListContainers(ctx, tt.filters)
+			if err != nil {
+				t.Fatalf("ListContainers() error = %v", err)
+			}
+			var names []string
+			for _, c := range got {
+				names = append(names, c.Name)
+			}
+			if len(names) != len(tt.want) {
+				t.Fatalf("ListContainers() = %v, want %v", names, tt.want)
+			}
+			for i := range names {
+				if names[i] != tt.want[i] {
+					t.Errorf("ListContainers() = %v, want %v", names, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestProvider_Fail(t *testing.T) {
+	ctx := context.Background()
+	p := newTestProvider(t)
+	boom := errors.New("boom")
+
+	p.Fail(Failure{Method: MethodCreateContainer, Name: "dev-consul-01", Err: boom, Times: 1})
+	p.Fail(Failure{Method: MethodImageExists})
+
+	if _, err := p.CreateContainer(ctx, testNode("dev-consul-01")); !errors.Is(err, boom) {
+		t.Errorf("CreateContainer() error = %v, want %v", err, boom)
+	}
+	if got := status(t, p, "dev-consul-01"); got != "" {
+		t.Errorf("failed create left a container in state %s", got)
+	}
+	if _, err := p.CreateContainer(ctx, testNode("dev-consul-01")); err != nil {
+		t.Errorf("CreateContainer() after one-shot failure error = %v", err)
+	}
+
+	for range 2 {
+		if _, err := p.ImageExists(ctx, "hind.consul:0.2.0"); !errors.Is(err, ErrInjected) {
+			t.Errorf("ImageExists() error = %v, want %v", err, ErrInjected)
+		}
+	}
+}
+
+func TestProvider_Calls(t *testing.T) {
+	ctx := context.Background()
+	p := New()
+
+	p.InspectNetwork(ctx, "hind.dev")
+	p.ListContainers(ctx, nil)
+	p.InspectNetwork(ctx, "hind.other")
+
+	want := []Call{
+		{Method: MethodInspectNetwork, Name: "hind.dev"},
+		{Method: MethodListContainers},
+		{Method: MethodInspectNetwork, Name: "hind.other"},
+	}
+	calls := p.Calls()
+	if len(calls) != len(want) {
+		t.Fatalf("Calls() = %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("Calls()[%d] = %v, want %v", i, calls[i], want[i])
+		}
+	}
+	if got := p.CallsTo(MethodInspectNetwork); len(got) != 2 {
+		t.Errorf("CallsTo(%s) = %v, want 2 calls", MethodInspectNetwork, got)
+	}
+}
+
+func TestProvider_Latency(t *testing.T) {
+	p := New(WithLatency(50 * time.Millisecond))
+
+	start := time.Now()
+	if _, err := p.ImageExists(context.Background(), "hind.consul:0.2.0"); err != nil {
+		t.Fatalf("ImageExists() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("ImageExists() took %v, want at least 50ms", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if _, err := p.ImageExists(ctx, "hind.consul:0.2.0"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ImageExists() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestProvider_WithImages(t *testing.T) {
+	p := newTestProvider(t, WithImages("hind.consul:0.2.0"))
+
+	tests := []struct {
+		ref  string
+		want bool
+	}{
+		{"hind.consul:0.2.0", true},
+		{"hind.nomad:0.2.0", false},
+	}
+	for _, tt := range tests {
+		got, err := p.ImageExists(context.Background(), tt.ref)
+		if err != nil {
+			t.Fatalf("ImageExists() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("ImageExists(%s) = %v, want %v", tt.ref, got, tt.want)
+		}
+	}
+
+	node := testNode("dev-nomad-01")
+	node.Image = config.Image{Name: "hind.nomad", Tag: "0.2.0"}
+	if _, err := p.CreateContainer(context.Background(), node); err == nil {
+		t.Error("CreateContainer() with a missing image error = nil, want error")
+	}
+}
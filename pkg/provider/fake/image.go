@@ -0,0 +1,63 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+)
+
+// Check if an image exists locally
+func (p *Provider) ImageExists(ctx context.Context, ref string) (bool, error) {
+	if err := p.begin(ctx, MethodImageExists, ref); err != nil {
+		return false, err
+	}
+	defer p.mu.Unlock()
+
+	if ref == "" {
+		return false, fmt.Errorf("image reference is required to inspect an image")
+	}
+	return p.imageExists(ref), nil
+}
+
+// Get the ID of a local image, empty when it does not exist
+func (p *Provider) ImageID(ctx context.Context, ref string) (string, error) {
+	if err := p.begin(ctx, MethodImageID, ref); err != nil {
+		return "", err
+	}
+	defer p.mu.Unlock()
+
+	if ref == "" {
+		return "", fmt.Errorf("image reference is required to inspect an image")
+	}
+	return p.imageID(ref), nil
+}
+
+// BuildImage builds an image, or rebuilds it with a new ID when it exists.
+// Containers created from the previous build keep its ID.
+func (p *Provider) BuildImage(ref string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.images != nil {
+		p.images[ref] = true
+	}
+	delete(p.imageIDs, ref)
+	p.imageID(ref)
+}
+
+func (p *Provider) imageExists(ref string) bool {
+	return p.images == nil || p.images[ref]
+}
+
+// imageID returns the ID of a local image, images get an ID on first use
+func (p *Provider) imageID(ref string) string {
+	if !p.imageExists(ref) {
+		return ""
+	}
+	id, ok := p.imageIDs[ref]
+	if !ok {
+		p.nextImage++
+		id = fmt.Sprintf("sha256:%064x", p.nextImage)
+		p.imageIDs[ref] = id
+	}
+	return id
+}
@@ -0,0 +1,200 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+type network struct {
+	id      string
+	created time.Time
+	cfg     config.Network
+	// last address given to a container
+	lastAddr netip.Addr
+}
+
+// Create a new network
+func (p *Provider) CreateNetwork(ctx context.Context, cfg config.Network) (string, error) {
+	if err := p.begin(ctx, MethodCreateNetwork, cfg.Name); err != nil {
+		return "", err
+	}
+	defer p.mu.Unlock()
+
+	if cfg.Name == "" {
+		return "", fmt.Errorf("name is required to create a network")
+	}
+	if _, ok := p.networks[cfg.Name]; ok {
+		return "", fmt.Errorf("network with name '%s' already exists", cfg.Name)
+	}
+
+	cfg.Labels = maps.Clone(cfg.Labels)
+	if cfg.Driver == "" {
+		cfg.Driver = "bridge"
+	}
+
+	// Like docker, networks without a subnet get the next free /16 and the
+	// gateway is the first address of the subnet
+	if cfg.Subnet == "" {
+		cfg.Subnet = fmt.Sprintf("172.%d.0.0/16", 18+p.nextSubnet)
+		p.nextSubnet++
+	}
+	subnet, err := netip.ParsePrefix(cfg.Subnet)
+	if err != nil {
+		return "", fmt.Errorf("invalid subnet '%s': %w", cfg.Subnet, err)
+	}
+	if cfg.Gateway == "" {
+		cfg.Gateway = subnet.Masked().Addr().Next().String()
+	}
+	gateway, err := netip.ParseAddr(cfg.Gateway)
+	if err != nil {
+		return "", fmt.Errorf("invalid gateway '%s': %w", cfg.Gateway, err)
+	}
+
+	n := &network{id: p.newID(), created: time.Now(), cfg: cfg, lastAddr: gateway}
+	p.networks[cfg.Name] = n
+	return n.id, nil
+}
+
+// nextAddress returns the next address on the network for a container
+func (n *network) nextAddress() netip.Addr {
+	n.lastAddr = n.lastAddr.Next()
+	return n.lastAddr
+}
+
+// Delete a network. Like docker, it fails while containers are attached.
+func (p *Provider) DeleteNetwork(ctx context.Context, name string) error {
+	if err := p.begin(ctx, MethodDeleteNetwork, name); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if _, ok := p.networks[name]; !ok {
+		return notFound("network", name)
+	}
+	for cname, c := range p.containers {
+		if _, ok := c.connected[name]; ok || c.spec.Network == name {
+			return fmt.Errorf("network '%s' has active endpoints: %s", name, cname)
+		}
+	}
+	delete(p.networks, name)
+	return nil
+}
+
+// Attach a container to another network. Like docker, it fails if the
+// container is already attached.
+func (p *Provider) ConnectNetwork(ctx context.Context, name, container string) error {
+	if err := p.begin(ctx, MethodConnectNetwork, name); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	n, ok := p.networks[name]
+	if !ok {
+		return notFound("network", name)
+	}
+	c, ok := p.containers[container]
+	if !ok {
+		return notFound("container", container)
+	}
+	if _, ok := c.connected[name]; ok || c.spec.Network == name {
+		return fmt.Errorf("endpoint with name %s already exists in network %s", container, name)
+	}
+	if c.connected == nil {
+		c.connected = map[string]netip.Addr{}
+	}
+	c.connected[name] = n.nextAddress()
+	return nil
+}
+
+// Detach a container from a network it was connected to
+func (p *Provider) DisconnectNetwork(ctx context.Context, name, container string) error {
+	if err := p.begin(ctx, MethodDisconnectNetwork, name); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if _, ok := p.networks[name]; !ok {
+		return notFound("network", name)
+	}
+	c, ok := p.containers[container]
+	if !ok {
+		return notFound("container", container)
+	}
+	if _, ok := c.connected[name]; !ok {
+		return fmt.Errorf("container %s is not connected to network %s", container, name)
+	}
+	delete(c.connected, name)
+	return nil
+}
+
+// List networks matching every filter. Supported filters are label=key,
+// label=key=value and name=substring.
+func (p *Provider) ListNetworks(ctx context.Context, filters []string) ([]provider.NetworkInfo, error) {
+	if err := p.begin(ctx, MethodListNetworks, ""); err != nil {
+		return nil, err
+	}
+	defer p.mu.Unlock()
+
+	var response []provider.NetworkInfo
+	for _, name := range slices.Sorted(maps.Keys(p.networks)) {
+		n := p.networks[name]
+
+		match := true
+		for _, f := range filters {
+			key, value, _ := strings.Cut(f, "=")
+			switch key {
+			case "label":
+				match = matchLabel(n.cfg.Labels, value)
+			case "name":
+				match = strings.Contains(name, value)
+			default:
+				return nil, fmt.Errorf("unsupported filter '%s'", f)
+			}
+			if !match {
+				break
+			}
+		}
+		if match {
+			response = append(response, n.info())
+		}
+	}
+	return response, nil
+}
+
+// Inspect network state, nil if it doesn't exist
+func (p *Provider) InspectNetwork(ctx context.Context, name string) (*provider.NetworkInfo, error) {
+	if err := p.begin(ctx, MethodInspectNetwork, name); err != nil {
+		return nil, err
+	}
+	defer p.mu.Unlock()
+
+	if name == "" {
+		return nil, fmt.Errorf("name is required to inspect a network")
+	}
+	n, ok := p.networks[name]
+	if !ok {
+		return nil, nil
+	}
+	info := n.info()
+	return &info, nil
+}
+
+func (n *network) info() provider.NetworkInfo {
+	return provider.NetworkInfo{
+		ID:      n.id,
+		Name:    n.cfg.Name,
+		Created: n.created,
+		Driver:  n.cfg.Driver,
+		Subnet:  n.cfg.Subnet,
+		Gateway: n.cfg.Gateway,
+		Labels:  maps.Clone(n.cfg.Labels),
+	}
+}
@@ -0,0 +1,84 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+// List volumes matching every filter. Supported filters are label=key,
+// label=key=value and name=substring.
+func (p *Provider) ListVolumes(ctx context.Context, filters []string) ([]provider.VolumeInfo, error) {
+	if err := p.begin(ctx, MethodListVolumes, ""); err != nil {
+		return nil, err
+	}
+	defer p.mu.Unlock()
+
+	var response []provider.VolumeInfo
+	for _, name := range slices.Sorted(maps.Keys(p.volumes)) {
+		v := p.volumes[name]
+
+		match := true
+		for _, f := range filters {
+			key, value, _ := strings.Cut(f, "=")
+			switch key {
+			case "label":
+				match = matchLabel(v.Labels, value)
+			case "name":
+				match = strings.Contains(name, value)
+			default:
+				return nil, fmt.Errorf("unsupported filter '%s'", f)
+			}
+			if !match {
+				break
+			}
+		}
+		if match {
+			info := *v
+			info.Labels = maps.Clone(v.Labels)
+			response = append(response, info)
+		}
+	}
+	return response, nil
+}
+
+// Delete a volume. Like docker, it fails while a container uses it.
+func (p *Provider) DeleteVolume(ctx context.Context, name string) error {
+	if err := p.begin(ctx, MethodDeleteVolume, name); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if _, ok := p.volumes[name]; !ok {
+		return notFound("volume", name)
+	}
+	for cname, c := range p.containers {
+		inUse := slices.Contains(c.anonymous, name) || slices.ContainsFunc(c.spec.Volumes, func(v config.Volume) bool {
+			return v.Type == config.VolumeMount && v.Name == name
+		})
+		if inUse {
+			return fmt.Errorf("volume '%s' is in use by container '%s'", name, cname)
+		}
+	}
+	delete(p.volumes, name)
+	return nil
+}
+
+// ensureVolume creates a named volume with the node labels and its own, if
+// it doesn't exist. The caller holds the lock.
+func (p *Provider) ensureVolume(v config.Volume, nodeLabels config.Labels) {
+	if _, ok := p.volumes[v.Name]; ok {
+		return
+	}
+	labels := maps.Clone(nodeLabels)
+	if labels == nil {
+		labels = config.Labels{}
+	}
+	maps.Copy(labels, v.Labels)
+	p.volumes[v.Name] = &provider.VolumeInfo{Name: v.Name, Driver: "local", Labels: labels}
+}
@@ -0,0 +1,208 @@
+// Package inspect converts docker inspect responses into hind types. It is
+// shared by the providers that talk to the docker engine.
+package inspect
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/network"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+// VarMount is the destination of the anonymous volume providers add
+// when the node doesn't configure one.
+const VarMount = "/var"
+
+// ModulesMount is the host kernel modules providers bind read only
+const ModulesMount = "/lib/modules"
+
+// NodeSpec converts an inspect response into the node configuration the
+// container was created with.
+func NodeSpec(res *container.InspectResponse) *config.Node {
+	spec := &config.Node{
+		Name: strings.TrimPrefix(res.Name, "/"),
+	}
+
+	if res.Config != nil {
+		spec.Image = ParseImageRef(res.Config.Image)
+		spec.Labels = res.Config.Labels
+
+		if len(res.Config.Env) > 0 {
+			spec.Environment = make(map[string]string, len(res.Config.Env))
+			for _, e := range res.Config.Env {
+				k, v, _ := strings.Cut(e, "=")
+				spec.Environment[k] = v
+			}
+		}
+	}
+
+	if res.HostConfig != nil {
+		spec.Network = string(res.HostConfig.NetworkMode)
+
+		for port, bindings := range res.HostConfig.PortBindings {
+			for _, b := range bindings {
+				p := config.PortMapping{
+					ContainerPort: int32(port.Num()),
+					Protocol:      string(port.Proto()),
+				}
+				if b.HostIP.IsValid() {
+					p.ListenAddress = b.HostIP.String()
+				}
+				if hostPort, err := strconv.ParseInt(b.HostPort, 10, 32); err == nil {
+					p.HostPort = int32(hostPort)
+				}
+				spec.Ports = append(spec.Ports, p)
+			}
+		}
+
+		for _, d := range res.HostConfig.Devices {
+			spec.Devices = append(spec.Devices,
+				strings.Join([]string{d.PathOnHost, d.PathInContainer, d.CgroupPermissions}, ":"))
+		}
+	}
+
+	for _, m := range res.Mounts {
+		v := config.Volume{
+			Type:        string(m.Type),
+			Destination: m.Destination,
+			ReadOnly:    !m.RW,
+		}
+		switch v.Type {
+		case config.VolumeMount:
+			if !provider.IsAnonymousVolume(m.Name) {
+				v.Name = m.Name
+			}
+		case config.BindMount:
+			v.Source = m.Source
+		}
+
+		// Skip the mounts providers add that aren't in the node config
+		if m.Destination == ModulesMount ||
+			(m.Destination == VarMount && v.Type == config.VolumeMount && v.Name == "") {
+			continue
+		}
+		spec.Volumes = append(spec.Volumes, v)
+	}
+
+	return spec
+}
+
+// PublishedPorts formats the ports bound on the host, eg. 0.0.0.0:8500->8500/tcp
+func PublishedPorts(ports network.PortMap) []string {
+	var published []string
+	for port, bindings := range ports {
+		for _, b := range bindings {
+			host := b.HostPort
+			if b.HostIP.IsValid() {
+				host = net.JoinHostPort(b.HostIP.String(), b.HostPort)
+			}
+			published = append(published, fmt.Sprintf("%s->%s", host, port))
+		}
+	}
+	slices.Sort(published)
+	return published
+}
+
+// ParseImageRef splits an image reference into its name, tag and digest
+//
+// eg. docker.io/stenh0use/hind.consul:0.4.0
+func ParseImageRef(ref string) config.Image {
+	var img config.Image
+
+	if name, digest, ok := strings.Cut(ref, "@"); ok {
+		img.Name = name
+		img.Digest = digest
+		return img
+	}
+
+	// The tag separator is the last colon after the last slash, a colon
+	// before it is a registry port
+	img.Name = ref
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		img.Name = ref[:i]
+		img.Tag = ref[i+1:]
+	}
+	return img
+}
+
+// SummaryPorts formats the ports bound on the host of a container list
+// entry, like PublishedPorts
+func SummaryPorts(ports []container.PortSummary) []string {
+	var published []string
+	for _, p := range ports {
+		if p.PublicPort == 0 {
+			continue
+		}
+		host := strconv.Itoa(int(p.PublicPort))
+		if p.IP.IsValid() {
+			host = net.JoinHostPort(p.IP.String(), host)
+		}
+		published = append(published, fmt.Sprintf("%s->%d/%s", host, p.PrivatePort, p.Type))
+	}
+	slices.Sort(published)
+	return published
+}
+
+// EndpointAddress returns the network a container is attached to and its IP
+// address on that network. The container's network mode is preferred when
+// it is attached to more than one.
+func EndpointAddress(networkMode string, networks map[string]*network.EndpointSettings) (string, string) {
+	name := networkMode
+	if _, ok := networks[name]; !ok {
+		names := slices.Sorted(maps.Keys(networks))
+		if len(names) == 0 {
+			return networkMode, ""
+		}
+		name = names[0]
+	}
+
+	ep := networks[name]
+	if ep == nil || !ep.IPAddress.IsValid() {
+		return name, ""
+	}
+	return name, ep.IPAddress.String()
+}
+
+// NetworkAddresses returns the IP address of a container on each network it
+// is attached to, empty for networks it has no address on yet
+func NetworkAddresses(networks map[string]*network.EndpointSettings) map[string]string {
+	addresses := make(map[string]string, len(networks))
+	for name, ep := range networks {
+		addresses[name] = ""
+		if ep != nil && ep.IPAddress.IsValid() {
+			addresses[name] = ep.IPAddress.String()
+		}
+	}
+	return addresses
+}
+
+// SubnetGateway returns the subnet and gateway of a network, preferring its
+// IPv4 subnet.
+func SubnetGateway(ipam network.IPAM) (string, string) {
+	var subnet, gateway string
+	for _, cfg := range ipam.Config {
+		if !cfg.Subnet.IsValid() {
+			continue
+		}
+		if subnet != "" && !cfg.Subnet.Addr().Is4() {
+			continue
+		}
+		subnet = cfg.Subnet.String()
+		gateway = ""
+		if cfg.Gateway.IsValid() {
+			gateway = cfg.Gateway.String()
+		}
+		if cfg.Subnet.Addr().Is4() {
+			break
+		}
+	}
+	return subnet, gateway
+}
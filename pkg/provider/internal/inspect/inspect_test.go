@@ -0,0 +1,270 @@
+package inspect
+
+import (
+	"encoding/json"
+	"maps"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/network"
+
+	"github.com/stenh0use/hind/pkg/config"
+)
+
+func TestParseImageRef(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want config.Image
+	}{
+		{
+			ref:  "docker.io/stenh0use/hind.consul:0.4.0",
+			want: config.Image{Name: "docker.io/stenh0use/hind.consul", Tag: "0.4.0"},
+		},
+		{
+			ref:  "docker.io/stenh0use/hind.consul@sha256:abc",
+			want: config.Image{Name: "docker.io/stenh0use/hind.consul", Digest: "sha256:abc"},
+		},
+		{
+			ref:  "localhost:5000/hind.consul",
+			want: config.Image{Name: "localhost:5000/hind.consul"},
+		},
+		{
+			ref:  "localhost:5000/hind.consul:0.4.0",
+			want: config.Image{Name: "localhost:5000/hind.consul", Tag: "0.4.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got := ParseImageRef(tt.ref)
+			if got != tt.want {
+				t.Errorf("ParseImageRef(%q) = %+v, want %+v", tt.ref, got, tt.want)
+			}
+			if got.Ref() != tt.ref {
+				t.Errorf("ParseImageRef(%q).Ref() = %q, want %q", tt.ref, got.Ref(), tt.ref)
+			}
+		})
+	}
+}
+
+func TestNodeSpec(t *testing.T) {
+	inspect := `{
+		"Name": "/hind.test.consul.01",
+		"Config": {
+			"Image": "docker.io/stenh0use/hind.consul:0.4.0",
+			"Env": ["CONSUL_AGENT_MODE=server", "PATH=/usr/bin"],
+			"Labels": {"hind.cluster": "test"}
+		},
+		"HostConfig": {
+			"NetworkMode": "hind.test",
+			"PortBindings": {"8500/tcp": [{"HostIp": "", "HostPort": "8500"}]},
+			"Devices": [{"PathOnHost": "/dev/fuse", "PathInContainer": "/dev/fuse", "CgroupPermissions": "rwm"}]
+		},
+		"Mounts": [
+			{"Type": "volume", "Name": "0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c4b5a69788796a5b4c3d2e1f0", "Destination": "/var", "RW": true},
+			{"Type": "bind", "Source": "/lib/modules", "Destination": "/lib/modules", "RW": false},
+			{"Type": "bind", "Source": "/srv/data", "Destination": "/data", "RW": true},
+			{"Type": "volume", "Name": "hind.test.consul-data", "Destination": "/consul/data", "RW": true},
+			{"Type": "tmpfs", "Destination": "/scratch", "RW": false}
+		]
+	}`
+
+	res := &container.InspectResponse{}
+	if err := json.Unmarshal([]byte(inspect), res); err != nil {
+		t.Fatalf("failed to unmarshal inspect response: %v", err)
+	}
+
+	got := NodeSpec(res)
+
+	if got.Name != "hind.test.consul.01" {
+		t.Errorf("Name = %q, want %q", got.Name, "hind.test.consul.01")
+	}
+	if got.Image.Ref() != "docker.io/stenh0use/hind.consul:0.4.0" {
+		t.Errorf("Image = %q, want %q", got.Image.Ref(), "docker.io/stenh0use/hind.consul:0.4.0")
+	}
+	if got.Environment["CONSUL_AGENT_MODE"] != "server" {
+		t.Errorf("Environment = %v, want CONSUL_AGENT_MODE=server", got.Environment)
+	}
+	if got.Labels["hind.cluster"] != "test" {
+		t.Errorf("Labels = %v, want hind.cluster=test", got.Labels)
+	}
+
+	wantPort := config.PortMapping{HostPort: 8500, ContainerPort: 8500, Protocol: "tcp"}
+	if len(got.Ports) != 1 || got.Ports[0] != wantPort {
+		t.Errorf("Ports = %+v, want [%+v]", got.Ports, wantPort)
+	}
+	if len(got.Devices) != 1 || got.Devices[0] != "/dev/fuse:/dev/fuse:rwm" {
+		t.Errorf("Devices = %v, want [/dev/fuse:/dev/fuse:rwm]", got.Devices)
+	}
+
+	// The default /var and /lib/modules mounts are not part of the node config
+	wantVolumes := []config.Volume{
+		{Type: config.BindMount, Source: "/srv/data", Destination: "/data"},
+		{Type: config.VolumeMount, Name: "hind.test.consul-data", Destination: "/consul/data"},
+		{Type: config.TmpfsMount, Destination: "/scratch", ReadOnly: true},
+	}
+	if len(got.Volumes) != len(wantVolumes) {
+		t.Fatalf("Volumes = %+v, want %+v", got.Volumes, wantVolumes)
+	}
+	for i, want := range wantVolumes {
+		v := got.Volumes[i]
+		if v.Type != want.Type || v.Name != want.Name || v.Source != want.Source ||
+			v.Destination != want.Destination || v.ReadOnly != want.ReadOnly {
+			t.Errorf("Volumes[%d] = %+v, want %+v", i, v, want)
+		}
+	}
+}
+
+func TestPublishedPorts(t *testing.T) {
+	var settings container.NetworkSettings
+	data := `{"Ports": {
+		"8500/tcp": [{"HostIp": "0.0.0.0", "HostPort": "8501"}, {"HostIp": "::", "HostPort": "8501"}],
+		"8600/udp": null
+	}}`
+	if err := json.Unmarshal([]byte(data), &settings); err != nil {
+		t.Fatalf("failed to unmarshal network settings: %v", err)
+	}
+
+	got := PublishedPorts(settings.Ports)
+	want := []string{"0.0.0.0:8501->8500/tcp", "[::]:8501->8500/tcp"}
+	if len(got) != len(want) {
+		t.Fatalf("PublishedPorts() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("PublishedPorts()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSummaryPorts(t *testing.T) {
+	var summary container.Summary
+	data := `{"Ports": [
+		{"IP": "0.0.0.0", "PrivatePort": 8500, "PublicPort": 8501, "Type": "tcp"},
+		{"PrivatePort": 8600, "Type": "udp"},
+		{"IP": "::", "PrivatePort": 8500, "PublicPort": 8501, "Type": "tcp"}
+	]}`
+	if err := json.Unmarshal([]byte(data), &summary); err != nil {
+		t.Fatalf("failed to unmarshal container summary: %v", err)
+	}
+
+	got := SummaryPorts(summary.Ports)
+	want := []string{"0.0.0.0:8501->8500/tcp", "[::]:8501->8500/tcp"}
+	if len(got) != len(want) {
+		t.Fatalf("SummaryPorts() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("SummaryPorts()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestEndpointAddress(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        string
+		data        string
+		wantNetwork string
+		wantAddress string
+	}{
+		{
+			name:        "network mode",
+			mode:        "hind.dev",
+			data:        `{"bridge": {"IPAddress": "172.17.0.2"}, "hind.dev": {"IPAddress": "172.18.0.3"}}`,
+			wantNetwork: "hind.dev",
+			wantAddress: "172.18.0.3",
+		},
+		{
+			name:        "only network",
+			mode:        "default",
+			data:        `{"hind.dev": {"IPAddress": "172.18.0.3"}}`,
+			wantNetwork: "hind.dev",
+			wantAddress: "172.18.0.3",
+		},
+		{
+			name:        "stopped container",
+			mode:        "hind.dev",
+			data:        `{"hind.dev": {"IPAddress": ""}}`,
+			wantNetwork: "hind.dev",
+		},
+		{
+			name:        "not attached",
+			mode:        "hind.dev",
+			data:        `{}`,
+			wantNetwork: "hind.dev",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var networks map[string]*network.EndpointSettings
+			if err := json.Unmarshal([]byte(tt.data), &networks); err != nil {
+				t.Fatalf("failed to unmarshal networks: %v", err)
+			}
+
+			gotNetwork, gotAddress := EndpointAddress(tt.mode, networks)
+			if gotNetwork != tt.wantNetwork || gotAddress != tt.wantAddress {
+				t.Errorf("EndpointAddress() = %q, %q, want %q, %q", gotNetwork, gotAddress, tt.wantNetwork, tt.wantAddress)
+			}
+		})
+	}
+}
+
+func TestNetworkAddresses(t *testing.T) {
+	var networks map[string]*network.EndpointSettings
+	data := `{"hind.dev": {"IPAddress": "172.18.0.3"}, "hind.prod": {"IPAddress": "172.19.0.5"}, "hind.new": {"IPAddress": ""}}`
+	if err := json.Unmarshal([]byte(data), &networks); err != nil {
+		t.Fatalf("failed to unmarshal networks: %v", err)
+	}
+
+	want := map[string]string{"hind.dev": "172.18.0.3", "hind.prod": "172.19.0.5", "hind.new": ""}
+	if got := NetworkAddresses(networks); !maps.Equal(got, want) {
+		t.Errorf("NetworkAddresses() = %v, want %v", got, want)
+	}
+}
+
+func TestSubnetGateway(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		wantSubnet  string
+		wantGateway string
+	}{
+		{
+			name:        "ipv4",
+			data:        `{"Config": [{"Subnet": "172.18.0.0/16", "Gateway": "172.18.0.1"}]}`,
+			wantSubnet:  "172.18.0.0/16",
+			wantGateway: "172.18.0.1",
+		},
+		{
+			name:       "ipv4 preferred",
+			data:       `{"Config": [{"Subnet": "fd00::/64", "Gateway": "fd00::1"}, {"Subnet": "172.18.0.0/16"}]}`,
+			wantSubnet: "172.18.0.0/16",
+		},
+		{
+			name:        "ipv6 only",
+			data:        `{"Config": [{"Subnet": "fd00::/64", "Gateway": "fd00::1"}]}`,
+			wantSubnet:  "fd00::/64",
+			wantGateway: "fd00::1",
+		},
+		{
+			name: "no config",
+			data: `{}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ipam network.IPAM
+			if err := json.Unmarshal([]byte(tt.data), &ipam); err != nil {
+				t.Fatalf("failed to unmarshal ipam: %v", err)
+			}
+
+			gotSubnet, gotGateway := SubnetGateway(ipam)
+			if gotSubnet != tt.wantSubnet || gotGateway != tt.wantGateway {
+				t.Errorf("SubnetGateway() = %q, %q, want %q, %q", gotSubnet, gotGateway, tt.wantSubnet, tt.wantGateway)
+			}
+		})
+	}
+}
@@ -7,6 +7,8 @@ type NetworkInfo struct {
 	Name    string
 	Created time.Time
 	Driver  string
+	Subnet  string
+	Gateway string
 	Status  string
 	Image   string
 	Ports   []string
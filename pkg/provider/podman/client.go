@@ -0,0 +1,69 @@
+// Package podman implements a provider that runs cluster containers with
+// the podman CLI.
+package podman
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+
+	"github.com/apex/log"
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+const clientBin = "podman"
+
+// Client provides an interface to podman for cluster operations
+type Client struct {
+	logger *log.Logger
+}
+
+// New creates a new podman client
+func New(logger *log.Logger) provider.Client {
+	return &Client{
+		logger: logger,
+	}
+}
+
+func baseClientCmd(ctx context.Context, arg ...string) *exec.Cmd {
+	return exec.CommandContext(
+		ctx,
+		clientBin,
+		arg...,
+	)
+}
+
+// setEnv sets env in the environment of the client process. Commands pass
+// the variables on with --env KEY flags that carry only the names, so the
+// values, eg. ACL tokens, stay out of the process list and the debug logs.
+func setEnv(cmd *exec.Cmd, env map[string]string) {
+	if len(env) == 0 {
+		return
+	}
+	cmd.Env = os.Environ()
+	for _, k := range slices.Sorted(maps.Keys(env)) {
+		cmd.Env = append(cmd.Env, k+"="+env[k])
+	}
+}
+
+// exists runs a podman exists command, eg. podman container exists. Podman
+// reports most failures with exit code 125, including missing objects on
+// inspect, so the exists commands are used to tell "not found" apart.
+func (c *Client) exists(ctx context.Context, arg ...string) (bool, error) {
+	cmd := baseClientCmd(ctx, arg...)
+
+	c.logger.WithField("command", cmd.String()).Debug("Running exists command")
+
+	err := cmd.Run()
+	if err == nil {
+		return true, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return false, err
+}
@@ -0,0 +1,398 @@
+package podman
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/network"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+	"github.com/stenh0use/hind/pkg/provider/internal/inspect"
+)
+
+// localImagePrefix is the registry podman gives images built locally
+const localImagePrefix = "localhost/"
+
+// Create and start a container
+func (c *Client) CreateContainer(ctx context.Context, cfg config.Node) (string, error) {
+	if cfg.Name == "" {
+		return "", fmt.Errorf("name is required to create a container")
+	}
+
+	args, err := runArgs(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	// Named volumes are created up front so they carry the node labels
+	for _, v := range cfg.Volumes {
+		if v.MountType() == config.VolumeMount && v.Name != "" {
+			if err := c.ensureVolume(ctx, v, cfg.Labels); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	cmd := baseClientCmd(ctx, args...)
+	setEnv(cmd, cfg.Environment)
+
+	c.logger.WithField("command", cmd.String()).Debug("Running container create command")
+
+	id, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to create container: %w", err)
+	}
+	return strings.TrimSpace(string(id)), nil
+}
+
+// runArgs returns the podman run arguments for a node. They follow the
+// dockercli provider, except that podman sets up systemd itself: with
+// --systemd=always it mounts /run and /tmp as tmpfs and makes the cgroup
+// tree writable, and --privileged already disables seccomp and SELinux.
+func runArgs(cfg config.Node) ([]string, error) {
+	if cfg.Image.Name == "" {
+		return nil, fmt.Errorf("image name is required")
+	}
+
+	args := []string{
+		"run",
+		"--detach",
+		"--systemd=always",
+		// podman defaults to the host cgroup namespace on cgroups v1
+		"--cgroupns=private",
+		"--privileged",
+		"--restart", "on-failure:1",
+		"--tty",
+		"--volume", inspect.ModulesMount + ":" + inspect.ModulesMount + ":ro",
+		"--name", cfg.Name,
+		"--hostname", cfg.Name,
+	}
+
+	if cfg.Network != "" {
+		args = append(args, "--network", cfg.Network)
+	}
+
+	// The node gets an anonymous /var volume unless one is configured
+	volumes := cfg.Volumes
+	if !slices.ContainsFunc(volumes, func(v config.Volume) bool { return v.Destination == inspect.VarMount }) {
+		volumes = append(volumes, config.Volume{Type: config.VolumeMount, Destination: inspect.VarMount})
+	}
+	for _, v := range volumes {
+		mount, err := mountArg(v)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--mount", mount)
+	}
+
+	for _, p := range cfg.Ports {
+		if p.ContainerPort == 0 {
+			return nil, fmt.Errorf("container port is required")
+		}
+		var publish []string
+		if p.ListenAddress != "" {
+			publish = append(publish, p.ListenAddress)
+		}
+		if p.HostPort != 0 {
+			publish = append(publish, strconv.Itoa(int(p.HostPort)))
+		} else if p.ListenAddress != "" {
+			publish = append(publish, "")
+		}
+		publish = append(publish, strconv.Itoa(int(p.ContainerPort)))
+
+		publishStr := strings.Join(publish, ":")
+		if p.Protocol != "" {
+			publishStr += "/" + p.Protocol
+		}
+		args = append(args, "--publish", publishStr)
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(cfg.Environment)) {
+		args = append(args, "--env", k)
+	}
+	for _, k := range slices.Sorted(maps.Keys(cfg.Labels)) {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", k, cfg.Labels[k]))
+	}
+	for _, d := range cfg.Devices {
+		args = append(args, "--device", d)
+	}
+
+	return append(args, cfg.Image.Ref()), nil
+}
+
+// mountArg converts a volume into the value of a --mount argument
+func mountArg(v config.Volume) (string, error) {
+	if v.Destination == "" {
+		return "", fmt.Errorf("volume destination is required")
+	}
+
+	opts := []string{"type=" + v.MountType()}
+
+	switch v.MountType() {
+	case config.VolumeMount:
+		if v.Name != "" {
+			opts = append(opts, "source="+v.Name)
+		}
+	case config.BindMount:
+		if v.Source == "" {
+			return "", fmt.Errorf("bind mount '%s' requires a source", v.Destination)
+		}
+		opts = append(opts, "source="+v.Source)
+	case config.TmpfsMount:
+		if v.Source != "" || v.Name != "" {
+			return "", fmt.Errorf("tmpfs mount '%s' cannot have a source", v.Destination)
+		}
+	default:
+		return "", fmt.Errorf("unknown mount type '%s' for '%s'", v.Type, v.Destination)
+	}
+
+	opts = append(opts, "target="+v.Destination)
+	if v.ReadOnly {
+		opts = append(opts, "readonly")
+	}
+	return strings.Join(opts, ","), nil
+}
+
+// Start a container if it is stopped
+func (c *Client) StartContainer(ctx context.Context, name string) error {
+	if name == "" {
+		return fmt.Errorf("name is required to start a container")
+	}
+
+	cmd := baseClientCmd(ctx, "start", name)
+
+	c.logger.WithField("container", name).Debug("starting container")
+
+	if _, err := cmd.Output(); err != nil {
+		return fmt.Errorf("failed to start container: %w", err)
+	}
+	return nil
+}
+
+// Stop a container if it is running
+func (c *Client) StopContainer(ctx context.Context, name string) error {
+	if name == "" {
+		return fmt.Errorf("name or id is required to stop a container")
+	}
+
+	cmd := baseClientCmd(ctx, "stop", name)
+
+	c.logger.WithField("container", name).Debug("stopping container")
+
+	if _, err := cmd.Output(); err != nil {
+		return fmt.Errorf("failed to stop container: %w", err)
+	}
+	return nil
+}
+
+// Delete a container and its anonymous volumes
+func (c *Client) DeleteContainer(ctx context.Context, name string) error {
+	if name == "" {
+		return fmt.Errorf("name or id is required to delete a container")
+	}
+
+	cmd := baseClientCmd(ctx, "rm", "--volumes", name)
+
+	if _, err := cmd.Output(); err != nil {
+		return fmt.Errorf("failed to delete container: %w", err)
+	}
+	return nil
+}
+
+// inspectEntry represents the parts of podman container inspect hind uses.
+// It is close to docker's output but not compatible with it, eg. the
+// entrypoint is a string in older podman releases.
+type inspectEntry struct {
+	ID      string `json:"Id"`
+	Name    string `json:"Name"`
+	Created string `json:"Created"`
+	Image   string `json:"Image"`
+	State   struct {
+		Status string `json:"Status"`
+	} `json:"State"`
+	Config struct {
+		Hostname string            `json:"Hostname"`
+		Image    string            `json:"Image"`
+		Env      []string          `json:"Env"`
+		Labels   map[string]string `json:"Labels"`
+	} `json:"Config"`
+	HostConfig struct {
+		NetworkMode  string                    `json:"NetworkMode"`
+		PortBindings network.PortMap           `json:"PortBindings"`
+		Devices      []container.DeviceMapping `json:"Devices"`
+	} `json:"HostConfig"`
+	Mounts          []container.MountPoint `json:"Mounts"`
+	NetworkSettings struct {
+		Ports    network.PortMap                      `json:"Ports"`
+		Networks map[string]*network.EndpointSettings `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// nodeSpec converts podman's inspect output into the node configuration
+// the container was created with
+func (e *inspectEntry) nodeSpec() *config.Node {
+	spec := inspect.NodeSpec(&container.InspectResponse{
+		Name: e.Name,
+		Config: &container.Config{
+			Image:  strings.TrimPrefix(e.Config.Image, localImagePrefix),
+			Env:    e.Config.Env,
+			Labels: e.Config.Labels,
+		},
+		HostConfig: &container.HostConfig{
+			NetworkMode:  container.NetworkMode(e.HostConfig.NetworkMode),
+			PortBindings: e.HostConfig.PortBindings,
+			Resources:    container.Resources{Devices: e.HostConfig.Devices},
+		},
+		Mounts: e.Mounts,
+	})
+
+	// podman reports the bridge network mode for containers attached to a
+	// named network, the network is only listed in the network settings
+	if len(e.NetworkSettings.Networks) == 1 {
+		for name := range e.NetworkSettings.Networks {
+			spec.Network = name
+		}
+	}
+	return spec
+}
+
+// Inspect container state
+func (c *Client) InspectContainer(ctx context.Context, name string) (*provider.ContainerInfo, error) {
+	if name == "" {
+		return nil, fmt.Errorf("name is required to inspect a container")
+	}
+
+	ok, err := c.exists(ctx, "container", "exists", name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if !ok {
+		c.logger.WithField("name", name).Debug("container not found")
+		return nil, nil
+	}
+
+	entries, err := c.inspectContainers(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return containerInfo(&entries[0]), nil
+}
+
+// inspectContainers inspects the named containers in a single command
+func (c *Client) inspectContainers(ctx context.Context, names ...string) ([]inspectEntry, error) {
+	cmd := baseClientCmd(ctx, "container", "inspect")
+	cmd.Args = append(cmd.Args, names...)
+
+	c.logger.WithField("command", cmd.String()).Debug("Running container inspect command")
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container: %w", err)
+	}
+
+	var entries []inspectEntry
+	if err := json.Unmarshal(out, &entries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal inspect response: %w", err)
+	}
+	return entries, nil
+}
+
+func containerInfo(e *inspectEntry) *provider.ContainerInfo {
+	info := &provider.ContainerInfo{
+		ID:       e.ID,
+		Name:     e.Name,
+		Created:  e.Created,
+		HostName: e.Config.Hostname,
+		Status:   e.State.Status,
+		Image:    e.Config.Image,
+		ImageID:  e.Image,
+		Labels:   e.Config.Labels,
+		Ports:    inspect.PublishedPorts(e.NetworkSettings.Ports),
+		Spec:     e.nodeSpec(),
+	}
+	info.Network, info.Address = inspect.EndpointAddress(info.Spec.Network, e.NetworkSettings.Networks)
+	info.Addresses = inspect.NetworkAddresses(e.NetworkSettings.Networks)
+	return info
+}
+
+// List containers
+func (c *Client) ListContainers(ctx context.Context, filters []string) ([]provider.ContainerInfo, error) {
+	var response []provider.ContainerInfo
+
+	cmd := baseClientCmd(ctx, "ps", "--all", "--quiet", "--no-trunc")
+	for _, f := range filters {
+		cmd.Args = append(cmd.Args, "--filter", f)
+	}
+
+	c.logger.WithField("command", cmd.String()).Debug("Running container list command")
+
+	out, err := cmd.Output()
+	if err != nil {
+		return response, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	// The list output has no addresses, inspect the containers for them
+	ids := strings.Fields(string(out))
+	if len(ids) == 0 {
+		return response, nil
+	}
+
+	entries, err := c.inspectContainers(ctx, ids...)
+	if err != nil {
+		return response, err
+	}
+	for i := range entries {
+		response = append(response, *containerInfo(&entries[i]))
+	}
+	return response, nil
+}
+
+// Run a command in a running container and wait for it to exit
+func (c *Client) Exec(ctx context.Context, name string, opts provider.ExecOptions) error {
+	if name == "" {
+		return fmt.Errorf("name is required to exec in a container")
+	}
+	if len(opts.Cmd) == 0 {
+		return fmt.Errorf("command is required to exec in a container")
+	}
+
+	cmd := baseClientCmd(ctx, "exec")
+	if opts.Stdin != nil {
+		cmd.Args = append(cmd.Args, "--interactive")
+	}
+	if opts.TTY {
+		cmd.Args = append(cmd.Args, "--tty")
+	}
+	for _, k := range slices.Sorted(maps.Keys(opts.Env)) {
+		cmd.Args = append(cmd.Args, "--env", k)
+	}
+	setEnv(cmd, opts.Env)
+	cmd.Args = append(cmd.Args, name)
+	cmd.Args = append(cmd.Args, opts.Cmd...)
+	cmd.Stdin = opts.Stdin
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+
+	c.logger.WithField("command", cmd.String()).Debug("Running exec command")
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return &provider.ExitError{Code: exitErr.ExitCode()}
+		}
+		return fmt.Errorf("failed to exec in container: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package podman
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stenh0use/hind/pkg/config"
+)
+
+func TestRunArgs(t *testing.T) {
+	node := config.Node{
+		Name:        "dev-consul-01",
+		Network:     "hind.dev",
+		Image:       config.Image{Name: "hind.consul", Tag: "0.2.0"},
+		Environment: map[string]string{"B": "2", "A": "1"},
+		Labels:      config.Labels{"hind.cluster": "dev"},
+		Ports: []config.PortMapping{
+			{ContainerPort: 8500, HostPort: 8500, Protocol: "tcp"},
+			{ContainerPort: 8600, ListenAddress: "127.0.0.1", Protocol: "udp"},
+		},
+		Volumes: []config.Volume{{Name: "hind.dev.consul-data", Destination: "/consul/data"}},
+	}
+
+	args, err := runArgs(node)
+	if err != nil {
+		t.Fatalf("runArgs() error = %v", err)
+	}
+	got := strings.Join(args, " ")
+
+	for _, want := range []string{
+		"--systemd=always",
+		"--cgroupns=private",
+		"--privileged",
+		"--name dev-consul-01",
+		"--network hind.dev",
+		"--mount type=volume,source=hind.dev.consul-data,target=/consul/data",
+		"--mount type=volume,target=/var",
+		"--publish 8500:8500/tcp",
+		"--publish 127.0.0.1::8600/udp",
+		"--env A --env B",
+		"--label hind.cluster=dev",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("runArgs() = %s, missing %q", got, want)
+		}
+	}
+	if strings.Contains(got, "A=1") {
+		t.Errorf("runArgs() = %s, environment values must not be arguments", got)
+	}
+	if slices.Contains(args, "--tmpfs") {
+		t.Errorf("runArgs() = %s, podman mounts the systemd tmpfs itself", got)
+	}
+	if args[len(args)-1] != "hind.consul:0.2.0" {
+		t.Errorf("runArgs() image = %s, want hind.consul:0.2.0", args[len(args)-1])
+	}
+}
+
+func TestRunArgs_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		node config.Node
+	}{
+		{"missing image", config.Node{Name: "n"}},
+		{"missing container port", config.Node{Name: "n", Image: config.Image{Name: "i"}, Ports: []config.PortMapping{{HostPort: 80}}}},
+		{"bind without source", config.Node{Name: "n", Image: config.Image{Name: "i"}, Volumes: []config.Volume{{Type: config.BindMount, Destination: "/d"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := runArgs(tt.node); err == nil {
+				t.Error("runArgs() error = nil, want error")
+			}
+		})
+	}
+}
+
+// inspectOutput is trimmed podman container inspect output for a node
+// created by hind on a named network
+const inspectOutput = `[{
+	"Id": "4f2c",
+	"Created": "2025-01-02T10:00:00.000000000Z",
+	"Name": "dev-consul-01",
+	"State": {"Status": "running", "Running": true},
+	"Config": {
+		"Hostname": "dev-consul-01",
+		"Image": "localhost/hind.consul:0.2.0",
+		"Entrypoint": "/sbin/init",
+		"Env": ["container=podman", "CONSUL_BIND=eth0"],
+		"Labels": {"hind.cluster": "dev", "hind.version": "0.2.0"}
+	},
+	"HostConfig": {
+		"NetworkMode": "bridge",
+		"PortBindings": {"8500/tcp": [{"HostIp": "", "HostPort": "8500"}]},
+		"Devices": []
+	},
+	"Mounts": [
+		{"Type": "volume", "Name": "hind.dev.consul-data", "Source": "/var/lib/containers/storage/volumes/hind.dev.consul-data/_data", "Destination": "/consul/data", "RW": true},
+		{"Type": "volume", "Name": "0f3c1b7e9a2d4c5b6a7f8e9d0c1b2a3f4e5d6c7b8a9f0e1d2c3b4a5f6e7d8c9b", "Destination": "/var", "RW": true},
+		{"Type": "bind", "Source": "/lib/modules", "Destination": "/lib/modules", "RW": false}
+	],
+	"NetworkSettings": {
+		"Ports": {"8500/tcp": [{"HostIp": "", "HostPort": "8500"}]},
+		"Networks": {"hind.dev": {"IPAddress": "10.89.0.2"}}
+	}
+}]`
+
+func TestContainerInfo(t *testing.T) {
+	var entries []inspectEntry
+	if err := json.Unmarshal([]byte(inspectOutput), &entries); err != nil {
+		t.Fatalf("failed to unmarshal inspect output: %v", err)
+	}
+
+	info := containerInfo(&entries[0])
+
+	if info.Status != "running" || info.Name != "dev-consul-01" {
+		t.Errorf("containerInfo() = %s %s, want dev-consul-01 running", info.Name, info.Status)
+	}
+	if len(info.Ports) != 1 || info.Ports[0] != "8500->8500/tcp" {
+		t.Errorf("containerInfo() ports = %v, want [8500->8500/tcp]", info.Ports)
+	}
+	if info.Network != "hind.dev" || info.Address != "10.89.0.2" {
+		t.Errorf("containerInfo() network = %q, %q, want hind.dev, 10.89.0.2", info.Network, info.Address)
+	}
+
+	spec := info.Spec
+	if spec.Image.Ref() != "hind.consul:0.2.0" {
+		t.Errorf("Spec.Image = %s, want hind.consul:0.2.0", spec.Image.Ref())
+	}
+	if spec.Network != "hind.dev" {
+		t.Errorf("Spec.Network = %q, want hind.dev", spec.Network)
+	}
+	if spec.Environment["CONSUL_BIND"] != "eth0" {
+		t.Errorf("Spec.Environment = %v, want CONSUL_BIND=eth0", spec.Environment)
+	}
+	if len(spec.Volumes) != 1 || spec.Volumes[0].Name != "hind.dev.consul-data" {
+		t.Errorf("Spec.Volumes = %+v, want only the consul data volume", spec.Volumes)
+	}
+}
+
+func TestSetEnv(t *testing.T) {
+	t.Setenv("A", "0")
+	cmd := baseClientCmd(context.Background(), "exec", "--env", "A")
+	setEnv(cmd, map[string]string{"A": "1", "B": "2"})
+
+	// The last value of a variable wins
+	if got := cmd.Env[len(cmd.Env)-2:]; !slices.Equal(got, []string{"A=1", "B=2"}) {
+		t.Errorf("setEnv() env = %v, want A=1 B=2 last", got)
+	}
+	if strings.Contains(cmd.String(), "=") {
+		t.Errorf("setEnv() command = %s, want no values", cmd.String())
+	}
+}
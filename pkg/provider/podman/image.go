@@ -0,0 +1,41 @@
+package podman
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Check if an image exists locally
+func (c *Client) ImageExists(ctx context.Context, ref string) (bool, error) {
+	if ref == "" {
+		return false, fmt.Errorf("image reference is required to inspect an image")
+	}
+
+	ok, err := c.exists(ctx, "image", "exists", ref)
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect image: %w", err)
+	}
+	if !ok {
+		c.logger.WithField("image", ref).Debug("image not found")
+	}
+	return ok, nil
+}
+
+// Get the ID of a local image, empty when it does not exist
+func (c *Client) ImageID(ctx context.Context, ref string) (string, error) {
+	ok, err := c.ImageExists(ctx, ref)
+	if err != nil || !ok {
+		return "", err
+	}
+
+	cmd := baseClientCmd(ctx, "image", "inspect", "--format", "{{ .Id }}", ref)
+
+	c.logger.WithField("command", cmd.String()).Debug("Running image inspect command")
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect image: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
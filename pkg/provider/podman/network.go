@@ -0,0 +1,201 @@
+package podman
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+// networkEntry represents the JSON output from podman network inspect and
+// ls, which uses netavark's lower case keys rather than docker's.
+type networkEntry struct {
+	Name    string            `json:"name"`
+	ID      string            `json:"id"`
+	Driver  string            `json:"driver"`
+	Created time.Time         `json:"created"`
+	Labels  map[string]string `json:"labels"`
+	Subnets []struct {
+		Subnet  string `json:"subnet"`
+		Gateway string `json:"gateway"`
+	} `json:"subnets"`
+}
+
+func (n networkEntry) info() provider.NetworkInfo {
+	info := provider.NetworkInfo{
+		ID:      n.ID,
+		Name:    n.Name,
+		Created: n.Created,
+		Driver:  n.Driver,
+		Labels:  n.Labels,
+	}
+
+	// Prefer the IPv4 subnet of a dual stack network
+	for _, s := range n.Subnets {
+		prefix, err := netip.ParsePrefix(s.Subnet)
+		if err != nil || (info.Subnet != "" && !prefix.Addr().Is4()) {
+			continue
+		}
+		info.Subnet, info.Gateway = s.Subnet, s.Gateway
+		if prefix.Addr().Is4() {
+			break
+		}
+	}
+	return info
+}
+
+// Create a new podman network
+func (c *Client) CreateNetwork(ctx context.Context, cfg config.Network) (string, error) {
+	if cfg.Name == "" {
+		return "", fmt.Errorf("name is required to create a network")
+	}
+
+	cmd := baseClientCmd(ctx, "network", "create")
+
+	if cfg.Driver != "" {
+		cmd.Args = append(cmd.Args, "--driver", cfg.Driver)
+	}
+	if cfg.Subnet != "" {
+		cmd.Args = append(cmd.Args, "--subnet", cfg.Subnet)
+	}
+	if cfg.Gateway != "" {
+		cmd.Args = append(cmd.Args, "--gateway", cfg.Gateway)
+	}
+	for k, v := range cfg.Labels {
+		cmd.Args = append(cmd.Args, "--label", fmt.Sprintf("%s=%s", k, v))
+	}
+
+	cmd.Args = append(cmd.Args, cfg.Name)
+
+	c.logger.WithField("command", cmd.String()).Debug("Running network create command")
+
+	// podman prints the network name rather than its ID
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to create network: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Delete a network
+func (c *Client) DeleteNetwork(ctx context.Context, name string) error {
+	if name == "" {
+		return fmt.Errorf("name is required to delete a network")
+	}
+
+	cmd := baseClientCmd(ctx, "network", "rm", name)
+
+	c.logger.WithField("command", cmd.String()).Debug("Running network delete command")
+
+	if _, err := cmd.Output(); err != nil {
+		return fmt.Errorf("failed to delete network: %w", err)
+	}
+	return nil
+}
+
+// Attach a container to another network
+func (c *Client) ConnectNetwork(ctx context.Context, name, container string) error {
+	if name == "" || container == "" {
+		return fmt.Errorf("network and container are required to connect a network")
+	}
+
+	cmd := baseClientCmd(ctx, "network", "connect", name, container)
+
+	c.logger.WithField("command", cmd.String()).Debug("Running network connect command")
+
+	if _, err := cmd.Output(); err != nil {
+		return fmt.Errorf("failed to connect network: %w", err)
+	}
+	return nil
+}
+
+// Detach a container from a network
+func (c *Client) DisconnectNetwork(ctx context.Context, name, container string) error {
+	if name == "" || container == "" {
+		return fmt.Errorf("network and container are required to disconnect a network")
+	}
+
+	cmd := baseClientCmd(ctx, "network", "disconnect", name, container)
+
+	c.logger.WithField("command", cmd.String()).Debug("Running network disconnect command")
+
+	if _, err := cmd.Output(); err != nil {
+		return fmt.Errorf("failed to disconnect network: %w", err)
+	}
+	return nil
+}
+
+// Inspect network state
+func (c *Client) InspectNetwork(ctx context.Context, name string) (*provider.NetworkInfo, error) {
+	if name == "" {
+		return nil, fmt.Errorf("name is required to inspect a network")
+	}
+
+	ok, err := c.exists(ctx, "network", "exists", name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect network: %w", err)
+	}
+	if !ok {
+		c.logger.WithField("name", name).Debug("network not found")
+		return nil, nil
+	}
+
+	cmd := baseClientCmd(ctx, "network", "inspect", name)
+
+	c.logger.WithField("command", cmd.String()).Debug("Running network inspect command")
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect network: %w", err)
+	}
+
+	entries, err := parseNetworks(out)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	info := entries[0].info()
+	return &info, nil
+}
+
+// List networks
+func (c *Client) ListNetworks(ctx context.Context, filters []string) ([]provider.NetworkInfo, error) {
+	var response []provider.NetworkInfo
+
+	cmd := baseClientCmd(ctx, "network", "ls", "--format", "json")
+	for _, f := range filters {
+		cmd.Args = append(cmd.Args, "--filter", f)
+	}
+
+	c.logger.WithField("command", cmd.String()).Debug("Running network list command")
+
+	out, err := cmd.Output()
+	if err != nil {
+		return response, fmt.Errorf("failed to list networks: %w", err)
+	}
+
+	entries, err := parseNetworks(out)
+	if err != nil {
+		return response, err
+	}
+	for _, e := range entries {
+		response = append(response, e.info())
+	}
+	return response, nil
+}
+
+// parseNetworks parses the JSON array podman prints for networks
+func parseNetworks(out []byte) ([]networkEntry, error) {
+	var entries []networkEntry
+	if err := json.Unmarshal(out, &entries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal network response: %w", err)
+	}
+	return entries, nil
+}
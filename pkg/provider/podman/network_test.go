@@ -0,0 +1,45 @@
+package podman
+
+import "testing"
+
+func TestParseNetworks(t *testing.T) {
+	out := `[{
+		"name": "hind.dev",
+		"id": "a1b2c3",
+		"driver": "bridge",
+		"network_interface": "podman1",
+		"created": "2025-01-02T10:00:00.000000000Z",
+		"subnets": [{"subnet": "10.89.0.0/24", "gateway": "10.89.0.1"}],
+		"ipv6_enabled": false,
+		"internal": false,
+		"dns_enabled": true,
+		"labels": {"hind.cluster": "dev"},
+		"ipam_options": {"driver": "host-local"}
+	}]`
+
+	entries, err := parseNetworks([]byte(out))
+	if err != nil {
+		t.Fatalf("parseNetworks() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("parseNetworks() = %d entries, want 1", len(entries))
+	}
+
+	info := entries[0].info()
+	if info.Name != "hind.dev" || info.ID != "a1b2c3" || info.Driver != "bridge" {
+		t.Errorf("info() = %+v, want hind.dev a1b2c3 bridge", info)
+	}
+	if info.Subnet != "10.89.0.0/24" || info.Gateway != "10.89.0.1" {
+		t.Errorf("info() = %s %s, want subnet 10.89.0.0/24 and gateway 10.89.0.1", info.Subnet, info.Gateway)
+	}
+	if info.Labels["hind.cluster"] != "dev" {
+		t.Errorf("info() labels = %v, want hind.cluster=dev", info.Labels)
+	}
+	if info.Created.IsZero() {
+		t.Error("info() created is zero")
+	}
+
+	if _, err := parseNetworks([]byte("not json")); err == nil {
+		t.Error("parseNetworks() error = nil, want error")
+	}
+}
@@ -0,0 +1,99 @@
+package podman
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/stenh0use/hind/pkg/config"
+	"github.com/stenh0use/hind/pkg/provider"
+)
+
+// volumeEntry represents the JSON output from podman volume ls
+type volumeEntry struct {
+	Name       string            `json:"Name"`
+	Driver     string            `json:"Driver"`
+	Mountpoint string            `json:"Mountpoint"`
+	Labels     map[string]string `json:"Labels"`
+}
+
+// List volumes
+func (c *Client) ListVolumes(ctx context.Context, filters []string) ([]provider.VolumeInfo, error) {
+	var response []provider.VolumeInfo
+
+	cmd := baseClientCmd(ctx, "volume", "ls", "--format", "json")
+	for _, f := range filters {
+		cmd.Args = append(cmd.Args, "--filter", f)
+	}
+
+	c.logger.WithField("command", cmd.String()).Debug("Running volume list command")
+
+	out, err := cmd.Output()
+	if err != nil {
+		return response, fmt.Errorf("failed to list volumes: %w", err)
+	}
+
+	var entries []volumeEntry
+	if err := json.Unmarshal(out, &entries); err != nil {
+		return response, fmt.Errorf("failed to unmarshal list response: %w", err)
+	}
+
+	for _, e := range entries {
+		response = append(response, provider.VolumeInfo{
+			Name:       e.Name,
+			Driver:     e.Driver,
+			Mountpoint: e.Mountpoint,
+			Labels:     e.Labels,
+		})
+	}
+	return response, nil
+}
+
+// Delete a volume
+func (c *Client) DeleteVolume(ctx context.Context, name string) error {
+	if name == "" {
+		return fmt.Errorf("name is required to delete a volume")
+	}
+
+	cmd := baseClientCmd(ctx, "volume", "rm", name)
+
+	c.logger.WithField("command", cmd.String()).Debug("Running volume delete command")
+
+	if _, err := cmd.Output(); err != nil {
+		return fmt.Errorf("failed to delete volume: %w", err)
+	}
+	return nil
+}
+
+// ensureVolume creates a named volume with the node labels and its own,
+// if it doesn't exist. Podman's --mount can't label the volumes it creates.
+func (c *Client) ensureVolume(ctx context.Context, v config.Volume, nodeLabels config.Labels) error {
+	ok, err := c.exists(ctx, "volume", "exists", v.Name)
+	if err != nil {
+		return fmt.Errorf("failed to inspect volume '%s': %w", v.Name, err)
+	}
+	if ok {
+		return nil
+	}
+
+	labels := maps.Clone(nodeLabels)
+	if labels == nil {
+		labels = config.Labels{}
+	}
+	maps.Copy(labels, v.Labels)
+
+	cmd := baseClientCmd(ctx, "volume", "create")
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		cmd.Args = append(cmd.Args, "--label", fmt.Sprintf("%s=%s", k, labels[k]))
+	}
+	cmd.Args = append(cmd.Args, v.Name)
+
+	c.logger.WithField("command", cmd.String()).Debug("Running volume create command")
+
+	if _, err := cmd.Output(); err != nil {
+		return fmt.Errorf("failed to create volume '%s': %w", v.Name, err)
+	}
+	return nil
+}
@@ -18,8 +18,10 @@ type Client interface {
 	DeleteContainer(ctx context.Context, name string) error
 	// Inspect node state
 	InspectContainer(ctx context.Context, name string) (*ContainerInfo, error)
-	// List nodes
+	// List nodes in any state
 	ListContainers(ctx context.Context, filters []string) ([]ContainerInfo, error)
+	// Run a command in a running node and wait for it to exit
+	Exec(ctx context.Context, name string, opts ExecOptions) error
 
 	// Network methods
 	// Create a new docker network
@@ -30,4 +32,20 @@ type Client interface {
 	ListNetworks(ctx context.Context, filters []string) ([]NetworkInfo, error)
 	// Inspect network state
 	InspectNetwork(ctx context.Context, name string) (*NetworkInfo, error)
+	// Attach a container to another network
+	ConnectNetwork(ctx context.Context, network, container string) error
+	// Detach a container from a network
+	DisconnectNetwork(ctx context.Context, network, container string) error
+
+	// Volume methods
+	// List volumes
+	ListVolumes(ctx context.Context, filters []string) ([]VolumeInfo, error)
+	// Delete a volume
+	DeleteVolume(ctx context.Context, name string) error
+
+	// Image methods
+	// Check if an image exists locally
+	ImageExists(ctx context.Context, ref string) (bool, error)
+	// Get the ID of a local image, empty when it does not exist
+	ImageID(ctx context.Context, ref string) (string, error)
 }
@@ -0,0 +1,19 @@
+package provider
+
+import "regexp"
+
+// anonymousVolume matches the generated names of anonymous volumes
+var anonymousVolume = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// IsAnonymousVolume reports whether name is a generated anonymous volume
+// name, eg. of the /var volume of every node, rather than a named volume
+func IsAnonymousVolume(name string) bool {
+	return anonymousVolume.MatchString(name)
+}
+
+type VolumeInfo struct {
+	Name       string
+	Driver     string
+	Mountpoint string
+	Labels     map[string]string
+}